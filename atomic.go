@@ -0,0 +1,152 @@
+package viewset
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/TcMits/viewset/manager"
+	"github.com/gin-gonic/gin"
+)
+
+// bufferedResponseWriter holds the response back until the transaction
+// around the handler is committed, so a failed commit or a retry never
+// leaks a half written response to the client.
+type bufferedResponseWriter struct {
+	gin.ResponseWriter
+	header  http.Header
+	body    bytes.Buffer
+	status  int
+	written bool
+}
+
+func newBufferedResponseWriter(w gin.ResponseWriter) *bufferedResponseWriter {
+	return &bufferedResponseWriter{
+		ResponseWriter: w,
+		header:         w.Header().Clone(),
+	}
+}
+
+func (w *bufferedResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferedResponseWriter) WriteHeader(statusCode int) {
+	if statusCode > 0 && !w.written {
+		w.status = statusCode
+	}
+}
+
+func (w *bufferedResponseWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+func (w *bufferedResponseWriter) Write(b []byte) (int, error) {
+	w.written = true
+	return w.body.Write(b)
+}
+
+func (w *bufferedResponseWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedResponseWriter) Status() int {
+	if w.status == 0 {
+		return w.ResponseWriter.Status()
+	}
+	return w.status
+}
+
+func (w *bufferedResponseWriter) Size() int {
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *bufferedResponseWriter) Written() bool {
+	return w.written
+}
+
+func (w *bufferedResponseWriter) Flush() {}
+
+func (w *bufferedResponseWriter) flush() {
+	header := w.ResponseWriter.Header()
+	for key, values := range w.header {
+		header[key] = values
+	}
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	if w.body.Len() > 0 {
+		w.ResponseWriter.Write(w.body.Bytes())
+	} else if w.written {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func readRequestBody(c *gin.Context) ([]byte, error) {
+	if c.Request == nil || c.Request.Body == nil {
+		return nil, nil
+	}
+	defer c.Request.Body.Close()
+	return io.ReadAll(c.Request.Body)
+}
+
+func resetRequestBody(c *gin.Context, body []byte) {
+	if c.Request == nil || body == nil {
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+}
+
+func handleAtomic[EntityType, ValidateType any](
+	action string,
+	viewSet *ViewSet[EntityType, ValidateType],
+	c *gin.Context,
+	txManager manager.TransactionalManager,
+	function HandlerWithViewSetFunc[EntityType, ValidateType],
+) {
+	body, err := readRequestBody(c)
+	if err != nil {
+		viewSet.ExceptionHandler.Handle(NewViewSetError(
+			err.Error(), http.StatusBadRequest, err,
+		), c)
+		return
+	}
+
+	originalWriter := c.Writer
+	// a panic must not leave the buffer as the writer
+	defer func() { c.Writer = originalWriter }()
+	errorCount := len(c.Errors)
+	var (
+		buffer     *bufferedResponseWriter
+		handlerErr error
+	)
+	err = txManager.Atomic(c, func() error {
+		// every attempt starts from a clean response and a fresh body
+		c.Errors = c.Errors[:errorCount]
+		buffer = newBufferedResponseWriter(originalWriter)
+		c.Writer = buffer
+		resetRequestBody(c, body)
+
+		function(action, viewSet, c)
+		handlerErr = nil
+		if len(c.Errors) > errorCount {
+			handlerErr = c.Errors.Last().Err
+		} else if buffer.Status() >= http.StatusBadRequest {
+			handlerErr = errors.New(http.StatusText(buffer.Status()))
+		}
+		return handlerErr
+	})
+	c.Writer = originalWriter
+
+	if err != nil && (handlerErr == nil || !errors.Is(err, handlerErr)) {
+		// the transaction itself failed, the buffered response is a lie
+		viewSet.ExceptionHandler.Handle(classifiedError(err, c), c)
+		return
+	}
+	buffer.flush()
+}
@@ -0,0 +1,173 @@
+package viewset
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type testTransactionalManager struct {
+	testObjectManager
+	Attempts   int
+	MaxRetries int
+	CommitErr  error
+	Committed  bool
+	RolledBack bool
+}
+
+func (om *testTransactionalManager) IsAtomic(c *gin.Context) bool {
+	return c.Request == nil || c.Request.Method != http.MethodGet
+}
+
+func (om *testTransactionalManager) Atomic(c *gin.Context, fn func() error) error {
+	var err error
+	for om.Attempts = 1; om.Attempts <= om.MaxRetries+1; om.Attempts++ {
+		snapshot := append([]testObject{}, om.Database...)
+		if err = fn(); err != nil {
+			om.Database = snapshot
+			om.RolledBack = true
+			continue
+		}
+		if om.CommitErr != nil {
+			om.Database = snapshot
+			om.RolledBack = true
+			return om.CommitErr
+		}
+		om.Committed = true
+		return nil
+	}
+	return err
+}
+
+func TestGetHandlerAtomicCommit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+	blw := &MockGinBodyResponseWriter{MockBody: bytes.NewBufferString(""), ResponseWriter: c.Writer}
+	c.Writer = blw
+	MockJsonPost(c, map[string]any{"name": "test", "age": 20})
+
+	objectManager := &testTransactionalManager{}
+	viewSet := NewViewSet[testObject, testObjectRequest](
+		"/objects", "/:pk", nil, nil, objectManager, nil, nil, nil, nil,
+	)
+
+	handler := getHandler(DEFAULT_CREATE_ACTION, *viewSet, Create[testObject, testObjectRequest])
+	handler(c)
+
+	assert.Equal(t, `{"age":20,"name":"test"}`, blw.MockBody.String())
+	assert.Equal(t, http.StatusCreated, blw.MockStatusCode)
+	assert.Equal(t, true, objectManager.Committed)
+	assert.Equal(t, 1, len(objectManager.Database))
+}
+
+func TestGetHandlerAtomicRollbackOnHandlerError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+	blw := &MockGinBodyResponseWriter{MockBody: bytes.NewBufferString(""), ResponseWriter: c.Writer}
+	c.Writer = blw
+	MockJsonPost(c, map[string]any{"name": "test", "age": 20})
+
+	objectManager := &testTransactionalManager{}
+	viewSet := NewViewSet[testObject, testObjectRequest](
+		"/objects", "/:pk", nil, nil, objectManager, nil, nil, nil, nil,
+	)
+
+	handler := getHandler(
+		DEFAULT_CREATE_ACTION,
+		*viewSet,
+		func(action string, vs *ViewSet[testObject, testObjectRequest], ctx *gin.Context) {
+			Create(action, vs, ctx)
+			vs.ExceptionHandler.Handle(errors.New("after save"), ctx)
+		},
+	)
+	handler(c)
+
+	assert.Equal(t, true, objectManager.RolledBack)
+	assert.Equal(t, false, objectManager.Committed)
+	assert.Equal(t, 0, len(objectManager.Database))
+}
+
+func TestGetHandlerAtomicRetryReplaysBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+	blw := &MockGinBodyResponseWriter{MockBody: bytes.NewBufferString(""), ResponseWriter: c.Writer}
+	c.Writer = blw
+	MockJsonPost(c, map[string]any{"name": "test", "age": 20})
+
+	objectManager := &testTransactionalManager{MaxRetries: 1}
+	viewSet := NewViewSet[testObject, testObjectRequest](
+		"/objects", "/:pk", nil, nil, objectManager, nil, nil, nil, nil,
+	)
+	calls := 0
+
+	handler := getHandler(
+		DEFAULT_CREATE_ACTION,
+		*viewSet,
+		func(action string, vs *ViewSet[testObject, testObjectRequest], ctx *gin.Context) {
+			calls += 1
+			Create(action, vs, ctx)
+			if calls == 1 {
+				vs.ExceptionHandler.Handle(errors.New("deadlock"), ctx)
+			}
+		},
+	)
+	handler(c)
+
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, `{"age":20,"name":"test"}`, blw.MockBody.String())
+	assert.Equal(t, http.StatusCreated, blw.MockStatusCode)
+	assert.Equal(t, 0, len(c.Errors))
+	assert.Equal(t, 1, len(objectManager.Database))
+}
+
+func TestGetHandlerAtomicCommitError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+	blw := &MockGinBodyResponseWriter{MockBody: bytes.NewBufferString(""), ResponseWriter: c.Writer}
+	c.Writer = blw
+	MockJsonPost(c, map[string]any{"name": "test", "age": 20})
+
+	objectManager := &testTransactionalManager{CommitErr: errors.New("commit error")}
+	viewSet := NewViewSet[testObject, testObjectRequest](
+		"/objects", "/:pk", nil, nil, objectManager, nil, nil, nil, nil,
+	)
+
+	handler := getHandler(DEFAULT_CREATE_ACTION, *viewSet, Create[testObject, testObjectRequest])
+	handler(c)
+
+	assert.Equal(t, `{"message":"internal server error"}`, blw.MockBody.String())
+	assert.Equal(t, http.StatusInternalServerError, blw.MockStatusCode)
+	assert.Equal(t, 0, len(objectManager.Database))
+}
+
+func TestGetHandlerSkipsAtomicForSafeMethods(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	writer := c.Writer
+
+	objectManager := &testTransactionalManager{}
+	viewSet := NewViewSet[testObject, testObjectRequest](
+		"/objects", "/:pk", nil, nil, objectManager, nil, nil, nil, nil,
+	)
+	handler := getHandler(
+		DEFAULT_LIST_ACTION,
+		*viewSet,
+		func(_ string, _ *ViewSet[testObject, testObjectRequest], c *gin.Context) {
+			// streamed responses must reach the client as they are written
+			assert.Equal(t, writer, c.Writer)
+		},
+	)
+	handler(c)
+
+	assert.Equal(t, 0, objectManager.Attempts)
+}
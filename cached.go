@@ -0,0 +1,65 @@
+package viewset
+
+import (
+	"reflect"
+
+	"github.com/TcMits/viewset/pkg/fieldplan"
+	"github.com/TcMits/viewset/pkg/fieldset"
+	"github.com/gin-gonic/gin"
+)
+
+var _ Serializer[any] = &CachedSerializer[any]{}
+
+// CachedSerializer works like DefaultSerializer but converts entities with
+// a field plan built once per type, see pkg/fieldplan. Keys come from the
+// mapstructure tag, then the json tag, time.Time values are kept as they are
+// and sql.Null* values become their value or nil.
+type CachedSerializer[EntityType any] struct {
+	DefaultSerializer[EntityType]
+}
+
+func NewCachedSerializer[EntityType any](
+	additionalField map[string]Field[EntityType],
+) *CachedSerializer[EntityType] {
+	return &CachedSerializer[EntityType]{
+		DefaultSerializer: DefaultSerializer[EntityType]{AdditionalField: additionalField},
+	}
+}
+
+func (s *CachedSerializer[EntityType]) Serialize(
+	dest *map[string]any, entity *EntityType, c *gin.Context,
+) error {
+	return s.serialize(dest, entity, requestFieldset(c), requestExpand(c), c)
+}
+
+func (s *CachedSerializer[EntityType]) serialize(
+	dest *map[string]any, entity *EntityType, fields fieldset.Set, expand fieldset.Tree, c *gin.Context,
+) error {
+	plan := fieldplan.For[EntityType]()
+	if *dest == nil {
+		*dest = plan.Map(reflect.ValueOf(entity), len(s.AdditionalField)+len(expand))
+	} else {
+		plan.MapInto(*dest, reflect.ValueOf(entity))
+	}
+	return s.complete(dest, entity, fields, expand, c)
+}
+
+func (s *CachedSerializer[EntityType]) ManySerialize(
+	dest *[]map[string]any, entities *[]*EntityType, c *gin.Context,
+) error {
+	fields := requestFieldset(c)
+	expand := requestExpand(c)
+	if free := cap(*dest) - len(*dest); free < len(*entities) {
+		grown := make([]map[string]any, len(*dest), len(*dest)+len(*entities))
+		copy(grown, *dest)
+		*dest = grown
+	}
+	for _, entity := range *entities {
+		var destObject map[string]any
+		if err := s.serialize(&destObject, entity, fields, expand, c); err != nil {
+			return err
+		}
+		*dest = append(*dest, destObject)
+	}
+	return nil
+}
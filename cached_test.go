@@ -0,0 +1,132 @@
+package viewset
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type testListing struct {
+	ID          uint           `mapstructure:"id"`
+	Title       string         `mapstructure:"title"`
+	Description string         `mapstructure:"description"`
+	Price       float64        `mapstructure:"price"`
+	Quantity    int            `mapstructure:"quantity"`
+	Active      bool           `mapstructure:"active"`
+	Seller      string         `mapstructure:"seller"`
+	Category    string         `mapstructure:"category"`
+	Password    string         `mapstructure:"password" viewset:"write_only"`
+	Note        sql.NullString `mapstructure:"note"`
+	CreatedAt   time.Time      `mapstructure:"created_at"`
+}
+
+type testListingSummary struct{}
+
+func (_ *testListingSummary) Serialize(listing *testListing, _ *gin.Context) (any, error) {
+	return map[string]any{"name": listing.Title}, nil
+}
+
+func newTestListings(n int) []*testListing {
+	created := time.Date(2022, 12, 1, 0, 0, 0, 0, time.UTC)
+	listings := make([]*testListing, 0, n)
+	for i := 0; i < n; i++ {
+		listings = append(listings, &testListing{
+			ID:          uint(i + 1),
+			Title:       fmt.Sprintf("listing %d", i),
+			Description: "a listing",
+			Price:       9.99,
+			Quantity:    i,
+			Active:      true,
+			Seller:      "phuc",
+			Category:    "books",
+			Password:    "secret",
+			Note:        sql.NullString{String: "note", Valid: i%2 == 0},
+			CreatedAt:   created,
+		})
+	}
+	return listings
+}
+
+func TestCachedSerializerSerialize(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/?omit=description", nil)
+	serializer := NewCachedSerializer(map[string]Field[testListing]{"summary": &testListingSummary{}})
+	listing := newTestListings(1)[0]
+	result := map[string]any{}
+
+	assert.NoError(t, serializer.Serialize(&result, listing, c))
+	assert.Equal(t, map[string]any{
+		"id":         uint(1),
+		"title":      "listing 0",
+		"price":      9.99,
+		"quantity":   0,
+		"active":     true,
+		"seller":     "phuc",
+		"category":   "books",
+		"note":       "note",
+		"created_at": listing.CreatedAt,
+		"summary":    map[string]any{"name": "listing 0"},
+	}, result)
+}
+
+func TestCachedSerializerManySerialize(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/?fields=id,note", nil)
+	serializer := NewCachedSerializer[testListing](nil)
+	listings := newTestListings(2)
+	results := []map[string]any{{"id": 0}}
+
+	assert.NoError(t, serializer.ManySerialize(&results, &listings, c))
+	assert.Equal(t, []map[string]any{
+		{"id": 0},
+		{"id": uint(1), "note": "note"},
+		{"id": uint(2), "note": nil},
+	}, results)
+}
+
+func benchmarkManySerialize(b *testing.B, serializer Serializer[testListing]) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	listings := newTestListings(100)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		results := make([]map[string]any, 0, len(listings))
+		if err := serializer.ManySerialize(&results, &listings, c); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDefaultSerializerManySerialize(b *testing.B) {
+	benchmarkManySerialize(b, &DefaultSerializer[testListing]{})
+}
+
+func BenchmarkCachedSerializerManySerialize(b *testing.B) {
+	benchmarkManySerialize(b, NewCachedSerializer[testListing](nil))
+}
+
+func TestCachedSerializerSerializeWithNestedFieldRules(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	serializer := NewCachedSerializer[testPost](nil)
+	result := map[string]any{}
+
+	assert.NoError(t, serializer.Serialize(&result, newTestPost(), c))
+	assert.Equal(t, map[string]any{
+		"title":    "post",
+		"author":   map[string]any{"id": uint(2)},
+		"editors":  []any{map[string]any{"id": uint(3)}},
+		"reviewer": map[string]any{"id": uint(4)},
+	}, result)
+}
@@ -0,0 +1,593 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/types"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/TcMits/viewset/pkg/fieldrule"
+)
+
+const HEADER = "// Code generated by viewset-gen. DO NOT EDIT.\n"
+
+type generator struct {
+	pkg     *types.Package
+	entity  *types.Named
+	imports map[string]string
+	// import path -> package name
+	helpers map[*types.Named]string
+	queue   []*types.Named
+	names   map[string]bool
+	buf     bytes.Buffer
+}
+
+func generate(pkg *types.Package, typeName string, validateName string) ([]byte, error) {
+	entity, err := lookupStruct(pkg, typeName)
+	if err != nil {
+		return nil, err
+	}
+	g := &generator{
+		pkg:     pkg,
+		entity:  entity,
+		imports: map[string]string{"github.com/TcMits/viewset": "viewset"},
+		helpers: map[*types.Named]string{},
+		names:   map[string]bool{},
+	}
+	if err := g.serializer(); err != nil {
+		return nil, err
+	}
+	if validateName != "" {
+		validate, err := lookupStruct(pkg, validateName)
+		if err != nil {
+			return nil, err
+		}
+		if err := g.validator(validate); err != nil {
+			return nil, err
+		}
+		if err := g.mapping(validate); err != nil {
+			return nil, err
+		}
+	}
+
+	var file bytes.Buffer
+	file.WriteString(HEADER)
+	fmt.Fprintf(&file, "\npackage %s\n\nimport (\n", pkg.Name())
+	paths := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		// standard library first
+		iStd, jStd := standard(paths[i]), standard(paths[j])
+		if iStd != jStd {
+			return iStd
+		}
+		return paths[i] < paths[j]
+	})
+	for i, path := range paths {
+		if i > 0 && standard(paths[i-1]) && !standard(path) {
+			file.WriteString("\n")
+		}
+		fmt.Fprintf(&file, "\t%q\n", path)
+	}
+	file.WriteString(")\n")
+	file.Write(g.buf.Bytes())
+	return format.Source(file.Bytes())
+}
+
+func standard(path string) bool {
+	return !strings.Contains(strings.Split(path, "/")[0], ".")
+}
+
+func lookupStruct(pkg *types.Package, name string) (*types.Named, error) {
+	typeName, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("type %s not found in %s", name, pkg.Path())
+	}
+	named, ok := typeName.Type().(*types.Named)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a named type", name)
+	}
+	if _, ok := named.Underlying().(*types.Struct); !ok {
+		return nil, fmt.Errorf("%s is not a struct", name)
+	}
+	if named.TypeParams().Len() > 0 {
+		return nil, fmt.Errorf("%s is generic", name)
+	}
+	return named, nil
+}
+
+func (g *generator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *generator) qualifier(pkg *types.Package) string {
+	if pkg == g.pkg {
+		return ""
+	}
+	g.imports[pkg.Path()] = pkg.Name()
+	return pkg.Name()
+}
+
+func (g *generator) typeString(t types.Type) string {
+	return types.TypeString(t, g.qualifier)
+}
+
+func (g *generator) use(path string) {
+	g.imports[path] = path[strings.LastIndex(path, "/")+1:]
+}
+
+func upperFirst(s string) string {
+	runes := []rune(s)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+func lowerFirst(s string) string {
+	runes := []rune(s)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+// helper returns the name of the function writing the keys of a struct
+// type, it is generated after the current one.
+func (g *generator) helper(named *types.Named) (string, error) {
+	if name, ok := g.helpers[named]; ok {
+		return name, nil
+	}
+	obj := named.Obj()
+	if obj.Pkg() != g.pkg && !obj.Exported() {
+		return "", fmt.Errorf("unexported type %s cannot be used outside its package", g.typeString(named))
+	}
+	if named.TypeArgs().Len() > 0 {
+		return "", fmt.Errorf("generic type %s is not supported", g.typeString(named))
+	}
+	name := "Map" + obj.Name()
+	if named != g.entity {
+		prefix := lowerFirst(g.entity.Obj().Name()) + "Map"
+		if obj.Pkg() != g.pkg {
+			prefix += upperFirst(obj.Pkg().Name())
+		}
+		name = prefix + obj.Name()
+		for i := 2; g.names[name]; i++ {
+			name = prefix + obj.Name() + strconv.Itoa(i)
+		}
+	}
+	g.names[name] = true
+	g.helpers[named] = name
+	g.queue = append(g.queue, named)
+	return name, nil
+}
+
+func (g *generator) serializer() error {
+	entity := g.entity.Obj().Name()
+	g.printf("\nvar _ viewset.Serializer[%s] = &%sSerializer{}\n", entity, entity)
+	g.printf("\n// %sSerializer serializes %s like DefaultSerializer, without reflection.\n", entity, entity)
+	g.printf("type %sSerializer struct {\n\tviewset.MappedSerializer[%s]\n}\n", entity, entity)
+	g.printf("\nfunc New%sSerializer(additionalField map[string]viewset.Field[%s]) *%sSerializer {\n",
+		entity, entity, entity)
+	g.printf("\treturn &%sSerializer{MappedSerializer: viewset.MappedSerializer[%s]{\n", entity, entity)
+	g.printf("\t\tDefaultSerializer: viewset.DefaultSerializer[%s]{AdditionalField: additionalField},\n", entity)
+	g.printf("\t\tMapInto: Map%s,\n\t}}\n}\n", entity)
+
+	if _, err := g.helper(g.entity); err != nil {
+		return err
+	}
+	for len(g.queue) > 0 {
+		named := g.queue[0]
+		g.queue = g.queue[1:]
+		name := g.helpers[named]
+		g.printf("\n")
+		if named == g.entity {
+			g.printf("// %s writes the keys mapstructure decodes from a %s.\n", name, entity)
+		}
+		g.printf("func %s(dest map[string]any, value *%s) {\n", name, g.typeString(named))
+		if err := g.mapFields(named.Underlying().(*types.Struct), "value"); err != nil {
+			return err
+		}
+		g.printf("}\n")
+	}
+	return nil
+}
+
+// mapstructureTag reads a field tag the way mapstructure encodes structs to
+// maps.
+func mapstructureTag(fieldName string, tag string) (key string, omitEmpty bool, squash bool, skip bool) {
+	key = fieldName
+	if index := strings.Index(tag, ","); index != -1 {
+		if tag[:index] == "-" {
+			return "", false, false, true
+		}
+		omitEmpty = strings.Contains(tag[index+1:], "omitempty")
+		squash = strings.Contains(tag[index+1:], "squash")
+		if tag[:index] != "" {
+			key = tag[:index]
+		}
+		return key, omitEmpty, squash, false
+	}
+	if tag == "-" {
+		return "", false, false, true
+	}
+	if tag != "" {
+		key = tag
+	}
+	return key, false, false, false
+}
+
+// convertibleStruct reports whether mapstructure follows a pointer to the
+// struct, which it does when a field has a mapstructure tag.
+func convertibleStruct(t types.Type) (*types.Named, bool) {
+	pointer, ok := t.Underlying().(*types.Pointer)
+	if !ok {
+		return nil, false
+	}
+	st, ok := pointer.Elem().Underlying().(*types.Struct)
+	if !ok {
+		return nil, false
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		if reflect.StructTag(st.Tag(i)).Get("mapstructure") != "" {
+			named, ok := pointer.Elem().(*types.Named)
+			return named, ok
+		}
+	}
+	return nil, false
+}
+
+func structType(t types.Type) (*types.Named, bool) {
+	if _, ok := t.Underlying().(*types.Struct); !ok {
+		return nil, false
+	}
+	named, ok := t.(*types.Named)
+	return named, ok
+}
+
+func hasExported(named *types.Named) bool {
+	st := named.Underlying().(*types.Struct)
+	for i := 0; i < st.NumFields(); i++ {
+		if st.Field(i).Exported() {
+			return true
+		}
+	}
+	return false
+}
+
+// marshalsItself reports whether values of the type implement json.Marshaler,
+// like time.Time.
+func marshalsItself(t types.Type) bool {
+	method, _, _ := types.LookupFieldOrMethod(t, false, nil, "MarshalJSON")
+	_, ok := method.(*types.Func)
+	return ok
+}
+
+// nonEmpty returns the condition under which mapstructure keeps an
+// omitempty value, an empty string if it always does.
+func nonEmpty(t types.Type, value string) string {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsString != 0:
+			return value + ` != ""`
+		case u.Info()&types.IsBoolean != 0:
+			return value
+		case u.Info()&(types.IsInteger|types.IsFloat) != 0:
+			return value + " != 0"
+		}
+	case *types.Slice, *types.Map, *types.Array:
+		return "len(" + value + ") != 0"
+	case *types.Pointer, *types.Interface:
+		return value + " != nil"
+	}
+	return ""
+}
+
+func (g *generator) mapFields(st *types.Struct, expr string) error {
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		if !field.Exported() {
+			continue
+		}
+		key, omitEmpty, squash, skip := mapstructureTag(
+			field.Name(), reflect.StructTag(st.Tag(i)).Get("mapstructure"),
+		)
+		if skip {
+			continue
+		}
+		value := expr + "." + field.Name()
+		t := field.Type()
+
+		if named, ok := convertibleStruct(t); ok && !squash {
+			helper, err := g.helper(named)
+			if err != nil {
+				return err
+			}
+			g.printf("if %s != nil {\n", value)
+			g.printf("nested := map[string]any{}\n%s(nested, %s)\ndest[%q] = nested\n", helper, value, key)
+			if !omitEmpty {
+				g.printf("} else {\ndest[%q] = %s\n", key, value)
+			}
+			g.printf("}\n")
+			continue
+		}
+		if _, ok := t.Underlying().(*types.Struct); ok && !squash && marshalsItself(t) {
+			// kept as it is like CachedSerializer does, mapstructure would
+			// write an empty map, time.Time is rendered in RFC 3339
+			g.printf("dest[%q] = %s\n", key, value)
+			continue
+		}
+		if _, ok := t.Underlying().(*types.Struct); ok {
+			named, ok := structType(t)
+			if !ok {
+				return fmt.Errorf("field %s has an anonymous struct type", value)
+			}
+			if !hasExported(named) {
+				// nothing to write
+				if !squash {
+					g.printf("dest[%q] = map[string]any{}\n", key)
+				}
+				continue
+			}
+			helper, err := g.helper(named)
+			if err != nil {
+				return err
+			}
+			if squash {
+				g.printf("%s(dest, &%s)\n", helper, value)
+				continue
+			}
+			g.printf("{\nnested := map[string]any{}\n%s(nested, &%s)\ndest[%q] = nested\n}\n", helper, value, key)
+			continue
+		}
+		if squash {
+			return fmt.Errorf("cannot squash %s of type %s", value, g.typeString(t))
+		}
+		if condition := nonEmpty(t, value); omitEmpty && condition != "" {
+			g.printf("if %s {\ndest[%q] = %s\n}\n", condition, key, value)
+			continue
+		}
+		g.printf("dest[%q] = %s\n", key, value)
+	}
+	return nil
+}
+
+type source struct {
+	key       string
+	expr      string
+	typ       types.Type
+	omitEmpty bool
+}
+
+// sources returns the keys mapstructure encodes from the validate type,
+// squashed structs included.
+func sources(st *types.Struct, expr string) ([]source, error) {
+	result := []source{}
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		if !field.Exported() {
+			continue
+		}
+		key, omitEmpty, squash, skip := mapstructureTag(
+			field.Name(), reflect.StructTag(st.Tag(i)).Get("mapstructure"),
+		)
+		if skip {
+			continue
+		}
+		value := expr + "." + field.Name()
+		if squash {
+			embedded, ok := field.Type().Underlying().(*types.Struct)
+			if !ok {
+				return nil, fmt.Errorf("cannot squash %s of type %s", value, field.Type())
+			}
+			squashed, err := sources(embedded, value)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, squashed...)
+			continue
+		}
+		result = append(result, source{key: key, expr: value, typ: field.Type(), omitEmpty: omitEmpty})
+	}
+	// later keys replace earlier ones in the map
+	seen := map[string]bool{}
+	unique := make([]source, 0, len(result))
+	for i := len(result) - 1; i >= 0; i-- {
+		if !seen[result[i].key] {
+			seen[result[i].key] = true
+			unique = append([]source{result[i]}, unique...)
+		}
+	}
+	return unique, nil
+}
+
+type target struct {
+	key  string
+	expr string
+	typ  types.Type
+}
+
+// targets returns the fields mapstructure decodes a map into, fields of
+// squashed structs come after the others.
+func targets(st *types.Struct, expr string) ([]target, error) {
+	result := []target{}
+	type pending struct {
+		st   *types.Struct
+		expr string
+	}
+	structs := []pending{{st, expr}}
+	for len(structs) > 0 {
+		current := structs[0]
+		structs = structs[1:]
+		for i := 0; i < current.st.NumFields(); i++ {
+			field := current.st.Field(i)
+			tagParts := strings.Split(reflect.StructTag(current.st.Tag(i)).Get("mapstructure"), ",")
+			value := current.expr + "." + field.Name()
+			squash := false
+			for _, option := range tagParts[1:] {
+				squash = squash || option == "squash"
+			}
+			if squash {
+				embedded, ok := field.Type().Underlying().(*types.Struct)
+				if !ok {
+					return nil, fmt.Errorf("cannot squash %s of type %s", value, field.Type())
+				}
+				structs = append(structs, pending{embedded, value})
+				continue
+			}
+			if !field.Exported() || tagParts[0] == "-" {
+				continue
+			}
+			key := field.Name()
+			if tagParts[0] != "" {
+				key = tagParts[0]
+			}
+			result = append(result, target{key: key, expr: value, typ: field.Type()})
+		}
+	}
+	return result, nil
+}
+
+// entityRules reads the viewset tags of the entity, rules declared by a
+// FieldRules method are not known here.
+func entityRules(st *types.Struct, rules fieldrule.Rules) fieldrule.Rules {
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		tag := reflect.StructTag(st.Tag(i))
+		parts := strings.Split(tag.Get("mapstructure"), ",")
+		squash := false
+		for _, option := range parts[1:] {
+			squash = squash || option == "squash"
+		}
+		if squash {
+			embedded := field.Type().Underlying()
+			if pointer, ok := embedded.(*types.Pointer); ok {
+				embedded = pointer.Elem().Underlying()
+			}
+			if embedded, ok := embedded.(*types.Struct); ok {
+				entityRules(embedded, rules)
+			}
+			continue
+		}
+		var rule fieldrule.Rule
+		found := false
+		for _, option := range strings.Split(tag.Get(fieldrule.TAG), ",") {
+			switch strings.TrimSpace(option) {
+			case fieldrule.READ_ONLY:
+				rule.ReadOnly, found = true, true
+			case fieldrule.WRITE_ONLY:
+				rule.WriteOnly, found = true, true
+			case fieldrule.HIDDEN:
+				rule.Hidden, found = true, true
+			}
+		}
+		if found {
+			name := parts[0]
+			if name == "" {
+				name = field.Name()
+			}
+			rules[name] = rule
+		}
+	}
+	return rules
+}
+
+func (g *generator) mapping(validate *types.Named) error {
+	entity := g.entity.Obj().Name()
+	validateName := validate.Obj().Name()
+	srcs, err := sources(validate.Underlying().(*types.Struct), "validatedData")
+	if err != nil {
+		return err
+	}
+	tgts, err := targets(g.entity.Underlying().(*types.Struct), "entity")
+	if err != nil {
+		return err
+	}
+	rules := entityRules(g.entity.Underlying().(*types.Struct), fieldrule.Rules{})
+	writable := make([]source, 0, len(srcs))
+	for _, src := range srcs {
+		if rules.Writable(src.key) {
+			writable = append(writable, src)
+		}
+	}
+
+	g.use("github.com/gin-gonic/gin")
+	g.use("github.com/TcMits/viewset/manager")
+	g.printf("\n// %sTo%s assigns the writable fields of %s to %s like the default\n", validateName, entity, validateName, entity)
+	g.printf("// create functions of the managers, leaving out the fields the rules and\n")
+	g.printf("// hidden fields of the request exclude.\n")
+	g.printf("func %sTo%s(validatedData *%s, entity *%s, c *gin.Context) {\n", validateName, entity, validateName, entity)
+	g.printf("writable := map[string]any{\n")
+	for _, src := range writable {
+		g.printf("%q: nil,\n", src.key)
+	}
+	g.printf("}\n")
+	g.printf("manager.FilterWritable[%s](writable, c)\n", entity)
+	for _, tgt := range tgts {
+		src, ok := matchSource(writable, tgt.key)
+		if !ok {
+			continue
+		}
+		assignment, err := g.assign(tgt, src)
+		if err != nil {
+			return err
+		}
+		condition := fmt.Sprintf("_, ok := writable[%q]; ok", src.key)
+		if nonEmpty := nonEmpty(src.typ, src.expr); src.omitEmpty && nonEmpty != "" {
+			condition += " && " + nonEmpty
+		}
+		g.printf("if %s {\n%s}\n", condition, assignment)
+	}
+	g.printf("}\n")
+	return nil
+}
+
+func matchSource(srcs []source, key string) (source, bool) {
+	for _, src := range srcs {
+		if src.key == key {
+			return src, true
+		}
+	}
+	for _, src := range srcs {
+		if strings.EqualFold(src.key, key) {
+			return src, true
+		}
+	}
+	return source{}, false
+}
+
+func basicInfo(t types.Type) types.BasicInfo {
+	if basic, ok := t.Underlying().(*types.Basic); ok {
+		return basic.Info()
+	}
+	return 0
+}
+
+func (g *generator) assign(tgt target, src source) (string, error) {
+	switch {
+	case types.Identical(tgt.typ, src.typ):
+		if _, ok := src.typ.Underlying().(*types.Pointer); ok {
+			return fmt.Sprintf("if %s != nil {\ncopied := *%s\n%s = &copied\n}\n", src.expr, src.expr, tgt.expr), nil
+		}
+		return fmt.Sprintf("%s = %s\n", tgt.expr, src.expr), nil
+	}
+	if pointer, ok := src.typ.Underlying().(*types.Pointer); ok && types.Identical(pointer.Elem(), tgt.typ) {
+		return fmt.Sprintf("if %s != nil {\n%s = *%s\n}\n", src.expr, tgt.expr, src.expr), nil
+	}
+	if pointer, ok := tgt.typ.Underlying().(*types.Pointer); ok && types.Identical(pointer.Elem(), src.typ) {
+		return fmt.Sprintf("{\ncopied := %s\n%s = &copied\n}\n", src.expr, tgt.expr), nil
+	}
+	const numeric = types.IsInteger | types.IsFloat
+	tgtInfo, srcInfo := basicInfo(tgt.typ), basicInfo(src.typ)
+	if tgtInfo&numeric != 0 && srcInfo&numeric != 0 && tgtInfo&types.IsUntyped == 0 ||
+		tgtInfo&types.IsString != 0 && srcInfo&types.IsString != 0 ||
+		tgtInfo&types.IsBoolean != 0 && srcInfo&types.IsBoolean != 0 {
+		return fmt.Sprintf("%s = %s(%s)\n", tgt.expr, g.typeString(tgt.typ), src.expr), nil
+	}
+	return "", fmt.Errorf("cannot assign %s (%s) to %s (%s)",
+		src.expr, g.typeString(src.typ), tgt.expr, g.typeString(tgt.typ))
+}
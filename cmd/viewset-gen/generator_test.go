@@ -0,0 +1,92 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func checkSource(t *testing.T, source string) *types.Package {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "source.go", source, 0)
+	require.NoError(t, err)
+	pkg, err := (&types.Config{}).Check("example", fset, []*ast.File{file}, nil)
+	require.NoError(t, err)
+	return pkg
+}
+
+func TestGenerateMatchesExample(t *testing.T) {
+	dir := filepath.Join("..", "..", "examples", "generated")
+	pkg, err := loadPackage(dir, "book_viewset_gen.go")
+	require.NoError(t, err)
+
+	source, err := generate(pkg, "Book", "BookRequest")
+	require.NoError(t, err)
+	committed, err := os.ReadFile(filepath.Join(dir, "book_viewset_gen.go"))
+	require.NoError(t, err)
+	assert.Equal(t, string(committed), string(source), "run go generate ./examples/...")
+}
+
+func TestGenerateWithoutValidateType(t *testing.T) {
+	pkg := checkSource(t, `package example
+type Node struct {
+	Name string `+"`mapstructure:\"name\"`"+`
+	Next *Node `+"`mapstructure:\"next,omitempty\"`"+`
+}`)
+
+	source, err := generate(pkg, "Node", "")
+	require.NoError(t, err)
+	assert.Contains(t, string(source), "func MapNode(dest map[string]any, value *Node) {")
+	assert.Contains(t, string(source), "MapNode(nested, value.Next)")
+	assert.NotContains(t, string(source), "Validator")
+}
+
+func TestGenerateKeepsMarshalers(t *testing.T) {
+	pkg := checkSource(t, `package example
+type Stamp struct{ unix int64 }
+func (Stamp) MarshalJSON() ([]byte, error) { return nil, nil }
+type Opaque struct{ id int }
+type Entity struct {
+	Created Stamp  `+"`mapstructure:\"created\"`"+`
+	Handle  Opaque `+"`mapstructure:\"handle\"`"+`
+}`)
+
+	source, err := generate(pkg, "Entity", "")
+	require.NoError(t, err)
+	assert.Contains(t, string(source), `dest["created"] = value.Created`)
+	assert.Contains(t, string(source), `dest["handle"] = map[string]any{}`)
+}
+
+func TestGenerateErrors(t *testing.T) {
+	cases := map[string]struct {
+		source   string
+		validate string
+	}{
+		"anonymous struct": {source: `package example
+type Entity struct { Inner struct{ Name string } }`},
+		"squashed pointer": {source: `package example
+type Base struct { Name string }
+type Entity struct { *Base ` + "`mapstructure:\",squash\"`" + ` }`},
+		"unsupported binding tag": {source: `package example
+type Entity struct { Name string }
+type Request struct { Name string ` + "`binding:\"email\"`" + ` }`, validate: "Request"},
+		"unassignable field": {source: `package example
+type Entity struct { Name string }
+type Request struct { Name []string }`, validate: "Request"},
+		"not a struct": {source: `package example
+type Entity int`},
+	}
+	for name, testCase := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := generate(checkSource(t, testCase.source), "Entity", testCase.validate)
+			assert.Error(t, err)
+		})
+	}
+}
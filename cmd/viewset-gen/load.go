@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// loadPackage type-checks the package in dir, leaving out its tests and the
+// output file which may be stale. Type errors are ignored, the declarations
+// read are those which could be checked.
+func loadPackage(dir string, output string) (*types.Package, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	files := []*ast.File{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") || name == output {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no Go files in %s", dir)
+	}
+
+	lookup, err := exportLookup(dir)
+	if err != nil {
+		return nil, err
+	}
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "gc", lookup),
+		// the package may use what is about to be generated
+		Error: func(error) {},
+	}
+	pkg, _ := conf.Check(files[0].Name.Name, fset, files, nil)
+	return pkg, nil
+}
+
+// exportLookup finds the export data of the dependencies with go list,
+// which builds them if needed.
+func exportLookup(dir string) (importer.Lookup, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("go", "list", "-e", "-export", "-deps", "-f", "{{.ImportPath}}\t{{.Export}}", ".")
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("go list: %w: %s", err, stderr.String())
+	}
+	exports := map[string]string{}
+	scanner := bufio.NewScanner(&stdout)
+	for scanner.Scan() {
+		if path, export, ok := strings.Cut(scanner.Text(), "\t"); ok && export != "" {
+			exports[path] = export
+		}
+	}
+	return func(path string) (io.ReadCloser, error) {
+		export, ok := exports[path]
+		if !ok {
+			return nil, fmt.Errorf("no export data for %s", path)
+		}
+		return os.Open(export)
+	}, nil
+}
@@ -0,0 +1,56 @@
+// Command viewset-gen writes a Serializer, and optionally a FormValidator and
+// a mapping function, for a pair of types without reflection, e.g.
+//
+//	//go:generate go run github.com/TcMits/viewset/cmd/viewset-gen -type Book -validate BookRequest
+//
+// The serializer yields the same keys as DefaultSerializer, keeping time.Time
+// values like CachedSerializer, it is built from
+// viewset.MappedSerializer so additional fields, field rules, fieldsets and
+// expansions work the same.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	typeName := flag.String("type", "", "entity type, required")
+	validateName := flag.String("validate", "", "optional, validate type of the entity")
+	output := flag.String("output", "", "output file, defaults to <type>_viewset_gen.go")
+	flag.Parse()
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+	if *typeName == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *output == "" {
+		*output = strings.ToLower(*typeName) + "_viewset_gen.go"
+	}
+	if err := run(dir, *typeName, *validateName, *output); err != nil {
+		fmt.Fprintln(os.Stderr, "viewset-gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir string, typeName string, validateName string, output string) error {
+	if !filepath.IsAbs(output) {
+		output = filepath.Join(dir, output)
+	}
+	pkg, err := loadPackage(dir, filepath.Base(output))
+	if err != nil {
+		return err
+	}
+	source, err := generate(pkg, typeName, validateName)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(output, source, 0o644)
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"fmt"
+	"go/types"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+func (g *generator) validator(validate *types.Named) error {
+	entity := g.entity.Obj().Name()
+	name := validate.Obj().Name()
+	g.use("encoding/json")
+	g.use("errors")
+	g.use("github.com/gin-gonic/gin")
+	g.use("github.com/gin-gonic/gin/binding")
+
+	g.printf("\nvar _ viewset.FormValidator[%s, %s] = &%sValidator{}\n", entity, name, name)
+	g.printf("\n// %sValidator decodes JSON bodies and checks them with Validate%s,\n", name, name)
+	g.printf("// other bodies are bound by gin.\n")
+	g.printf("type %sValidator struct{}\n", name)
+	g.printf("\nfunc (_ *%sValidator) Validate(dest *%s, _ *%s, c *gin.Context) error {\n", name, name, entity)
+	g.printf("if c.ContentType() != binding.MIMEJSON {\nreturn c.ShouldBind(dest)\n}\n")
+	g.printf("if c.Request == nil || c.Request.Body == nil {\nreturn errors.New(\"invalid request\")\n}\n")
+	g.printf("if err := json.NewDecoder(c.Request.Body).Decode(dest); err != nil {\nreturn err\n}\n")
+	g.printf("return Validate%s(dest)\n}\n", name)
+
+	g.printf("\n// Validate%s checks the binding tags of a %s, the first failing tag\n", name, name)
+	g.printf("// of each field is reported like the validator gin uses does.\n")
+	g.printf("func Validate%s(value *%s) error {\nfailed := viewset.FieldErrors{}\n", name, name)
+	visiting := map[*types.Named]bool{validate: true}
+	if err := g.validateFields(validate.Underlying().(*types.Struct), "value", name, visiting); err != nil {
+		return err
+	}
+	g.printf("if len(failed) > 0 {\nreturn failed\n}\nreturn nil\n}\n")
+	return nil
+}
+
+func (g *generator) validateFields(st *types.Struct, expr string, namespace string, visiting map[*types.Named]bool) error {
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		tag := reflect.StructTag(st.Tag(i)).Get("binding")
+		if !field.Exported() || tag == "-" {
+			continue
+		}
+		value := expr + "." + field.Name()
+		fieldNamespace := namespace + "." + field.Name()
+		t := field.Type()
+		pointer, isPointer := t.Underlying().(*types.Pointer)
+		elem := t
+		if isPointer {
+			elem = pointer.Elem()
+		}
+
+		if embedded, ok := elem.Underlying().(*types.Struct); ok {
+			if tag != "" {
+				return fmt.Errorf("binding tag %q on struct field %s is not supported", tag, value)
+			}
+			named, _ := elem.(*types.Named)
+			if named != nil {
+				if visiting[named] {
+					return fmt.Errorf("recursive type %s is not supported", g.typeString(named))
+				}
+				visiting[named] = true
+			}
+			if isPointer {
+				g.printf("if %s != nil {\n", value)
+			}
+			if err := g.validateFields(embedded, value, fieldNamespace, visiting); err != nil {
+				return err
+			}
+			if isPointer {
+				g.printf("}\n")
+			}
+			delete(visiting, named)
+			continue
+		}
+		if tag == "" {
+			continue
+		}
+
+		tags := strings.Split(tag, ",")
+		omitEmpty := tags[0] == "omitempty"
+		if omitEmpty {
+			tags = tags[1:]
+		}
+		if len(tags) == 0 {
+			continue
+		}
+		elemExpr := value
+		if isPointer {
+			elemExpr = "*" + value
+		}
+		conditions := make([]string, 0, len(tags))
+		for _, option := range tags {
+			name, param, _ := strings.Cut(option, "=")
+			if isPointer && name == "required" {
+				// a pointer which is not nil has a value
+				conditions = append(conditions, "")
+				continue
+			}
+			condition, err := g.failing(name, param, elem, elemExpr)
+			if err != nil {
+				return fmt.Errorf("%s: %w", value, err)
+			}
+			conditions = append(conditions, condition)
+		}
+
+		failure := func(option string) string {
+			name, param, _ := strings.Cut(option, "=")
+			if param != "" {
+				return fmt.Sprintf("failed = append(failed, viewset.FieldError{Namespace: %q, Field: %q, Tag: %q, Param: %q})\n",
+					fieldNamespace, field.Name(), name, param)
+			}
+			return fmt.Sprintf("failed = append(failed, viewset.FieldError{Namespace: %q, Field: %q, Tag: %q})\n",
+				fieldNamespace, field.Name(), name)
+		}
+		chain := &strings.Builder{}
+		first := true
+		if isPointer && !omitEmpty {
+			// every tag fails on a nil pointer, the first one is reported
+			fmt.Fprintf(chain, "if %s == nil {\n%s}", value, failure(tags[0]))
+			first = false
+		}
+		for i, condition := range conditions {
+			if condition == "" {
+				continue
+			}
+			if !first {
+				chain.WriteString(" else ")
+			}
+			fmt.Fprintf(chain, "if %s {\n%s}", condition, failure(tags[i]))
+			first = false
+		}
+		if first {
+			continue
+		}
+		switch {
+		case omitEmpty && isPointer:
+			g.printf("if %s != nil {\n%s\n}\n", value, chain.String())
+		case omitEmpty:
+			guard := nonEmpty(t, value)
+			if guard == "" {
+				return fmt.Errorf("omitempty is not supported on %s", value)
+			}
+			g.printf("if %s {\n%s\n}\n", guard, chain.String())
+		default:
+			g.printf("%s\n", chain.String())
+		}
+	}
+	return nil
+}
+
+// failing returns the condition under which a value fails a binding tag.
+func (g *generator) failing(name string, param string, t types.Type, value string) (string, error) {
+	info := basicInfo(t)
+	var size string
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case info&types.IsString != 0:
+			g.use("unicode/utf8")
+			size = "utf8.RuneCountInString(" + value + ")"
+		case info&(types.IsInteger|types.IsFloat) != 0:
+			size = value
+		case info&types.IsBoolean != 0:
+			if name == "required" {
+				return "!" + value, nil
+			}
+			return "", fmt.Errorf("binding tag %q is not supported on booleans", name)
+		default:
+			return "", fmt.Errorf("type %s is not supported", u)
+		}
+	case *types.Slice, *types.Map:
+		size = "len(" + value + ")"
+	default:
+		return "", fmt.Errorf("type %s is not supported", g.typeString(t))
+	}
+
+	if name == "required" {
+		switch {
+		case info&types.IsString != 0:
+			return value + ` == ""`, nil
+		case info != 0:
+			return value + " == 0", nil
+		}
+		return value + " == nil", nil
+	}
+	if name == "oneof" {
+		return g.oneOf(param, t, value)
+	}
+
+	bound, err := parseParam(param, info)
+	if err != nil {
+		return "", fmt.Errorf("binding tag %s=%s: %w", name, param, err)
+	}
+	operators := map[string]string{
+		"min": "<",
+		"max": ">",
+		"len": "!=",
+		"eq":  "!=",
+		"ne":  "==",
+		"gt":  "<=",
+		"gte": "<",
+		"lt":  ">=",
+		"lte": ">",
+	}
+	operator, ok := operators[name]
+	if !ok {
+		return "", fmt.Errorf("binding tag %q is not supported", name)
+	}
+	if (name == "eq" || name == "ne") && size != value {
+		return "", fmt.Errorf("binding tag %q is only supported on numbers", name)
+	}
+	return size + " " + operator + " " + bound, nil
+}
+
+// parseParam checks a bound the way the validator parses it, sizes are
+// integers.
+func parseParam(param string, info types.BasicInfo) (string, error) {
+	switch {
+	case info&types.IsUnsigned != 0:
+		bound, err := strconv.ParseUint(param, 0, 64)
+		return strconv.FormatUint(bound, 10), err
+	case info&types.IsFloat != 0:
+		bound, err := strconv.ParseFloat(param, 64)
+		return strconv.FormatFloat(bound, 'g', -1, 64), err
+	default:
+		bound, err := strconv.ParseInt(param, 0, 64)
+		return strconv.FormatInt(bound, 10), err
+	}
+}
+
+func (g *generator) oneOf(param string, t types.Type, value string) (string, error) {
+	info := basicInfo(t)
+	values := strings.Fields(param)
+	if len(values) == 0 {
+		return "", fmt.Errorf("binding tag oneof needs values")
+	}
+	conditions := make([]string, 0, len(values))
+	for _, option := range values {
+		switch {
+		case info&types.IsString != 0:
+			conditions = append(conditions, value+" != "+strconv.Quote(option))
+		case info&types.IsInteger != 0:
+			bound, err := parseParam(option, info)
+			if err != nil {
+				return "", fmt.Errorf("binding tag oneof=%s: %w", param, err)
+			}
+			conditions = append(conditions, value+" != "+bound)
+		default:
+			return "", fmt.Errorf("binding tag oneof is only supported on strings and integers")
+		}
+	}
+	return strings.Join(conditions, " && "), nil
+}
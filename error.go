@@ -1,8 +1,10 @@
 package viewset
 
 import (
+	"errors"
 	"net/http"
 
+	"github.com/TcMits/viewset/manager"
 	"github.com/gin-gonic/gin"
 )
 
@@ -12,6 +14,10 @@ type ViewSetError struct {
 	message    string
 	StatusCode int
 	ActualErr  error
+	Code       string
+	// optional, machine-readable code like permission_denied
+	Type string
+	// optional, URI identifying the kind of problem, see RFC 7807
 }
 
 func (err ViewSetError) Error() string {
@@ -22,7 +28,12 @@ func (err ViewSetError) Unwrap() error {
 	return err.ActualErr
 }
 
-type DefaultExceptionHandler struct{}
+// DefaultExceptionHandler writes {"message": ...}, or {"errors": ...} for
+// ValidationErrors.
+type DefaultExceptionHandler struct {
+	ValidationStatusCode int
+	// optional, status of validation errors, e.g. 422, defaults to 400
+}
 
 func NewViewSetError(message string, statusCode int, baseError error) *ViewSetError {
 	return &ViewSetError{
@@ -32,22 +43,97 @@ func NewViewSetError(message string, statusCode int, baseError error) *ViewSetEr
 	}
 }
 
+// NewLocalizedError returns an error with code, its message is the
+// error.<code> message of the request catalog, see Localize, else the
+// message of baseError.
+func NewLocalizedError(c *gin.Context, code string, statusCode int, baseError error, params ...string) *ViewSetError {
+	text, ok := message(c, []string{"error." + code}, params...)
+	if !ok {
+		text = code
+		if baseError != nil {
+			text = baseError.Error()
+		}
+	}
+	return NewViewSetError(text, statusCode, baseError).WithCode(code)
+}
+
+// WithCode sets the machine-readable code of the error.
+func (err *ViewSetError) WithCode(code string) *ViewSetError {
+	err.Code = code
+	return err
+}
+
+// WithType sets the URI identifying the kind of problem.
+func (err *ViewSetError) WithType(uri string) *ViewSetError {
+	err.Type = uri
+	return err
+}
+
+// asViewSetError keeps the status of a ViewSetError returned by a component,
+// other errors get statusCode.
+func asViewSetError(err error, statusCode int) *ViewSetError {
+	viewSetErr := new(ViewSetError)
+	if errors.As(err, &viewSetErr) {
+		return viewSetErr
+	}
+	return NewViewSetError(err.Error(), statusCode, err)
+}
+
+// classifiedError gives the errors of a manager or database the status of
+// their kind, unknown errors are internal ones. Outside debug mode their
+// messages, which may hold SQL, are replaced by generic ones. Components
+// return a ViewSetError to pick another status.
+func classifiedError(err error, c *gin.Context) *ViewSetError {
+	viewSetErr := new(ViewSetError)
+	if errors.As(err, &viewSetErr) {
+		return viewSetErr
+	}
+	statusCode, code := http.StatusInternalServerError, ""
+	switch manager.Classify(err) {
+	case manager.NOT_FOUND_ERROR:
+		statusCode, code = http.StatusNotFound, "not_found"
+	case manager.CONFLICT_ERROR:
+		statusCode, code = http.StatusConflict, "conflict"
+	case manager.FOREIGN_KEY_ERROR:
+		statusCode, code = http.StatusUnprocessableEntity, "foreign_key_violation"
+	case manager.CHECK_ERROR:
+		statusCode, code = http.StatusUnprocessableEntity, "check_violation"
+	case manager.TIMEOUT_ERROR:
+		statusCode, code = http.StatusServiceUnavailable, "timeout"
+	case manager.UNAVAILABLE_ERROR:
+		statusCode, code = http.StatusServiceUnavailable, "unavailable"
+	case manager.INVALID_ERROR:
+		// the message tells the client what to fix
+		return NewViewSetError(err.Error(), http.StatusBadRequest, err).WithCode("invalid_data")
+	}
+	message := err.Error()
+	if !gin.IsDebugging() {
+		// keep the cause in the logs of the request
+		c.Error(err).SetType(gin.ErrorTypePrivate)
+		message = translate(c, "error.internal")
+		if code != "" {
+			message = translate(c, "error."+code)
+		}
+	}
+	return NewViewSetError(message, statusCode, err).WithCode(code)
+}
+
 func (h *DefaultExceptionHandler) Handle(err error, c *gin.Context) {
+	c.Error(err)
+	validationErrs := ValidationErrors{}
+	if errors.As(err, &validationErrs) {
+		statusCode := h.ValidationStatusCode
+		if statusCode == 0 {
+			statusCode = http.StatusBadRequest
+		}
+		c.AbortWithStatusJSON(statusCode, map[string]any{"errors": validationErrs})
+		return
+	}
 	switch foundedErr := err.(type) {
 	case *ViewSetError:
-		c.AbortWithStatusJSON(
-			foundedErr.StatusCode,
-			map[string]any{
-				"message": foundedErr.Error(),
-			},
-		)
+		c.AbortWithStatusJSON(foundedErr.StatusCode, errorBody(foundedErr))
 	case ViewSetError:
-		c.AbortWithStatusJSON(
-			foundedErr.StatusCode,
-			map[string]any{
-				"message": foundedErr.Error(),
-			},
-		)
+		c.AbortWithStatusJSON(foundedErr.StatusCode, errorBody(foundedErr))
 	default:
 		c.AbortWithStatusJSON(
 			http.StatusBadRequest,
@@ -57,3 +143,13 @@ func (h *DefaultExceptionHandler) Handle(err error, c *gin.Context) {
 		)
 	}
 }
+
+// errorBody is {"message": ...}, with the stack trace of a panic in debug
+// mode.
+func errorBody(err error) map[string]any {
+	body := map[string]any{"message": err.Error()}
+	if stack := panicStack(err); stack != nil {
+		body["stack"] = string(stack)
+	}
+	return body
+}
@@ -2,13 +2,21 @@ package viewset
 
 import (
 	"bytes"
+	"database/sql/driver"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/TcMits/viewset/manager"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
 func TestViewSetNewViewSetError(t *testing.T) {
@@ -61,3 +69,135 @@ func TestDefaultExceptionHandlerHandleWithPointerViewSetError(t *testing.T) {
 	assert.Equal(t, `{"message":"testing"}`, blw.MockBody.String())
 	assert.Equal(t, http.StatusForbidden, blw.MockStatusCode)
 }
+
+func TestAsViewSetError(t *testing.T) {
+	baseErr := errors.New("testing")
+
+	err := asViewSetError(baseErr, http.StatusNotFound)
+	assert.Equal(t, NewViewSetError("testing", http.StatusNotFound, baseErr), err)
+
+	viewSetErr := NewViewSetError("conflict", http.StatusConflict, baseErr)
+	assert.Equal(t, viewSetErr, asViewSetError(fmt.Errorf("validate: %w", viewSetErr), http.StatusBadRequest))
+}
+
+func TestViewSetErrorWithCodeAndType(t *testing.T) {
+	err := NewViewSetError("gone", http.StatusGone, nil).WithCode("archived").WithType("https://example.com/archived")
+
+	assert.Equal(t, "archived", err.Code)
+	assert.Equal(t, "https://example.com/archived", err.Type)
+	assert.Equal(t, "permission_denied", permissionError(ErrPermissionDenied, newTestFieldContext("/")).Code)
+}
+
+func TestClassifiedError(t *testing.T) {
+	c := newTestFieldContext("/members/")
+	gin.SetMode(gin.DebugMode)
+	defer gin.SetMode(gin.TestMode)
+
+	err := classifiedError(fmt.Errorf("get: %w", driver.ErrBadConn), c)
+	assert.Equal(t, http.StatusServiceUnavailable, err.StatusCode)
+	assert.Equal(t, "unavailable", err.Code)
+	assert.EqualError(t, err, "get: driver: bad connection")
+
+	err = classifiedError(errors.New("UNIQUE constraint failed: members.email"), c)
+	assert.Equal(t, http.StatusConflict, err.StatusCode)
+	assert.Equal(t, "conflict", err.Code)
+
+	err = classifiedError(errors.New("no such table: members"), c)
+	assert.Equal(t, http.StatusInternalServerError, err.StatusCode)
+	assert.Equal(t, "", err.Code)
+	assert.EqualError(t, err, "no such table: members")
+
+	err = classifiedError(&manager.NestedWriteError{Path: "items[0]", Err: manager.ErrMissingChild}, c)
+	assert.Equal(t, http.StatusBadRequest, err.StatusCode)
+	assert.Equal(t, "invalid_data", err.Code)
+
+	viewSetErr := NewViewSetError("gone", http.StatusGone, manager.ErrObjectNotFound)
+	assert.Equal(t, viewSetErr, classifiedError(viewSetErr, c))
+}
+
+func TestClassifiedErrorHidesMessagesOutsideDebugMode(t *testing.T) {
+	c := newTestFieldContext("/members/")
+	gin.SetMode(gin.ReleaseMode)
+	defer gin.SetMode(gin.TestMode)
+
+	err := classifiedError(errors.New("no such table: members"), c)
+	assert.Equal(t, http.StatusInternalServerError, err.StatusCode)
+	assert.EqualError(t, err, "internal server error")
+	assert.EqualError(t, errors.Unwrap(err), "no such table: members")
+	assert.Equal(t, "no such table: members", c.Errors.Last().Error())
+
+	err = classifiedError(errors.New("FOREIGN KEY constraint failed"), c)
+	assert.Equal(t, http.StatusUnprocessableEntity, err.StatusCode)
+	assert.EqualError(t, err, "object refers to an object which does not exist or is still referred to")
+
+	err = classifiedError(&manager.NestedWriteError{Path: "items[0]", Err: manager.ErrMissingChild}, c)
+	assert.EqualError(t, err, "items[0]: child is missing")
+
+	gin.SetMode(gin.TestMode)
+	err = classifiedError(errors.New("invalid page"), c)
+	assert.Equal(t, http.StatusInternalServerError, err.StatusCode)
+	assert.EqualError(t, err, "internal server error")
+}
+
+type testMember struct {
+	ID    uint   `mapstructure:"id" viewset:"read_only"`
+	Email string `mapstructure:"email" gorm:"uniqueIndex"`
+}
+
+type testMemberRequest struct {
+	Email string `json:"email" mapstructure:"email"`
+}
+
+type testMemberURI struct {
+	ID uint `uri:"pk"`
+}
+
+func TestViewSetClassifiesManagerErrors(t *testing.T) {
+	db, err := gorm.Open(
+		sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())),
+		&gorm.Config{Logger: logger.Default.LogMode(logger.Silent)},
+	)
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&testMember{}))
+	require.NoError(t, db.Create(&testMember{Email: "a@example.com"}).Error)
+
+	memberManager := manager.NewGormManager[testMember, testMemberRequest, testMemberURI](
+		db.Model(&testMember{}), nil, nil, nil, nil, "db",
+	)
+	viewSet := NewViewSet[testMember, testMemberRequest](
+		"/members", "/:pk", nil, nil, memberManager, nil, nil, nil, nil,
+	)
+	router := SetUpRouter()
+	viewSet.Register(router)
+	serve := func(method string, target string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve(http.MethodPost, "/members/", `{"email":"a@example.com"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = serve(http.MethodGet, "/members/2", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	gin.SetMode(gin.ReleaseMode)
+	defer gin.SetMode(gin.TestMode)
+	w = serve(http.MethodPost, "/members/", `{"email":"a@example.com"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, `{"message":"object conflicts with an existing one"}`, w.Body.String())
+
+	require.NoError(t, db.Migrator().DropTable(&testMember{}))
+	for _, method := range []string{http.MethodGet, http.MethodDelete} {
+		w = serve(method, "/members/1", "")
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, `{"message":"internal server error"}`, w.Body.String())
+	}
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		w = serve(method, "/members/", `{"email":"b@example.com"}`)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, `{"message":"internal server error"}`, w.Body.String())
+	}
+}
@@ -0,0 +1,131 @@
+// Code generated by viewset-gen. DO NOT EDIT.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"unicode/utf8"
+
+	"github.com/TcMits/viewset"
+	"github.com/TcMits/viewset/manager"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+var _ viewset.Serializer[Book] = &BookSerializer{}
+
+// BookSerializer serializes Book like DefaultSerializer, without reflection.
+type BookSerializer struct {
+	viewset.MappedSerializer[Book]
+}
+
+func NewBookSerializer(additionalField map[string]viewset.Field[Book]) *BookSerializer {
+	return &BookSerializer{MappedSerializer: viewset.MappedSerializer[Book]{
+		DefaultSerializer: viewset.DefaultSerializer[Book]{AdditionalField: additionalField},
+		MapInto:           MapBook,
+	}}
+}
+
+// MapBook writes the keys mapstructure decodes from a Book.
+func MapBook(dest map[string]any, value *Book) {
+	dest["id"] = value.ID
+	dest["title"] = value.Title
+	dest["author"] = value.Author
+	if value.Pages != 0 {
+		dest["pages"] = value.Pages
+	}
+	dest["price"] = value.Price
+	dest["secret"] = value.Secret
+	bookMapAudit(dest, &value.Audit)
+	dest["Language"] = value.Language
+}
+
+func bookMapAudit(dest map[string]any, value *Audit) {
+	dest["created_at"] = value.CreatedAt
+	dest["updated_at"] = value.UpdatedAt
+}
+
+var _ viewset.FormValidator[Book, BookRequest] = &BookRequestValidator{}
+
+// BookRequestValidator decodes JSON bodies and checks them with ValidateBookRequest,
+// other bodies are bound by gin.
+type BookRequestValidator struct{}
+
+func (_ *BookRequestValidator) Validate(dest *BookRequest, _ *Book, c *gin.Context) error {
+	if c.ContentType() != binding.MIMEJSON {
+		return c.ShouldBind(dest)
+	}
+	if c.Request == nil || c.Request.Body == nil {
+		return errors.New("invalid request")
+	}
+	if err := json.NewDecoder(c.Request.Body).Decode(dest); err != nil {
+		return err
+	}
+	return ValidateBookRequest(dest)
+}
+
+// ValidateBookRequest checks the binding tags of a BookRequest, the first failing tag
+// of each field is reported like the validator gin uses does.
+func ValidateBookRequest(value *BookRequest) error {
+	failed := viewset.FieldErrors{}
+	if value.Title == "" {
+		failed = append(failed, viewset.FieldError{Namespace: "BookRequest.Title", Field: "Title", Tag: "required"})
+	} else if utf8.RuneCountInString(value.Title) > 100 {
+		failed = append(failed, viewset.FieldError{Namespace: "BookRequest.Title", Field: "Title", Tag: "max", Param: "100"})
+	}
+	if value.Author == "" {
+		failed = append(failed, viewset.FieldError{Namespace: "BookRequest.Author", Field: "Author", Tag: "required"})
+	}
+	if value.Pages != 0 {
+		if value.Pages < 1 {
+			failed = append(failed, viewset.FieldError{Namespace: "BookRequest.Pages", Field: "Pages", Tag: "gte", Param: "1"})
+		}
+	}
+	if value.Price != nil {
+		if *value.Price <= 0 {
+			failed = append(failed, viewset.FieldError{Namespace: "BookRequest.Price", Field: "Price", Tag: "gt", Param: "0"})
+		}
+	}
+	if value.Language != "" {
+		if value.Language != "en" && value.Language != "fr" && value.Language != "vi" {
+			failed = append(failed, viewset.FieldError{Namespace: "BookRequest.Language", Field: "Language", Tag: "oneof", Param: "en fr vi"})
+		}
+	}
+	if len(failed) > 0 {
+		return failed
+	}
+	return nil
+}
+
+// BookRequestToBook assigns the writable fields of BookRequest to Book like the default
+// create functions of the managers, leaving out the fields the rules and
+// hidden fields of the request exclude.
+func BookRequestToBook(validatedData *BookRequest, entity *Book, c *gin.Context) {
+	writable := map[string]any{
+		"title":    nil,
+		"author":   nil,
+		"pages":    nil,
+		"price":    nil,
+		"language": nil,
+	}
+	manager.FilterWritable[Book](writable, c)
+	if _, ok := writable["title"]; ok {
+		entity.Title = validatedData.Title
+	}
+	if _, ok := writable["author"]; ok {
+		entity.Author = validatedData.Author
+	}
+	if _, ok := writable["pages"]; ok {
+		entity.Pages = validatedData.Pages
+	}
+	if _, ok := writable["price"]; ok {
+		if validatedData.Price != nil {
+			copied := *validatedData.Price
+			entity.Price = &copied
+		}
+	}
+	if _, ok := writable["language"]; ok {
+		entity.Language = validatedData.Language
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"time"
+
+	"github.com/TcMits/viewset"
+	"github.com/TcMits/viewset/manager"
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+//go:generate go run github.com/TcMits/viewset/cmd/viewset-gen -type Book -validate BookRequest
+
+type Audit struct {
+	CreatedAt time.Time `mapstructure:"created_at" viewset:"read_only"`
+	UpdatedAt time.Time `mapstructure:"updated_at" viewset:"read_only"`
+}
+
+type Book struct {
+	ID       uint     `mapstructure:"id" gorm:"primary_key" viewset:"read_only"`
+	Title    string   `mapstructure:"title"`
+	Author   string   `mapstructure:"author"`
+	Pages    int      `mapstructure:"pages,omitempty"`
+	Price    *float64 `mapstructure:"price"`
+	Secret   string   `mapstructure:"secret" viewset:"hidden"`
+	Audit    `mapstructure:",squash"`
+	Language string
+}
+
+type BookRequest struct {
+	Title    string   `mapstructure:"title" json:"title" form:"title" binding:"required,max=100"`
+	Author   string   `mapstructure:"author" json:"author" form:"author" binding:"required"`
+	Pages    int      `mapstructure:"pages" json:"pages" form:"pages" binding:"omitempty,gte=1"`
+	Price    *float64 `mapstructure:"price" json:"price" form:"price" binding:"omitempty,gt=0"`
+	Secret   string   `mapstructure:"secret" json:"secret" form:"secret"`
+	Language string   `mapstructure:"language" json:"language" form:"language" binding:"omitempty,oneof=en fr vi"`
+}
+
+type BookURI struct {
+	ID uint `mapstructure:"id" uri:"pk" binding:"required"`
+}
+
+func createBook(dest **Book, validatedData *BookRequest, db *gorm.DB, c *gin.Context) error {
+	*dest = new(Book)
+	BookRequestToBook(validatedData, *dest, c)
+	return db.Create(*dest).Error
+}
+
+func main() {
+	r := gin.Default()
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("Failed to connect to database!")
+	}
+	db.AutoMigrate(&Book{})
+
+	bookManager := manager.NewGormManager[Book, BookRequest, BookURI](
+		db.Model(&Book{}), nil, createBook, nil, nil, "db",
+	)
+	bookViewSet := viewset.NewViewSet[Book, BookRequest](
+		"/books", "/:pk", nil, nil, bookManager, nil, nil, NewBookSerializer(nil), &BookRequestValidator{},
+	)
+	bookViewSet.Register(r)
+	r.Run() // listen and serve on 0.0.0.0:8080 (for windows "localhost:8080")
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TcMits/viewset"
+	"github.com/TcMits/viewset/manager"
+	"github.com/TcMits/viewset/pkg/fieldrule"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/mitchellh/mapstructure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testContext(body string) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest(http.MethodPost, "/books/", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", binding.MIMEJSON)
+	return c
+}
+
+func TestBookSerializerMatchesDefaultSerializer(t *testing.T) {
+	price := 9.5
+	books := []*Book{
+		{ID: 1, Title: "Dune", Author: "Herbert", Pages: 412, Price: &price, Secret: "s", Language: "en"},
+		{ID: 2, Title: "Untitled", Audit: Audit{CreatedAt: time.Now()}},
+	}
+	c := testContext("")
+
+	expected := []map[string]any{}
+	require.NoError(t, (&viewset.DefaultSerializer[Book]{}).ManySerialize(&expected, &books, c))
+	actual := []map[string]any{}
+	require.NoError(t, NewBookSerializer(nil).ManySerialize(&actual, &books, c))
+	for i, book := range books {
+		// mapstructure turns time.Time into an empty map, the mapper keeps it
+		assert.Equal(t, map[string]any{}, expected[i]["created_at"])
+		expected[i]["created_at"] = book.CreatedAt
+		expected[i]["updated_at"] = book.UpdatedAt
+	}
+
+	assert.Equal(t, expected, actual)
+	assert.NotContains(t, actual[0], "secret")
+	assert.NotContains(t, actual[1], "pages")
+}
+
+func TestBookSerializerRendersTimestamps(t *testing.T) {
+	createdAt := time.Date(2022, 7, 13, 10, 26, 35, 0, time.UTC)
+	book := &Book{ID: 1, Title: "Dune", Audit: Audit{CreatedAt: createdAt}}
+	actual := map[string]any{}
+	require.NoError(t, NewBookSerializer(nil).Serialize(&actual, book, testContext("")))
+
+	assert.Equal(t, createdAt, actual["created_at"])
+	rendered, err := json.Marshal(actual)
+	require.NoError(t, err)
+	assert.Contains(t, string(rendered), `"created_at":"2022-07-13T10:26:35Z"`)
+	assert.Contains(t, string(rendered), `"updated_at":"0001-01-01T00:00:00Z"`)
+}
+
+func TestValidateBookRequestMatchesValidator(t *testing.T) {
+	zero, price := 0.0, 3.0
+	requests := []BookRequest{
+		{},
+		{Title: "Dune", Author: "Herbert"},
+		{Title: strings.Repeat("é", 101), Author: "Herbert", Pages: -1},
+		{Title: strings.Repeat("é", 100), Author: "Herbert", Price: &zero, Language: "de"},
+		{Title: "Dune", Author: "Herbert", Pages: 1, Price: &price, Language: "vi"},
+	}
+	for _, request := range requests {
+		expected := binding.Validator.ValidateStruct(&request)
+		actual := ValidateBookRequest(&request)
+		if expected == nil {
+			assert.NoError(t, actual)
+			continue
+		}
+		require.Error(t, actual)
+		assert.Equal(t, expected.Error(), actual.Error())
+	}
+}
+
+func TestBookRequestValidatorValidate(t *testing.T) {
+	dest := BookRequest{}
+	err := (&BookRequestValidator{}).Validate(&dest, nil, testContext(`{"title": "Dune", "author": "Herbert"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "Dune", dest.Title)
+
+	err = (&BookRequestValidator{}).Validate(&BookRequest{}, nil, testContext(`{"title": "Dune"}`))
+	assert.Equal(t, viewset.FieldErrors{{Namespace: "BookRequest.Author", Field: "Author", Tag: "required"}}, err)
+
+	err = (&BookRequestValidator{}).Validate(&BookRequest{}, nil, testContext(`{"title": 1}`))
+	var typeError *json.UnmarshalTypeError
+	assert.ErrorAs(t, err, &typeError)
+}
+
+func TestBookRequestToBookMatchesDecode(t *testing.T) {
+	price := 3.0
+	requests := []BookRequest{
+		{Title: "Dune", Author: "Herbert", Pages: 412, Price: &price, Secret: "s", Language: "en"},
+		{Title: "Untitled"},
+	}
+	restricted := testContext("")
+	manager.SetFieldRules(restricted, fieldrule.Rules{"pages": {ReadOnly: true}})
+	manager.SetHiddenFields(restricted, "author")
+	for _, c := range []*gin.Context{nil, testContext(""), restricted} {
+		for _, request := range requests {
+			values := map[string]any{}
+			require.NoError(t, mapstructure.Decode(&request, &values))
+			manager.FilterWritable[Book](values, c)
+			expected := Book{ID: 7}
+			require.NoError(t, mapstructure.Decode(values, &expected))
+
+			actual := Book{ID: 7}
+			BookRequestToBook(&request, &actual, c)
+			assert.Equal(t, expected, actual)
+		}
+	}
+	actual := Book{}
+	BookRequestToBook(&requests[0], &actual, restricted)
+	assert.Equal(t, Book{Title: "Dune", Price: &price, Language: "en"}, actual)
+}
@@ -0,0 +1,206 @@
+package viewset
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+
+	"github.com/TcMits/viewset/manager"
+	"github.com/TcMits/viewset/pkg/fieldset"
+	"github.com/gin-gonic/gin"
+)
+
+const EXPAND_PARAM = "expand"
+
+var ErrUnknownExpansion = errors.New("unknown expansion")
+
+// treeSerializer serializes an entity with an explicit selection, nested
+// expansions use it to pass their part of the request down.
+type treeSerializer[EntityType any] interface {
+	serialize(dest *map[string]any, entity *EntityType, fields fieldset.Set, expand fieldset.Tree, c *gin.Context) error
+}
+
+// Expansion nests the object(s) held by the Source field of an entity in
+// place of its foreign key, GormManager preloads Source when it is requested.
+type Expansion struct {
+	Source string
+	// field of the entity holding the related object(s), also the GORM association
+	ForeignKey string
+	// optional, serialized key of the related id which the object replaces
+	PermissionChecker PermissionChecker
+	// optional, checked before the related objects are expanded
+	Action string
+	// optional, action given to PermissionChecker, defaults to retrieve
+
+	serialize  func(value reflect.Value, fields fieldset.Set, expand fieldset.Tree, c *gin.Context) (any, error)
+	expansions func() map[string]Expansion
+}
+
+// NewExpansion serializes the related objects with serializer, which may
+// itself declare expansions for dotted paths like publisher.country.
+func NewExpansion[RelatedType any](
+	source string, foreignKey string, serializer SingleSerializer[RelatedType],
+) Expansion {
+	if serializer == nil {
+		panic("serializer is required")
+	}
+	expansion := Expansion{Source: source, ForeignKey: foreignKey}
+	expansion.serialize = func(
+		value reflect.Value, fields fieldset.Set, expand fieldset.Tree, c *gin.Context,
+	) (any, error) {
+		serializeOne := func(value reflect.Value) (any, error) {
+			related, ok := relatedPointer[RelatedType](value)
+			if !ok {
+				return nil, fmt.Errorf("cannot expand %s from %s", source, value.Type())
+			}
+			if related == nil {
+				return nil, nil
+			}
+			dest := map[string]any{}
+			if tree, ok := serializer.(treeSerializer[RelatedType]); ok {
+				if err := tree.serialize(&dest, related, fields, expand, c); err != nil {
+					return nil, err
+				}
+				return dest, nil
+			}
+			if err := serializer.Serialize(&dest, related, c); err != nil {
+				return nil, err
+			}
+			fields.Apply(dest)
+			return dest, nil
+		}
+		if value.Kind() != reflect.Slice {
+			return serializeOne(value)
+		}
+		objects := make([]any, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			object, err := serializeOne(value.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, object)
+		}
+		return objects, nil
+	}
+	expansion.expansions = func() map[string]Expansion {
+		if expander, ok := serializer.(Expander); ok {
+			return expander.Expansions()
+		}
+		return nil
+	}
+	return expansion
+}
+
+// relatedPointer returns nil for a nil pointer, which stands for a missing
+// relation.
+func relatedPointer[RelatedType any](value reflect.Value) (*RelatedType, bool) {
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil, true
+		}
+		related, ok := value.Interface().(*RelatedType)
+		return related, ok
+	}
+	if value.CanAddr() {
+		related, ok := value.Addr().Interface().(*RelatedType)
+		return related, ok
+	}
+	related, ok := value.Interface().(RelatedType)
+	return &related, ok
+}
+
+func (expansion Expansion) action() string {
+	if expansion.Action == "" {
+		return DEFAULT_RETRIEVE_ACTION
+	}
+	return expansion.Action
+}
+
+// expand serializes the related object(s) of the entity, fields is the
+// selection under the expand name.
+func (expansion Expansion) expand(
+	entity any, fields fieldset.Set, expand fieldset.Tree, c *gin.Context,
+) (any, error) {
+	source := reflect.Indirect(reflect.ValueOf(entity)).FieldByName(expansion.Source)
+	if !source.IsValid() {
+		return nil, fmt.Errorf("unknown field %q", expansion.Source)
+	}
+	if expansion.serialize == nil {
+		return nil, fmt.Errorf("expansion of %s has no serializer, use NewExpansion", expansion.Source)
+	}
+	return expansion.serialize(source, fields, expand, c)
+}
+
+// requestExpand returns the dotted paths asked by the expand query param.
+func requestExpand(c *gin.Context) fieldset.Tree {
+	if c.Request == nil || c.Request.URL == nil {
+		return nil
+	}
+	return fieldset.ParseTree(c.Request.URL.Query()[EXPAND_PARAM])
+}
+
+func unknownExpansion(path string, c *gin.Context) *ViewSetError {
+	return NewLocalizedError(c, "unknown_expansion", http.StatusBadRequest, ErrUnknownExpansion, path)
+}
+
+// resolveExpansions checks the requested expansions against the declared
+// ones and their permissions, and returns the associations to preload.
+func resolveExpansions(
+	expansions map[string]Expansion, expand fieldset.Tree, path string, association string, c *gin.Context,
+) ([]string, error) {
+	names := make([]string, 0, len(expand))
+	for name := range expand {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	associations := make([]string, 0, len(names))
+	for _, name := range names {
+		expansion, ok := expansions[name]
+		if !ok {
+			return nil, unknownExpansion(path+name, c)
+		}
+		if expansion.PermissionChecker != nil {
+			if err := expansion.PermissionChecker.Check(expansion.action(), c); err != nil {
+				return nil, permissionError(err, c)
+			}
+		}
+		associations = append(associations, association+expansion.Source)
+		if expand[name] == nil {
+			continue
+		}
+		var nested map[string]Expansion
+		if expansion.expansions != nil {
+			nested = expansion.expansions()
+		}
+		nestedAssociations, err := resolveExpansions(
+			nested, expand[name], path+name+".", association+expansion.Source+".", c,
+		)
+		if err != nil {
+			return nil, err
+		}
+		associations = append(associations, nestedAssociations...)
+	}
+	return associations, nil
+}
+
+// prepareExpansions validates the expand query param before the manager is
+// called and asks it to preload the expanded associations.
+func (viewSet *ViewSet[_, _]) prepareExpansions(c *gin.Context) error {
+	expand := requestExpand(c)
+	if expand == nil {
+		return nil
+	}
+	var expansions map[string]Expansion
+	if expander, ok := viewSet.Serializer.(Expander); ok {
+		expansions = expander.Expansions()
+	}
+	associations, err := resolveExpansions(expansions, expand, "", "", c)
+	if err != nil {
+		return err
+	}
+	manager.SetPreloads(c, associations...)
+	return nil
+}
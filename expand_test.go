@@ -0,0 +1,200 @@
+package viewset
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TcMits/viewset/manager"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type testCountry struct {
+	ID   uint   `gorm:"primarykey" mapstructure:"id"`
+	Name string `mapstructure:"name"`
+}
+
+type testPublisher struct {
+	ID        uint         `gorm:"primarykey" mapstructure:"id"`
+	Name      string       `mapstructure:"name"`
+	CountryID uint         `mapstructure:"country_id"`
+	Country   *testCountry `mapstructure:"-"`
+}
+
+type testWriter struct {
+	ID   uint   `gorm:"primarykey" mapstructure:"id"`
+	Name string `mapstructure:"name"`
+}
+
+type testNovel struct {
+	ID          uint           `gorm:"primarykey" mapstructure:"id"`
+	Title       string         `mapstructure:"title"`
+	AuthorID    uint           `mapstructure:"author_id"`
+	Author      *testWriter    `mapstructure:"-"`
+	PublisherID uint           `mapstructure:"publisher_id"`
+	Publisher   *testPublisher `mapstructure:"-"`
+}
+
+type testNovelRequest struct {
+	Title string `json:"title" mapstructure:"title" binding:"required"`
+}
+
+type testNovelURI struct {
+	ID uint `uri:"pk" binding:"required"`
+}
+
+type testHeaderPermission struct{}
+
+func (_ *testHeaderPermission) Check(_ string, c *gin.Context) error {
+	if c.GetHeader("X-Staff") == "" {
+		return errors.New("staff only")
+	}
+	return nil
+}
+
+func newTestNovelRouter(t *testing.T) (*gin.Engine, *int) {
+	db, err := gorm.Open(
+		sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())),
+		&gorm.Config{Logger: logger.Default.LogMode(logger.Silent)},
+	)
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&testCountry{}, &testPublisher{}, &testWriter{}, &testNovel{}))
+	require.NoError(t, db.Create(&testNovel{
+		Title:     "first",
+		Author:    &testWriter{Name: "phuc"},
+		Publisher: &testPublisher{Name: "kim dong", Country: &testCountry{Name: "vietnam"}},
+	}).Error)
+	require.NoError(t, db.Create(&testNovel{
+		Title:     "second",
+		Author:    &testWriter{Name: "huy"},
+		Publisher: &testPublisher{Name: "tre", Country: &testCountry{Name: "vietnam"}},
+	}).Error)
+
+	queries := new(int)
+	require.NoError(t, db.Callback().Query().Before("gorm:query").Register("count", func(*gorm.DB) {
+		*queries++
+	}))
+
+	publisherSerializer := &DefaultSerializer[testPublisher]{
+		Expandable: map[string]Expansion{
+			"country": NewExpansion[testCountry]("Country", "country_id", &DefaultSerializer[testCountry]{}),
+		},
+	}
+	authorExpansion := NewExpansion[testWriter]("Author", "author_id", &DefaultSerializer[testWriter]{})
+	authorExpansion.PermissionChecker = &testHeaderPermission{}
+	bookSerializer := &DefaultSerializer[testNovel]{
+		Expandable: map[string]Expansion{
+			"author":    authorExpansion,
+			"publisher": NewExpansion[testPublisher]("Publisher", "publisher_id", publisherSerializer),
+		},
+	}
+
+	bookManager := manager.NewGormManager[testNovel, testNovelRequest, testNovelURI](
+		db.Model(&testNovel{}), nil, nil, nil, nil, "db",
+	)
+	viewSet := NewViewSet[testNovel, testNovelRequest](
+		"/books", "/:pk", nil, nil, bookManager, nil, nil, bookSerializer, nil,
+	)
+	router := SetUpRouter()
+	viewSet.Register(router)
+	return router, queries
+}
+
+func serveTestNovel(router *gin.Engine, target string, staff bool) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if staff {
+		req.Header.Set("X-Staff", "1")
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestExpandRetrieve(t *testing.T) {
+	router, _ := newTestNovelRouter(t)
+
+	w := serveTestNovel(router, "/books/1", false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id":1,"title":"first","author_id":1,"publisher_id":1}`, w.Body.String())
+
+	w = serveTestNovel(router, "/books/1?expand=author,publisher.country", true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"id":1,"title":"first",
+		"author":{"id":1,"name":"phuc"},
+		"publisher":{"id":1,"name":"kim dong","country":{"id":1,"name":"vietnam"}}
+	}`, w.Body.String())
+
+	w = serveTestNovel(router, "/books/1?expand=publisher&fields=title,publisher.name", false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"title":"first","publisher":{"name":"kim dong"}}`, w.Body.String())
+}
+
+func TestExpandListPreloads(t *testing.T) {
+	router, queries := newTestNovelRouter(t)
+
+	*queries = 0
+	w := serveTestNovel(router, "/books/?expand=author,publisher.country", true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	// books, authors, publishers and countries whatever the number of books
+	assert.Equal(t, 4, *queries)
+
+	var body struct {
+		Results []map[string]any `json:"results"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Len(t, body.Results, 2)
+	assert.Equal(t, map[string]any{"id": float64(2), "name": "huy"}, body.Results[1]["author"])
+	assert.Equal(t, "tre", body.Results[1]["publisher"].(map[string]any)["name"])
+}
+
+func TestExpandRejected(t *testing.T) {
+	router, _ := newTestNovelRouter(t)
+
+	w := serveTestNovel(router, "/books/?expand=editor", true)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"message":"unknown expansion: editor"}`, w.Body.String())
+
+	w = serveTestNovel(router, "/books/1?expand=publisher.owner", true)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"message":"unknown expansion: publisher.owner"}`, w.Body.String())
+
+	w = serveTestNovel(router, "/books/1?expand=author.country", true)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = serveTestNovel(router, "/books/1?expand=author", false)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.JSONEq(t, `{"message":"staff only"}`, w.Body.String())
+}
+
+func TestExpansionWithoutSerializerPanics(t *testing.T) {
+	assert.Panics(t, func() {
+		NewExpansion[testWriter]("Author", "author_id", nil)
+	})
+}
+
+func TestExpandSliceSource(t *testing.T) {
+	type shelf struct {
+		Books []testWriter `mapstructure:"-"`
+	}
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/?expand=books", nil)
+
+	serializer := &DefaultSerializer[shelf]{Expandable: map[string]Expansion{
+		"books": NewExpansion[testWriter]("Books", "", &DefaultSerializer[testWriter]{}),
+	}}
+	dest := map[string]any{}
+	require.NoError(t, serializer.Serialize(&dest, &shelf{Books: []testWriter{{ID: 1, Name: "phuc"}}}, c))
+	assert.Equal(t, map[string]any{
+		"books": []any{map[string]any{"id": uint(1), "name": "phuc"}},
+	}, dest)
+}
@@ -0,0 +1,250 @@
+package viewset
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/TcMits/viewset/pkg/fieldset"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	TIMEZONE_HEADER      = "Time-Zone"
+	TIMEZONE_CONTEXT_KEY = "github.com/TcMits/viewset.timezone"
+)
+
+var ErrUnknownTimezone = errors.New("unknown timezone")
+
+var _ Field[any] = &MethodField[any]{}
+var _ Field[any] = &DateTimeField[any]{}
+var _ Field[any] = &ChoiceField[any, int]{}
+var _ Field[any] = &PrimaryKeyRelatedField[any]{}
+var _ Field[any] = &RelatedField[any, any]{}
+var _ Field[any] = &GormCountField[any]{}
+var _ Field[any] = &ConstantField[any]{}
+var _ Field[any] = &ContextField[any]{}
+
+// sourceField returns the field of the entity named source.
+func sourceField(entity any, source string) (reflect.Value, error) {
+	value := reflect.Indirect(reflect.ValueOf(entity)).FieldByName(source)
+	if !value.IsValid() {
+		return reflect.Value{}, fmt.Errorf("unknown field %q", source)
+	}
+	return value, nil
+}
+
+// MethodField serializes what Method returns.
+type MethodField[EntityType any] struct {
+	Method func(*EntityType, *gin.Context) (any, error)
+}
+
+func (f *MethodField[EntityType]) Serialize(entity *EntityType, c *gin.Context) (any, error) {
+	return f.Method(entity, c)
+}
+
+// SetTimezone makes DateTimeField format times in loc for the rest of the
+// request, e.g. from the profile of the current user.
+func SetTimezone(c *gin.Context, loc *time.Location) {
+	c.Set(TIMEZONE_CONTEXT_KEY, loc)
+}
+
+// Timezone returns the location set by SetTimezone, else the one named by
+// the Time-Zone header, nil if there is neither. The header is resolved once
+// per request, later calls reuse the location kept in the context.
+func Timezone(c *gin.Context) (*time.Location, error) {
+	if loc, ok := c.Value(TIMEZONE_CONTEXT_KEY).(*time.Location); ok {
+		return loc, nil
+	}
+	name := c.GetHeader(TIMEZONE_HEADER)
+	if name == "" {
+		return nil, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, NewLocalizedError(c, "unknown_timezone", http.StatusBadRequest, ErrUnknownTimezone, name)
+	}
+	SetTimezone(c, loc)
+	return loc, nil
+}
+
+// DateTimeField formats the time.Time or *time.Time Source field in the
+// timezone of the request, a zero or nil time is serialized as nil.
+type DateTimeField[EntityType any] struct {
+	Source string
+	Layout string
+	// optional, defaults to time.RFC3339
+	Location *time.Location
+	// optional, used when the request has no timezone, defaults to the location of the time
+}
+
+func (f *DateTimeField[EntityType]) Serialize(entity *EntityType, c *gin.Context) (any, error) {
+	source, err := sourceField(entity, f.Source)
+	if err != nil {
+		return nil, err
+	}
+	if source.Kind() == reflect.Pointer {
+		if source.IsNil() {
+			return nil, nil
+		}
+		source = source.Elem()
+	}
+	value, ok := source.Interface().(time.Time)
+	if !ok {
+		return nil, fmt.Errorf("field %q is not a time", f.Source)
+	}
+	if value.IsZero() {
+		return nil, nil
+	}
+	loc, err := Timezone(c)
+	if err != nil {
+		return nil, err
+	}
+	if loc == nil {
+		loc = f.Location
+	}
+	if loc != nil {
+		value = value.In(loc)
+	}
+	layout := f.Layout
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	return value.Format(layout), nil
+}
+
+// ChoiceField serializes the display name of the Source field, values
+// missing from Choices are serialized as they are.
+type ChoiceField[EntityType any, ValueType comparable] struct {
+	Source  string
+	Choices map[ValueType]string
+}
+
+func (f *ChoiceField[EntityType, ValueType]) Serialize(entity *EntityType, c *gin.Context) (any, error) {
+	source, err := sourceField(entity, f.Source)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := source.Interface().(ValueType)
+	if !ok {
+		return nil, fmt.Errorf("field %q is not a %s", f.Source, reflect.TypeOf(new(ValueType)).Elem())
+	}
+	if display, ok := f.Choices[value]; ok {
+		return display, nil
+	}
+	return value, nil
+}
+
+// PrimaryKeyRelatedField serializes the primary key of the object held by
+// the Source field, or the keys of the objects if Source is a slice. A nil
+// object is serialized as nil.
+type PrimaryKeyRelatedField[EntityType any] struct {
+	Source string
+	Key    string
+	// optional, field of the related object holding its key, defaults to ID
+}
+
+func (f *PrimaryKeyRelatedField[EntityType]) Serialize(entity *EntityType, c *gin.Context) (any, error) {
+	source, err := sourceField(entity, f.Source)
+	if err != nil {
+		return nil, err
+	}
+	if source.Kind() != reflect.Slice {
+		return f.key(source)
+	}
+	keys := make([]any, 0, source.Len())
+	for i := 0; i < source.Len(); i++ {
+		key, err := f.key(source.Index(i))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (f *PrimaryKeyRelatedField[EntityType]) key(related reflect.Value) (any, error) {
+	if related.Kind() == reflect.Pointer {
+		if related.IsNil() {
+			return nil, nil
+		}
+		related = related.Elem()
+	}
+	name := f.Key
+	if name == "" {
+		name = "ID"
+	}
+	if related.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot read field %q of %s", name, related.Type())
+	}
+	key := related.FieldByName(name)
+	if !key.IsValid() {
+		return nil, fmt.Errorf("unknown field %q", name)
+	}
+	return key.Interface(), nil
+}
+
+// RelatedField serializes the object(s) held by the Source field with
+// Serializer, unlike an Expansion it is always nested.
+type RelatedField[EntityType any, RelatedType any] struct {
+	Source     string
+	Serializer SingleSerializer[RelatedType]
+}
+
+func (f *RelatedField[EntityType, RelatedType]) Serialize(entity *EntityType, c *gin.Context) (any, error) {
+	return NewExpansion(f.Source, "", f.Serializer).expand(entity, fieldset.Set{}, nil, c)
+}
+
+// GormCountField serializes the number of objects of a GORM association,
+// with one query per entity. The DB stored under ContextKey by GormManager
+// is used if there is one, so the count sees the current transaction.
+type GormCountField[EntityType any] struct {
+	DB          *gorm.DB
+	Association string
+	ContextKey  string
+	// optional, gin context key of the request DB
+}
+
+func (f *GormCountField[EntityType]) Serialize(entity *EntityType, c *gin.Context) (any, error) {
+	db := f.DB
+	if f.ContextKey != "" {
+		if requestDB, ok := c.Value(f.ContextKey).(*gorm.DB); ok && requestDB != nil {
+			db = requestDB
+		}
+	}
+	if db == nil {
+		return nil, errors.New("db is required")
+	}
+	association := db.Session(&gorm.Session{NewDB: true}).WithContext(c).Model(entity).Association(f.Association)
+	if association.Error != nil {
+		return nil, association.Error
+	}
+	return association.Count(), association.Error
+}
+
+// ConstantField serializes Value for every entity.
+type ConstantField[EntityType any] struct {
+	Value any
+}
+
+func (f *ConstantField[EntityType]) Serialize(_ *EntityType, _ *gin.Context) (any, error) {
+	return f.Value, nil
+}
+
+// ContextField serializes the value stored under Key in the gin context,
+// e.g. the current user set by an authentication middleware.
+type ContextField[EntityType any] struct {
+	Key     string
+	Default any
+	// optional, serialized when nothing is stored under Key
+}
+
+func (f *ContextField[EntityType]) Serialize(_ *EntityType, c *gin.Context) (any, error) {
+	if value, ok := c.Get(f.Key); ok {
+		return value, nil
+	}
+	return f.Default, nil
+}
@@ -0,0 +1,215 @@
+package viewset
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type testShelfBook struct {
+	ID      uint   `gorm:"primarykey" mapstructure:"id"`
+	ShelfID uint   `mapstructure:"shelf_id"`
+	Title   string `mapstructure:"title"`
+}
+
+type testShelf struct {
+	ID        uint            `gorm:"primarykey" mapstructure:"id"`
+	Status    int             `mapstructure:"status"`
+	CreatedAt time.Time       `mapstructure:"-"`
+	ClosedAt  *time.Time      `mapstructure:"-"`
+	Books     []testShelfBook `gorm:"foreignKey:ShelfID" mapstructure:"-"`
+	Favorite  *testShelfBook  `gorm:"-" mapstructure:"-"`
+}
+
+func newTestFieldContext(target string) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+	return c
+}
+
+func TestMethodField(t *testing.T) {
+	c := newTestFieldContext("/shelves/")
+	field := &MethodField[testShelf]{Method: func(shelf *testShelf, _ *gin.Context) (any, error) {
+		return fmt.Sprintf("shelf %d", shelf.ID), nil
+	}}
+
+	value, err := field.Serialize(&testShelf{ID: 3}, c)
+	assert.NoError(t, err)
+	assert.Equal(t, "shelf 3", value)
+}
+
+func TestDateTimeField(t *testing.T) {
+	created := time.Date(2022, 12, 1, 10, 0, 0, 0, time.UTC)
+	shelf := &testShelf{CreatedAt: created}
+	field := &DateTimeField[testShelf]{Source: "CreatedAt"}
+
+	value, err := field.Serialize(shelf, newTestFieldContext("/shelves/"))
+	assert.NoError(t, err)
+	assert.Equal(t, "2022-12-01T10:00:00Z", value)
+
+	c := newTestFieldContext("/shelves/")
+	c.Request.Header.Set(TIMEZONE_HEADER, "Asia/Ho_Chi_Minh")
+	value, err = field.Serialize(shelf, c)
+	assert.NoError(t, err)
+	assert.Equal(t, "2022-12-01T17:00:00+07:00", value)
+	loc, ok := c.Value(TIMEZONE_CONTEXT_KEY).(*time.Location)
+	require.True(t, ok)
+	assert.Equal(t, "Asia/Ho_Chi_Minh", loc.String())
+
+	c = newTestFieldContext("/shelves/")
+	SetTimezone(c, time.FixedZone("", -3600))
+	value, err = (&DateTimeField[testShelf]{Source: "CreatedAt", Layout: "2006-01-02 15:04"}).Serialize(shelf, c)
+	assert.NoError(t, err)
+	assert.Equal(t, "2022-12-01 09:00", value)
+
+	value, err = (&DateTimeField[testShelf]{Source: "ClosedAt"}).Serialize(shelf, c)
+	assert.NoError(t, err)
+	assert.Nil(t, value)
+
+	c = newTestFieldContext("/shelves/")
+	c.Request.Header.Set(TIMEZONE_HEADER, "Nowhere/City")
+	_, err = field.Serialize(shelf, c)
+	viewSetErr := new(ViewSetError)
+	require.True(t, errors.As(err, &viewSetErr))
+	assert.Equal(t, http.StatusBadRequest, viewSetErr.StatusCode)
+	assert.ErrorIs(t, err, ErrUnknownTimezone)
+}
+
+func TestChoiceField(t *testing.T) {
+	c := newTestFieldContext("/shelves/")
+	field := &ChoiceField[testShelf, int]{Source: "Status", Choices: map[int]string{0: "open", 1: "closed"}}
+
+	value, err := field.Serialize(&testShelf{Status: 1}, c)
+	assert.NoError(t, err)
+	assert.Equal(t, "closed", value)
+
+	value, err = field.Serialize(&testShelf{Status: 5}, c)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, value)
+
+	_, err = (&ChoiceField[testShelf, string]{Source: "Status"}).Serialize(&testShelf{}, c)
+	assert.Error(t, err)
+}
+
+func TestPrimaryKeyRelatedField(t *testing.T) {
+	c := newTestFieldContext("/shelves/")
+	shelf := &testShelf{Books: []testShelfBook{{ID: 1}, {ID: 2}}}
+
+	value, err := (&PrimaryKeyRelatedField[testShelf]{Source: "Books"}).Serialize(shelf, c)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{uint(1), uint(2)}, value)
+
+	favorite := &PrimaryKeyRelatedField[testShelf]{Source: "Favorite"}
+	value, err = favorite.Serialize(shelf, c)
+	assert.NoError(t, err)
+	assert.Nil(t, value)
+
+	shelf.Favorite = &testShelfBook{ID: 2, Title: "dune"}
+	value, err = favorite.Serialize(shelf, c)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), value)
+
+	value, err = (&PrimaryKeyRelatedField[testShelf]{Source: "Favorite", Key: "Title"}).Serialize(shelf, c)
+	assert.NoError(t, err)
+	assert.Equal(t, "dune", value)
+
+	_, err = (&PrimaryKeyRelatedField[testShelf]{Source: "Unknown"}).Serialize(shelf, c)
+	assert.Error(t, err)
+}
+
+func TestRelatedField(t *testing.T) {
+	c := newTestFieldContext("/shelves/")
+	shelf := &testShelf{Books: []testShelfBook{{ID: 1, ShelfID: 3, Title: "dune"}}}
+	field := &RelatedField[testShelf, testShelfBook]{Source: "Books", Serializer: &DefaultSerializer[testShelfBook]{}}
+
+	value, err := field.Serialize(shelf, c)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{map[string]any{"id": uint(1), "shelf_id": uint(3), "title": "dune"}}, value)
+
+	value, err = (&RelatedField[testShelf, testShelfBook]{
+		Source: "Favorite", Serializer: &DefaultSerializer[testShelfBook]{},
+	}).Serialize(shelf, c)
+	assert.NoError(t, err)
+	assert.Nil(t, value)
+}
+
+func TestGormCountField(t *testing.T) {
+	db, err := gorm.Open(
+		sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())),
+		&gorm.Config{Logger: logger.Default.LogMode(logger.Silent)},
+	)
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&testShelf{}, &testShelfBook{}))
+	shelf := &testShelf{Books: []testShelfBook{{Title: "dune"}, {Title: "emma"}}}
+	require.NoError(t, db.Create(shelf).Error)
+
+	c := newTestFieldContext("/shelves/")
+	field := &GormCountField[testShelf]{DB: db, Association: "Books", ContextKey: "db"}
+	value, err := field.Serialize(shelf, c)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), value)
+
+	assert.EqualError(t, db.Transaction(func(tx *gorm.DB) error {
+		require.NoError(t, tx.Create(&testShelfBook{ShelfID: shelf.ID, Title: "ulysses"}).Error)
+		c.Set("db", tx)
+		value, err = field.Serialize(shelf, c)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), value)
+		return errors.New("rollback")
+	}), "rollback")
+	value, err = field.Serialize(shelf, newTestFieldContext("/shelves/"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), value)
+
+	_, err = (&GormCountField[testShelf]{DB: db, Association: "Unknown"}).Serialize(shelf, newTestFieldContext("/"))
+	assert.Error(t, err)
+}
+
+func TestConstantAndContextFields(t *testing.T) {
+	c := newTestFieldContext("/shelves/")
+
+	value, err := (&ConstantField[testShelf]{Value: "v1"}).Serialize(&testShelf{}, c)
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", value)
+
+	field := &ContextField[testShelf]{Key: "user", Default: "anonymous"}
+	value, err = field.Serialize(&testShelf{}, c)
+	assert.NoError(t, err)
+	assert.Equal(t, "anonymous", value)
+
+	c.Set("user", "phuc")
+	value, err = field.Serialize(&testShelf{}, c)
+	assert.NoError(t, err)
+	assert.Equal(t, "phuc", value)
+}
+
+func TestFieldsInDefaultSerializer(t *testing.T) {
+	c := newTestFieldContext("/shelves/?omit=status")
+	c.Request.Header.Set(TIMEZONE_HEADER, "UTC")
+	serializer := &DefaultSerializer[testShelf]{AdditionalField: map[string]Field[testShelf]{
+		"status_display": &ChoiceField[testShelf, int]{Source: "Status", Choices: map[int]string{0: "open"}},
+		"created_at":     &DateTimeField[testShelf]{Source: "CreatedAt", Layout: "2006-01-02"},
+		"book_ids":       &PrimaryKeyRelatedField[testShelf]{Source: "Books"},
+	}}
+	shelf := &testShelf{ID: 1, CreatedAt: time.Date(2022, 12, 1, 0, 0, 0, 0, time.UTC), Books: []testShelfBook{{ID: 4}}}
+	result := map[string]any{}
+
+	assert.NoError(t, serializer.Serialize(&result, shelf, c))
+	assert.Equal(t, map[string]any{
+		"id":             uint(1),
+		"status_display": "open",
+		"created_at":     "2022-12-01",
+		"book_ids":       []any{uint(4)},
+	}, result)
+}
@@ -0,0 +1,142 @@
+// Package hal renders ViewSet responses as HAL+JSON documents, see
+// https://datatracker.ietf.org/doc/html/draft-kelly-json-hal.
+package hal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/TcMits/viewset"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	CONTENT_TYPE = "application/hal+json"
+
+	DEFAULT_EMBEDDED_REL = "items"
+)
+
+var _ viewset.Renderer = &Renderer{}
+
+// Renderer adds _links.self to every serialized object by reversing the
+// retrieve action of Reverser, and embeds List results under Rel.
+type Renderer struct {
+	Reverser viewset.Reverser
+	Params   map[string]string
+	// URI param -> key of the serialized object
+	Rel string
+	// optional, key of the results in _embedded, defaults to "items"
+}
+
+func NewRenderer(reverser viewset.Reverser, params map[string]string, rel string) *Renderer {
+	if rel == "" {
+		rel = DEFAULT_EMBEDDED_REL
+	}
+	return &Renderer{Reverser: reverser, Params: params, Rel: rel}
+}
+
+// Enable lets clients ask for HAL+JSON with the Accept header, the current
+// renderer stays the default.
+func Enable[EntityType, ValidateType any](
+	viewSet *viewset.ViewSet[EntityType, ValidateType], params map[string]string, rel string,
+) *viewset.ViewSet[EntityType, ValidateType] {
+	viewSet.Renderer = viewset.NewNegotiatedRenderer(viewSet.Renderer, map[string]viewset.Renderer{
+		CONTENT_TYPE: NewRenderer(viewSet, params, rel),
+	})
+	return viewSet
+}
+
+func link(href string) map[string]any {
+	return map[string]any{"href": href}
+}
+
+// absoluteHref completes the relative pagination links of the managers, a
+// nil link means there is no such page.
+func absoluteHref(value any, c *gin.Context) (string, bool) {
+	href, ok := value.(string)
+	if !ok || href == "" {
+		return "", false
+	}
+	if strings.HasPrefix(href, "/") {
+		href = viewset.AbsoluteURL(c, href)
+	}
+	return href, true
+}
+
+func (r *Renderer) resource(object map[string]any, c *gin.Context) (map[string]any, error) {
+	params := make(map[string]string, len(r.Params))
+	for param, key := range r.Params {
+		value, ok := object[key]
+		if !ok {
+			return nil, fmt.Errorf("serialized object has no %q field", key)
+		}
+		params[param] = fmt.Sprint(value)
+	}
+	path, err := r.Reverser.Reverse(viewset.DEFAULT_RETRIEVE_ACTION, params)
+	if err != nil {
+		return nil, err
+	}
+
+	resource := make(map[string]any, len(object)+1)
+	for key, value := range object {
+		resource[key] = value
+	}
+	resource["_links"] = map[string]any{"self": link(viewset.AbsoluteURL(c, path))}
+	return resource, nil
+}
+
+func (r *Renderer) Render(statusCode int, response *map[string]any, c *gin.Context) error {
+	resource, err := r.resource(*response, c)
+	if err != nil {
+		return err
+	}
+	c.Header("Content-Type", CONTENT_TYPE)
+	c.JSON(statusCode, resource)
+	return nil
+}
+
+// RenderMany embeds the results, turns the next and previous pagination
+// metadata into links and keeps the rest, like count, as properties.
+func (r *Renderer) RenderMany(
+	statusCode int, manyResponse *[]map[string]any, paginatedMeta *map[string]any, c *gin.Context,
+) error {
+	resources := make([]any, 0, len(*manyResponse))
+	for _, object := range *manyResponse {
+		resource, err := r.resource(object, c)
+		if err != nil {
+			return err
+		}
+		resources = append(resources, resource)
+	}
+
+	rel := r.Rel
+	if rel == "" {
+		rel = DEFAULT_EMBEDDED_REL
+	}
+	links := map[string]any{}
+	if c.Request != nil && c.Request.URL != nil {
+		links["self"] = link(viewset.AbsoluteURL(c, c.Request.URL.RequestURI()))
+	}
+	document := map[string]any{"_embedded": map[string]any{rel: resources}}
+	if paginatedMeta != nil {
+		for key, value := range *paginatedMeta {
+			switch key {
+			case "next":
+				if href, ok := absoluteHref(value, c); ok {
+					links["next"] = link(href)
+				}
+			case "previous":
+				if href, ok := absoluteHref(value, c); ok {
+					links["prev"] = link(href)
+				}
+			default:
+				document[key] = value
+			}
+		}
+	}
+	document["_links"] = links
+
+	c.Header("Content-Type", CONTENT_TYPE)
+	c.JSON(statusCode, document)
+	return nil
+}
@@ -0,0 +1,84 @@
+package hal
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/TcMits/viewset"
+	"github.com/TcMits/viewset/manager"
+	"github.com/TcMits/viewset/viewsettest"
+	"github.com/stretchr/testify/assert"
+)
+
+type book struct {
+	ID    uint   `mapstructure:"id"`
+	Title string `mapstructure:"title"`
+}
+
+type bookRequest struct {
+	Title string `json:"title" mapstructure:"title" binding:"required"`
+}
+
+type bookURI struct {
+	ID uint `uri:"pk" binding:"required"`
+}
+
+func newBookClient(t *testing.T) *viewsettest.Client {
+	bookManager := manager.NewMemoryManager[book, bookRequest, bookURI]("ID")
+	bookManager.Add(book{Title: "first"}, book{Title: "second"})
+	viewSet := Enable(viewset.NewViewSet[book, bookRequest](
+		"/books", "/:pk", nil, nil, bookManager, nil, nil, nil, nil,
+	), map[string]string{"pk": "id"}, "books")
+	return viewsettest.New(t, "/books", viewSet)
+}
+
+func TestHALRenderMany(t *testing.T) {
+	client := newBookClient(t).WithHeader("Accept", CONTENT_TYPE)
+
+	response := client.WithQuery("limit", "1").WithQuery("offset", "1").WithQuery("with_count", "true").
+		List().AssertStatus(http.StatusOK)
+
+	assert.Equal(t, CONTENT_TYPE, response.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"_embedded":{"books":[
+			{"id":2,"title":"second","_links":{"self":{"href":"http://example.com/books/2"}}}
+		]},
+		"_links":{
+			"self":{"href":"http://example.com/books/?limit=1&offset=1&with_count=true"},
+			"prev":{"href":"http://example.com/books/?limit=1&offset=0&with_count=true"}
+		},
+		"count":2
+	}`, response.Body.String())
+}
+
+func TestHALRender(t *testing.T) {
+	client := newBookClient(t).WithHeader("Accept", "application/hal+json;q=0.9, application/json;q=0.8")
+
+	response := client.Create(bookRequest{Title: "third"}).AssertStatus(http.StatusCreated)
+
+	assert.Equal(t, CONTENT_TYPE, response.Header().Get("Content-Type"))
+	assert.JSONEq(
+		t,
+		`{"id":3,"title":"third","_links":{"self":{"href":"http://example.com/books/3"}}}`,
+		response.Body.String(),
+	)
+}
+
+func TestHALNotAccepted(t *testing.T) {
+	client := newBookClient(t)
+
+	response := client.Retrieve(1).AssertStatus(http.StatusOK)
+	assert.Equal(t, "application/json; charset=utf-8", response.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"id":1,"title":"first"}`, response.Body.String())
+
+	response = client.WithHeader("Accept", "application/json, application/hal+json").Retrieve(1)
+	assert.JSONEq(t, `{"id":1,"title":"first"}`, response.Body.String())
+}
+
+func TestRendererWithMissingParam(t *testing.T) {
+	renderer := NewRenderer(viewset.NamedRoutes{"retrieve": "/books/:pk"}, map[string]string{"pk": "id"}, "")
+	assert.Equal(t, DEFAULT_EMBEDDED_REL, renderer.Rel)
+
+	_, err := renderer.resource(map[string]any{"title": "first"}, nil)
+	assert.EqualError(t, err, `serialized object has no "id" field`)
+}
@@ -0,0 +1,297 @@
+package viewset
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/TcMits/viewset/pkg/fieldset"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	DEFAULT_URL_FIELD     = "url"
+	FORWARDED_CONTEXT_KEY = "github.com/TcMits/viewset.forwarded"
+)
+
+var _ Reverser = &ViewSet[any, any]{}
+var _ Reverser = NamedRoutes{}
+var _ Serializer[any] = &HyperlinkedSerializer[any]{}
+var _ Field[any] = &HyperlinkedIdentityField[any]{}
+var _ Field[any] = &HyperlinkedRelatedField[any]{}
+
+var ErrRouteNotFound = errors.New("route not found")
+
+// NamedRoutes reverses gin path patterns by name, for routes which are not
+// served by a ViewSet.
+type NamedRoutes map[string]string
+
+func (routes NamedRoutes) Reverse(name string, params map[string]string) (string, error) {
+	pattern, ok := routes[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrRouteNotFound, name)
+	}
+	return expandPath(pattern, params)
+}
+
+// Reverse returns the path of the action, where the ViewSet is registered
+// if Register was called, BasePath otherwise.
+func (viewSet *ViewSet[_, _]) Reverse(action string, params map[string]string) (string, error) {
+	mountPath := viewSet.mountPath
+	if mountPath == "" {
+		mountPath = viewSet.BasePath
+	}
+	for _, route := range viewSet.Actions {
+		if route.Action == action {
+			return expandPath(joinPaths(mountPath, route.SubPath), params)
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrRouteNotFound, action)
+}
+
+func joinPaths(absolutePath string, relativePath string) string {
+	if relativePath == "" {
+		return absolutePath
+	}
+	joined := strings.TrimRight(absolutePath, "/") + "/" + strings.TrimLeft(relativePath, "/")
+	if strings.HasSuffix(relativePath, "/") && !strings.HasSuffix(joined, "/") {
+		return joined + "/"
+	}
+	return joined
+}
+
+// expandPath replaces the :name and *name segments of a gin path pattern.
+func expandPath(pattern string, params map[string]string) (string, error) {
+	segments := strings.Split(pattern, "/")
+	for i, segment := range segments {
+		if segment == "" || (segment[0] != ':' && segment[0] != '*') {
+			continue
+		}
+		value, ok := params[segment[1:]]
+		if !ok {
+			return "", fmt.Errorf("missing param %q to reverse %s", segment[1:], pattern)
+		}
+		if segment[0] == '*' {
+			segments[i] = strings.TrimLeft(value, "/")
+			continue
+		}
+		segments[i] = url.PathEscape(value)
+	}
+	return strings.Join(segments, "/"), nil
+}
+
+// TrustForwardedHeaders returns a middleware making AbsoluteURL honor the
+// X-Forwarded-* headers of the requests sent by the given proxies, IPs or
+// CIDRs. Headers of other clients are ignored, they could point the links
+// to any host. It panics if a proxy cannot be parsed.
+func TrustForwardedHeaders(proxies ...string) gin.HandlerFunc {
+	networks := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			if strings.Contains(proxy, ":") {
+				proxy += "/128"
+			} else {
+				proxy += "/32"
+			}
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			panic(fmt.Sprintf("invalid trusted proxy %q: %v", proxy, err))
+		}
+		networks = append(networks, network)
+	}
+	return func(c *gin.Context) {
+		if ip := net.ParseIP(c.RemoteIP()); ip != nil {
+			for _, network := range networks {
+				if network.Contains(ip) {
+					c.Set(FORWARDED_CONTEXT_KEY, true)
+					break
+				}
+			}
+		}
+		c.Next()
+	}
+}
+
+// AbsoluteURL builds an absolute URL for path from the request scheme and
+// host, honoring the X-Forwarded-Proto, X-Forwarded-Host and
+// X-Forwarded-Prefix headers of the proxies trusted by TrustForwardedHeaders.
+func AbsoluteURL(c *gin.Context, path string) string {
+	scheme := "http"
+	host := ""
+	prefix := ""
+	if c.Request != nil {
+		if c.Request.TLS != nil {
+			scheme = "https"
+		}
+		host = c.Request.Host
+		if c.Request.URL != nil && c.Request.URL.Host != "" && host == "" {
+			host = c.Request.URL.Host
+		}
+		if c.GetBool(FORWARDED_CONTEXT_KEY) {
+			if proto := forwardedHeader(c, "X-Forwarded-Proto"); proto != "" {
+				scheme = proto
+			}
+			if forwardedHost := forwardedHeader(c, "X-Forwarded-Host"); forwardedHost != "" {
+				host = forwardedHost
+			}
+			prefix = strings.TrimRight(forwardedHeader(c, "X-Forwarded-Prefix"), "/")
+		}
+	}
+	return scheme + "://" + host + prefix + path
+}
+
+// forwardedHeader returns the first value of a header proxies may append to.
+func forwardedHeader(c *gin.Context, key string) string {
+	value := c.GetHeader(key)
+	if i := strings.IndexByte(value, ','); i >= 0 {
+		value = value[:i]
+	}
+	return strings.TrimSpace(value)
+}
+
+// HyperlinkedIdentityField serializes the URL of the entity itself, Params
+// maps each URI param of the action to a field of the entity.
+type HyperlinkedIdentityField[EntityType any] struct {
+	Reverser Reverser
+	Action   string
+	Params   map[string]string
+}
+
+func (f *HyperlinkedIdentityField[EntityType]) Serialize(entity *EntityType, c *gin.Context) (any, error) {
+	return reverseObject(f.Reverser, f.Action, f.Params, reflect.ValueOf(entity), c)
+}
+
+// HyperlinkedRelatedField serializes the URL of the object held by the Source
+// field of the entity, or the URLs of the objects if Source is a slice.
+// Params maps each URI param of the action to a field of the related object,
+// an empty field name uses the Source value itself, e.g. a foreign key.
+type HyperlinkedRelatedField[EntityType any] struct {
+	Reverser Reverser
+	Action   string
+	Source   string
+	Params   map[string]string
+}
+
+func (f *HyperlinkedRelatedField[EntityType]) Serialize(entity *EntityType, c *gin.Context) (any, error) {
+	source := reflect.Indirect(reflect.ValueOf(entity)).FieldByName(f.Source)
+	if !source.IsValid() {
+		return nil, fmt.Errorf("unknown field %q", f.Source)
+	}
+	if source.Kind() != reflect.Slice {
+		return reverseObject(f.Reverser, f.Action, f.Params, source, c)
+	}
+	urls := make([]any, 0, source.Len())
+	for i := 0; i < source.Len(); i++ {
+		related, err := reverseObject(f.Reverser, f.Action, f.Params, source.Index(i), c)
+		if err != nil {
+			return nil, err
+		}
+		urls = append(urls, related)
+	}
+	return urls, nil
+}
+
+// reverseObject returns nil for a nil pointer or a zero value, which stands
+// for a missing relation.
+func reverseObject(
+	reverser Reverser, action string, fields map[string]string, value reflect.Value, c *gin.Context,
+) (any, error) {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil, nil
+		}
+		value = value.Elem()
+	}
+	if value.IsZero() {
+		return nil, nil
+	}
+	params := make(map[string]string, len(fields))
+	for param, field := range fields {
+		paramValue := value
+		if field != "" {
+			if value.Kind() != reflect.Struct {
+				return nil, fmt.Errorf("cannot read field %q of %s", field, value.Type())
+			}
+			paramValue = value.FieldByName(field)
+			if !paramValue.IsValid() {
+				return nil, fmt.Errorf("unknown field %q", field)
+			}
+		}
+		params[param] = fmt.Sprint(paramValue.Interface())
+	}
+	path, err := reverser.Reverse(action, params)
+	if err != nil {
+		return nil, err
+	}
+	return AbsoluteURL(c, path), nil
+}
+
+// HyperlinkedSerializer adds the URL of each entity under URLField to the
+// output of DefaultSerializer, use HyperlinkedRelatedField in
+// AdditionalField for related resources.
+type HyperlinkedSerializer[EntityType any] struct {
+	DefaultSerializer[EntityType]
+	URLField string
+	Identity Field[EntityType]
+}
+
+// NewHyperlinkedSerializer links entities to the retrieve action of the
+// reverser, assign it to ViewSet.Serializer before Register when the
+// reverser is the ViewSet itself.
+func NewHyperlinkedSerializer[EntityType any](
+	reverser Reverser,
+	params map[string]string,
+	additionalField map[string]Field[EntityType],
+) *HyperlinkedSerializer[EntityType] {
+	return &HyperlinkedSerializer[EntityType]{
+		DefaultSerializer: DefaultSerializer[EntityType]{AdditionalField: additionalField},
+		URLField:          DEFAULT_URL_FIELD,
+		Identity: &HyperlinkedIdentityField[EntityType]{
+			Reverser: reverser,
+			Action:   DEFAULT_RETRIEVE_ACTION,
+			Params:   params,
+		},
+	}
+}
+
+func (s *HyperlinkedSerializer[EntityType]) Serialize(
+	dest *map[string]any, entity *EntityType, c *gin.Context,
+) error {
+	return s.serialize(dest, entity, requestFieldset(c), requestExpand(c), c)
+}
+
+func (s *HyperlinkedSerializer[EntityType]) serialize(
+	dest *map[string]any, entity *EntityType, fields fieldset.Set, expand fieldset.Tree, c *gin.Context,
+) error {
+	if err := s.DefaultSerializer.serialize(dest, entity, fields, expand, c); err != nil {
+		return err
+	}
+	if !fields.Has(s.URLField) {
+		return nil
+	}
+	entityURL, err := s.Identity.Serialize(entity, c)
+	if err != nil {
+		return err
+	}
+	(*dest)[s.URLField] = entityURL
+	return nil
+}
+
+func (s *HyperlinkedSerializer[EntityType]) ManySerialize(
+	dest *[]map[string]any, entities *[]*EntityType, c *gin.Context,
+) error {
+	fields := requestFieldset(c)
+	expand := requestExpand(c)
+	for _, entity := range *entities {
+		var destObject map[string]any
+		if err := s.serialize(&destObject, entity, fields, expand, c); err != nil {
+			return err
+		}
+		*dest = append(*dest, destObject)
+	}
+	return nil
+}
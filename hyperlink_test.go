@@ -0,0 +1,180 @@
+package viewset
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type testAuthor struct {
+	Pk int
+}
+
+type testBook struct {
+	Pk       int    `mapstructure:"-"`
+	Title    string `mapstructure:"title"`
+	AuthorPk int    `mapstructure:"-"`
+	Editor   *testAuthor
+	Authors  []testAuthor
+}
+
+var testAuthorRoutes = NamedRoutes{"author-detail": "/authors/:pk"}
+
+func TestViewSetReverse(t *testing.T) {
+	viewSet := NewViewSet[testObject, testObjectRequest](
+		"/objects", "/:pk", nil, []Route[testObject, testObjectRequest]{{
+			Action:  "files",
+			SubPath: "/:pk/files/*path",
+			Method:  http.MethodGet,
+			Handler: Retrieve[testObject, testObjectRequest],
+		}}, &testObjectManager{}, nil, nil, nil, nil,
+	)
+
+	path, err := viewSet.Reverse(DEFAULT_RETRIEVE_ACTION, map[string]string{"pk": "1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "/objects/1", path)
+
+	viewSet.Register(SetUpRouter().Group("/api"))
+
+	path, err = viewSet.Reverse(DEFAULT_RETRIEVE_ACTION, map[string]string{"pk": "a b"})
+	assert.NoError(t, err)
+	assert.Equal(t, "/api/objects/a%20b", path)
+	path, err = viewSet.Reverse(DEFAULT_LIST_ACTION, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "/api/objects/", path)
+	path, err = viewSet.Reverse("files", map[string]string{"pk": "1", "path": "/a/b.txt"})
+	assert.NoError(t, err)
+	assert.Equal(t, "/api/objects/1/files/a/b.txt", path)
+
+	_, err = viewSet.Reverse(DEFAULT_RETRIEVE_ACTION, nil)
+	assert.EqualError(t, err, `missing param "pk" to reverse /api/objects/:pk`)
+	_, err = viewSet.Reverse("unknown", nil)
+	assert.ErrorIs(t, err, ErrRouteNotFound)
+}
+
+func TestNamedRoutesReverse(t *testing.T) {
+	path, err := testAuthorRoutes.Reverse("author-detail", map[string]string{"pk": "2"})
+	assert.NoError(t, err)
+	assert.Equal(t, "/authors/2", path)
+
+	_, err = testAuthorRoutes.Reverse("book-detail", nil)
+	assert.ErrorIs(t, err, ErrRouteNotFound)
+}
+
+func TestAbsoluteURL(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "http://example.com/objects/", nil)
+	assert.Equal(t, "http://example.com/objects/1", AbsoluteURL(c, "/objects/1"))
+
+	c.Request.TLS = &tls.ConnectionState{}
+	assert.Equal(t, "https://example.com/objects/1", AbsoluteURL(c, "/objects/1"))
+
+	c.Request.Header.Set("X-Forwarded-Proto", "http, https")
+	c.Request.Header.Set("X-Forwarded-Host", "api.example.com")
+	c.Request.Header.Set("X-Forwarded-Prefix", "/v1/")
+	assert.Equal(t, "https://example.com/objects/1", AbsoluteURL(c, "/objects/1"))
+
+	TrustForwardedHeaders("10.0.0.0/8", "192.0.2.1")(c)
+	assert.Equal(t, "http://api.example.com/v1/objects/1", AbsoluteURL(c, "/objects/1"))
+}
+
+func TestTrustForwardedHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	middleware := TrustForwardedHeaders("10.0.0.0/8", "2001:db8::1")
+	for remoteAddr, trusted := range map[string]bool{
+		"10.1.2.3:1234":      true,
+		"[2001:db8::1]:1234": true,
+		"192.0.2.1:1234":     false,
+		"[2001:db8::2]:1234": false,
+		"invalid":            false,
+	} {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		c.Request.RemoteAddr = remoteAddr
+		middleware(c)
+		assert.Equal(t, trusted, c.GetBool(FORWARDED_CONTEXT_KEY), remoteAddr)
+	}
+
+	assert.Panics(t, func() { TrustForwardedHeaders("not an ip") })
+}
+
+func TestHyperlinkedRelatedField(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "http://example.com/books/", nil)
+	book := testBook{
+		Pk:       1,
+		AuthorPk: 2,
+		Authors:  []testAuthor{{Pk: 3}, {Pk: 4}},
+	}
+
+	author := &HyperlinkedRelatedField[testBook]{
+		Reverser: testAuthorRoutes, Action: "author-detail", Source: "AuthorPk", Params: map[string]string{"pk": ""},
+	}
+	value, err := author.Serialize(&book, c)
+	assert.NoError(t, err)
+	assert.Equal(t, "http://example.com/authors/2", value)
+
+	editor := &HyperlinkedRelatedField[testBook]{
+		Reverser: testAuthorRoutes, Action: "author-detail", Source: "Editor", Params: map[string]string{"pk": "Pk"},
+	}
+	value, err = editor.Serialize(&book, c)
+	assert.NoError(t, err)
+	assert.Nil(t, value)
+	book.Editor = &testAuthor{Pk: 5}
+	value, err = editor.Serialize(&book, c)
+	assert.NoError(t, err)
+	assert.Equal(t, "http://example.com/authors/5", value)
+
+	authors := &HyperlinkedRelatedField[testBook]{
+		Reverser: testAuthorRoutes, Action: "author-detail", Source: "Authors", Params: map[string]string{"pk": "Pk"},
+	}
+	value, err = authors.Serialize(&book, c)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"http://example.com/authors/3", "http://example.com/authors/4"}, value)
+
+	_, err = (&HyperlinkedRelatedField[testBook]{
+		Reverser: testAuthorRoutes, Action: "author-detail", Source: "Publisher",
+	}).Serialize(&book, c)
+	assert.Error(t, err)
+	_, err = (&HyperlinkedRelatedField[testBook]{
+		Reverser: testAuthorRoutes, Action: "author-detail", Source: "AuthorPk", Params: map[string]string{"pk": "Pk"},
+	}).Serialize(&book, c)
+	assert.Error(t, err)
+}
+
+func TestHyperlinkedSerializer(t *testing.T) {
+	objectManager := &testObjectManager{Database: []testObject{{Pk: 1, Name: "test", Age: 18}}}
+	viewSet := NewViewSet[testObject, testObjectRequest](
+		"/objects", "/:pk", nil, nil, objectManager, nil, nil, nil, nil,
+	)
+	viewSet.Serializer = NewHyperlinkedSerializer[testObject](
+		viewSet, map[string]string{"pk": "Pk"}, nil,
+	)
+	router := SetUpRouter()
+	router.Use(TrustForwardedHeaders("192.0.2.1"))
+	viewSet.Register(router.Group("/api"))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/objects/1", nil)
+	req.Header.Set("X-Forwarded-Proto", "https")
+	req.Host = "example.com"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"name":"test","age":18,"url":"https://example.com/api/objects/1"}`, w.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/api/objects/", nil)
+	req.Host = "example.com"
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	response := map[string]any{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "http://example.com/api/objects/1", response["results"].([]any)[0].(map[string]any)["url"])
+}
@@ -0,0 +1,78 @@
+package viewset
+
+import "github.com/gin-gonic/gin"
+
+const (
+	PERMISSION_PHASE  = "permission"
+	GET_OBJECT_PHASE  = "get_object"
+	GET_OBJECTS_PHASE = "get_objects"
+	VALIDATE_PHASE    = "validate"
+	SAVE_PHASE        = "save"
+	DELETE_PHASE      = "delete"
+	SERIALIZE_PHASE   = "serialize"
+	RENDER_PHASE      = "render"
+)
+
+var _ Instrumentation = &NopInstrumentation{}
+var _ Instrumentation = MultiInstrumentation{}
+
+type NopInstrumentation struct{}
+
+// MultiInstrumentation fans every call out to all of its instrumentations,
+// e.g. to collect metrics and traces at the same time.
+type MultiInstrumentation []Instrumentation
+
+func (_ *NopInstrumentation) StartAction(_ string, _ *gin.Context) func() {
+	return func() {}
+}
+
+func (_ *NopInstrumentation) StartPhase(_ string, _ string, _ *gin.Context) func(error) {
+	return func(error) {}
+}
+
+func (m MultiInstrumentation) StartAction(action string, c *gin.Context) func() {
+	ends := make([]func(), 0, len(m))
+	for _, instrumentation := range m {
+		ends = append(ends, instrumentation.StartAction(action, c))
+	}
+	return func() {
+		for i := len(ends) - 1; i >= 0; i-- {
+			ends[i]()
+		}
+	}
+}
+
+func (m MultiInstrumentation) StartPhase(action string, phase string, c *gin.Context) func(error) {
+	ends := make([]func(error), 0, len(m))
+	for _, instrumentation := range m {
+		ends = append(ends, instrumentation.StartPhase(action, phase, c))
+	}
+	return func(err error) {
+		for i := len(ends) - 1; i >= 0; i-- {
+			ends[i](err)
+		}
+	}
+}
+
+func (viewSet *ViewSet[_, _]) instrumentation() Instrumentation {
+	if viewSet.Instrumentation == nil {
+		return &NopInstrumentation{}
+	}
+	return viewSet.Instrumentation
+}
+
+// observe wraps a phase of the action, a panic ends the phase with a
+// PanicError before it goes on to the recovery of the ViewSet.
+func (viewSet *ViewSet[_, _]) observe(
+	action string, phase string, c *gin.Context, function func() error,
+) (err error) {
+	end := viewSet.instrumentation().StartPhase(action, phase, c)
+	defer func() {
+		if value := recover(); value != nil {
+			end(&PanicError{Value: value})
+			panic(value)
+		}
+		end(err)
+	}()
+	return function()
+}
@@ -0,0 +1,130 @@
+package viewset
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type testInstrumentation struct {
+	Events []string
+	Errors []error
+}
+
+func (i *testInstrumentation) StartAction(action string, c *gin.Context) func() {
+	i.Events = append(i.Events, "start "+action)
+	return func() {
+		i.Events = append(i.Events, "end "+action)
+	}
+}
+
+func (i *testInstrumentation) StartPhase(action string, phase string, c *gin.Context) func(error) {
+	return func(err error) {
+		if err != nil {
+			i.Events = append(i.Events, phase+" error")
+			i.Errors = append(i.Errors, err)
+			return
+		}
+		i.Events = append(i.Events, phase)
+	}
+}
+
+func TestNopInstrumentation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	instrumentation := &NopInstrumentation{}
+
+	instrumentation.StartAction(DEFAULT_LIST_ACTION, c)()
+	instrumentation.StartPhase(DEFAULT_LIST_ACTION, RENDER_PHASE, c)(errors.New("ignored"))
+}
+
+func TestMultiInstrumentation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	first := &testInstrumentation{}
+	second := &testInstrumentation{}
+	instrumentation := MultiInstrumentation{first, second}
+
+	end := instrumentation.StartAction(DEFAULT_LIST_ACTION, c)
+	instrumentation.StartPhase(DEFAULT_LIST_ACTION, RENDER_PHASE, c)(errors.New("testing"))
+	end()
+
+	assert.Equal(t, []string{"start list", "render error", "end list"}, first.Events)
+	assert.Equal(t, []string{"start list", "render error", "end list"}, second.Events)
+}
+
+func TestViewSetObserveWithoutInstrumentation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	viewSet := &ViewSet[testObject, testObjectRequest]{}
+
+	err := viewSet.observe(DEFAULT_LIST_ACTION, RENDER_PHASE, c, func() error {
+		return errors.New("testing")
+	})
+
+	assert.Equal(t, "testing", err.Error())
+}
+
+func TestViewSetObserveEndsPanickingPhases(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	instrumentation := &testInstrumentation{}
+	viewSet := &ViewSet[testObject, testObjectRequest]{Instrumentation: instrumentation}
+
+	assert.PanicsWithValue(t, "testing", func() {
+		_ = viewSet.observe(DEFAULT_LIST_ACTION, RENDER_PHASE, c, func() error {
+			panic("testing")
+		})
+	})
+
+	assert.Equal(t, []string{"render error"}, instrumentation.Events)
+	assert.Equal(t, []error{&PanicError{Value: "testing"}}, instrumentation.Errors)
+}
+
+func TestInstrumentationPhases(t *testing.T) {
+	objectManager := &testObjectManager{}
+	objectManager.Database = append(
+		objectManager.Database,
+		testObject{Pk: 1, Name: "test", Age: 20},
+	)
+	viewSet := NewViewSet[testObject, testObjectRequest](
+		"/objects", "/:pk", nil, nil, objectManager, nil, nil, nil, nil,
+	)
+	instrumentation := &testInstrumentation{}
+	viewSet.Instrumentation = instrumentation
+	router := SetUpRouter()
+	viewSet.Register(router)
+
+	requests := []struct {
+		method   string
+		path     string
+		body     string
+		expected string
+	}{
+		{http.MethodGet, "/objects/", "", "start list,permission,get_objects,serialize,render,end list"},
+		{http.MethodGet, "/objects/1", "", "start retrieve,permission,get_object,serialize,render,end retrieve"},
+		{http.MethodGet, "/objects/9", "", "start retrieve,permission,get_object error,end retrieve"},
+		{
+			http.MethodPost, "/objects/", `{"name":"test","age":20}`,
+			"start create,permission,validate,save,serialize,render,end create",
+		},
+		{
+			http.MethodPut, "/objects/1", `{"name":"test","age":21}`,
+			"start update,permission,get_object,validate,save,serialize,render,end update",
+		},
+		{http.MethodDelete, "/objects/1", "", "start delete,permission,get_object,delete,render,end delete"},
+	}
+	for _, request := range requests {
+		instrumentation.Events = nil
+		req := httptest.NewRequest(request.method, request.path, strings.NewReader(request.body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.Equal(t, request.expected, strings.Join(instrumentation.Events, ","))
+	}
+}
@@ -1,6 +1,9 @@
 package viewset
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/TcMits/viewset/pkg/fieldrule"
+	"github.com/gin-gonic/gin"
+)
 
 type Field[EntityType any] interface {
 	Serialize(*EntityType, *gin.Context) (any, error)
@@ -32,3 +35,42 @@ type ExceptionHandler interface {
 	Handle(error, *gin.Context)
 	// use something like ctx.AbortWithStatusJSON(http.StatusUnauthorized, map[string]any{"Message": "Unauthorized"}) to abort request
 }
+
+type Reverser interface {
+	Reverse(string, map[string]string) (string, error)
+	// return the path of a named route, filling its URI params
+}
+
+type Instrumentation interface {
+	StartAction(string, *gin.Context) func()
+	// called before an action, the returned function is called after the response is written
+	StartPhase(string, string, *gin.Context) func(error)
+	// called before a phase of an action, the returned function receives the phase error
+}
+
+type Renderer interface {
+	Render(int, *map[string]any, *gin.Context) error
+	// write a single object
+	RenderMany(int, *[]map[string]any, *map[string]any, *gin.Context) error
+	// write a list of objects with the pagination metadata
+}
+
+type QueryValidator interface {
+	ValidateQuery(*gin.Context) error
+	// reject the query params of the request before the manager runs, e.g. a renderer its unknown includes
+}
+
+type PanicReporter interface {
+	ReportPanic(any, []byte, *gin.Context)
+	// called with the recovered value and the stack trace before the exception handler answers the panic
+}
+
+type FieldRuleDeclarer interface {
+	FieldRuleOverrides() fieldrule.Rules
+	// rules merged over those the entity declares, see pkg/fieldrule
+}
+
+type Expander interface {
+	Expansions() map[string]Expansion
+	// expand name -> related objects a serializer can nest, see EXPAND_PARAM
+}
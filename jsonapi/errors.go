@@ -0,0 +1,119 @@
+package jsonapi
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/TcMits/viewset"
+	"github.com/gin-gonic/gin"
+)
+
+var _ viewset.ExceptionHandler = &ExceptionHandler{}
+
+type ErrorSource struct {
+	Pointer   string `json:"pointer,omitempty"`
+	Parameter string `json:"parameter,omitempty"`
+}
+
+type Error struct {
+	Status string       `json:"status,omitempty"`
+	Code   string       `json:"code,omitempty"`
+	Title  string       `json:"title,omitempty"`
+	Detail string       `json:"detail,omitempty"`
+	Source *ErrorSource `json:"source,omitempty"`
+}
+
+// Errors is an error carrying the JSON:API error objects to render.
+type Errors []Error
+
+func (errs Errors) Error() string {
+	details := make([]string, 0, len(errs))
+	for _, err := range errs {
+		if err.Detail != "" {
+			details = append(details, err.Detail)
+		} else {
+			details = append(details, err.Title)
+		}
+	}
+	return strings.Join(details, "; ")
+}
+
+// ExceptionHandler writes an errors document, the error objects are taken
+// from an Errors in the chain, one per message of viewset.ValidationErrors,
+// or built from the message.
+type ExceptionHandler struct{}
+
+func (_ *ExceptionHandler) Handle(err error, c *gin.Context) {
+	c.Error(err)
+	statusCode := http.StatusBadRequest
+	viewSetErr := new(viewset.ViewSetError)
+	if errors.As(err, &viewSetErr) {
+		statusCode = viewSetErr.StatusCode
+	}
+
+	errs := Errors{}
+	validationErrs := viewset.ValidationErrors{}
+	switch {
+	case errors.As(err, &errs) && len(errs) > 0:
+	case errors.As(err, &validationErrs) && len(validationErrs) > 0:
+		errs = validationErrors(validationErrs)
+	default:
+		errs = Errors{{Title: http.StatusText(statusCode), Detail: err.Error()}}
+	}
+	rendered := make(Errors, 0, len(errs))
+	for _, errorObject := range errs {
+		if errorObject.Status == "" {
+			errorObject.Status = strconv.Itoa(statusCode)
+		}
+		rendered = append(rendered, errorObject)
+	}
+
+	c.Header("Content-Type", CONTENT_TYPE)
+	c.AbortWithStatusJSON(statusCode, map[string]any{"errors": rendered})
+}
+
+// validationErrors returns an error object per message, pointing to the
+// attribute, ordered by field.
+func validationErrors(validationErrs viewset.ValidationErrors) Errors {
+	fields := make([]string, 0, len(validationErrs))
+	for field := range validationErrs {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	errs := make(Errors, 0, len(fields))
+	for _, field := range fields {
+		for _, validationErr := range validationErrs[field] {
+			errorObject := Error{Code: validationErr.Code, Title: "Invalid Attribute", Detail: validationErr.Message}
+			if field == viewset.NON_FIELD_ERRORS {
+				errorObject.Title = "Invalid Document"
+				errorObject.Source = &ErrorSource{Pointer: "/data"}
+			} else {
+				errorObject.Source = &ErrorSource{Pointer: "/data/attributes" + fieldPointer(field)}
+			}
+			errs = append(errs, errorObject)
+		}
+	}
+	return errs
+}
+
+// fieldPointer turns the path of a field, like items[1].name, into a JSON
+// pointer, like /items/1/name.
+func fieldPointer(field string) string {
+	escaper := strings.NewReplacer("~", "~0", "/", "~1")
+	pointer := ""
+	for _, segment := range strings.Split(field, ".") {
+		name, indexes, _ := strings.Cut(segment, "[")
+		if name != "" {
+			pointer += "/" + escaper.Replace(name)
+		}
+		if indexes != "" {
+			for _, index := range strings.Split(strings.TrimSuffix(indexes, "]"), "][") {
+				pointer += "/" + escaper.Replace(index)
+			}
+		}
+	}
+	return pointer
+}
@@ -0,0 +1,66 @@
+package jsonapi
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/TcMits/viewset"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorsError(t *testing.T) {
+	errs := Errors{{Title: "Invalid Document"}, {Title: "Invalid Attribute", Detail: "title is required"}}
+
+	assert.Equal(t, "Invalid Document; title is required", errs.Error())
+}
+
+func TestExceptionHandler(t *testing.T) {
+	c, w := newTestContext("/articles/")
+
+	(&ExceptionHandler{}).Handle(errors.New("bad request"), c)
+
+	assert.True(t, c.IsAborted())
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, CONTENT_TYPE, w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"errors":[{"status":"400","title":"Bad Request","detail":"bad request"}]}`, w.Body.String())
+	assert.Equal(t, 1, len(c.Errors))
+
+	c, w = newTestContext("/articles/")
+
+	(&ExceptionHandler{}).Handle(viewset.NewViewSetError("invalid", http.StatusUnprocessableEntity, Errors{
+		{Title: "Invalid Attribute", Source: &ErrorSource{Pointer: "/data/attributes/title"}},
+		{Status: "409", Title: "Conflict"},
+	}), c)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.JSONEq(t, `{"errors":[
+		{"status":"422","title":"Invalid Attribute","source":{"pointer":"/data/attributes/title"}},
+		{"status":"409","title":"Conflict"}
+	]}`, w.Body.String())
+}
+
+func TestExceptionHandlerValidationErrors(t *testing.T) {
+	c, w := newTestContext("/articles/")
+
+	errs := viewset.ValidationErrors{
+		"title":                  {{Code: "required", Message: "This field is required."}},
+		"items[1].name":          {{Code: "invalid_type", Message: "Expected string, got number."}},
+		viewset.NON_FIELD_ERRORS: {{Code: "invalid_json", Message: "Invalid JSON."}},
+	}
+	(&ExceptionHandler{}).Handle(viewset.NewViewSetError(errs.Error(), http.StatusBadRequest, errs), c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"errors":[
+		{"status":"400","code":"invalid_type","title":"Invalid Attribute","detail":"Expected string, got number.","source":{"pointer":"/data/attributes/items/1/name"}},
+		{"status":"400","code":"invalid_json","title":"Invalid Document","detail":"Invalid JSON.","source":{"pointer":"/data"}},
+		{"status":"400","code":"required","title":"Invalid Attribute","detail":"This field is required.","source":{"pointer":"/data/attributes/title"}}
+	]}`, w.Body.String())
+}
+
+func TestFieldPointer(t *testing.T) {
+	assert.Equal(t, "/title", fieldPointer("title"))
+	assert.Equal(t, "/items/1/name", fieldPointer("items[1].name"))
+	assert.Equal(t, "/grid/0/1", fieldPointer("grid[0][1]"))
+	assert.Equal(t, "/tags/a~1b", fieldPointer("tags[a/b]"))
+}
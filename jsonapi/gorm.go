@@ -0,0 +1,28 @@
+package jsonapi
+
+import (
+	"github.com/TcMits/viewset/manager"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// IncludeScope preloads the GORM association of every relationship requested
+// by the include query param, unknown relationships fail the query. The
+// Renderer rejects them before the manager runs.
+func IncludeScope(resource Resource) manager.GormScopeGenerator {
+	return func(c *gin.Context) func(*gorm.DB) *gorm.DB {
+		return func(db *gorm.DB) *gorm.DB {
+			names, err := includes(resource, c)
+			if err != nil {
+				db.AddError(err)
+				return db
+			}
+			for _, name := range names {
+				if association := resource.Relationships[name].Association; association != "" {
+					db = db.Preload(association)
+				}
+			}
+			return db
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// Package jsonapi renders and parses ViewSet documents in the JSON:API
+// format, see https://jsonapi.org/format/.
+package jsonapi
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/TcMits/viewset"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	CONTENT_TYPE = "application/vnd.api+json"
+
+	DEFAULT_ID_FIELD = "id"
+	INCLUDE_PARAM    = "include"
+)
+
+// Resource describes how the serialized objects of a ViewSet map to JSON:API
+// resource objects, every serialized key which is neither the id nor a
+// relationship is an attribute.
+type Resource struct {
+	Type    string
+	IDField string
+	// optional, key of the id in the serialized object, defaults to "id"
+	Relationships map[string]Relationship
+	// relationship name -> relationship
+}
+
+type Relationship struct {
+	Type string
+	// JSON:API type of the related resources
+	Field string
+	// optional, key of the related object(s) or id(s) in the serialized object, defaults to the relationship name
+	ForeignKey string
+	// optional, key of the related id used when Field holds no object, and set from request bodies instead of Field
+	IDField string
+	// optional, key of the id in the serialized related objects, defaults to "id"
+	Association string
+	// optional, GORM association preloaded by IncludeScope when the relationship is included
+	ToMany bool
+	// parse a to-many linkage in request bodies
+}
+
+func (resource Resource) idField() string {
+	if resource.IDField == "" {
+		return DEFAULT_ID_FIELD
+	}
+	return resource.IDField
+}
+
+func (relationship Relationship) field(name string) string {
+	if relationship.Field == "" {
+		return name
+	}
+	return relationship.Field
+}
+
+// inputField returns the key of ValidateType which request bodies set.
+func (relationship Relationship) inputField(name string) string {
+	if relationship.ForeignKey != "" {
+		return relationship.ForeignKey
+	}
+	return relationship.field(name)
+}
+
+func (relationship Relationship) idField() string {
+	if relationship.IDField == "" {
+		return DEFAULT_ID_FIELD
+	}
+	return relationship.IDField
+}
+
+// Enable switches the ViewSet to JSON:API documents for responses, errors and
+// request bodies, the current FormValidator validates the parsed attributes.
+func Enable[EntityType, ValidateType any](
+	viewSet *viewset.ViewSet[EntityType, ValidateType], resource Resource,
+) *viewset.ViewSet[EntityType, ValidateType] {
+	viewSet.Renderer = &Renderer{Resource: resource}
+	viewSet.ExceptionHandler = &ExceptionHandler{}
+	viewSet.FormValidator = &Validator[EntityType, ValidateType]{
+		Resource:      resource,
+		FormValidator: viewSet.FormValidator,
+	}
+	return viewSet
+}
+
+// includes returns the relationships requested by the include query param,
+// only relationships of the resource itself can be included.
+func includes(resource Resource, c *gin.Context) ([]string, error) {
+	value := c.Query(INCLUDE_PARAM)
+	if value == "" {
+		return nil, nil
+	}
+	names := strings.Split(value, ",")
+	for _, name := range names {
+		if _, ok := resource.Relationships[name]; !ok {
+			detail := fmt.Sprintf("relationship %q cannot be included", name)
+			return nil, viewset.NewViewSetError(detail, http.StatusBadRequest, Errors{{
+				Status: "400",
+				Title:  "Invalid Query Parameter",
+				Detail: detail,
+				Source: &ErrorSource{Parameter: INCLUDE_PARAM},
+			}})
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,161 @@
+package jsonapi
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/TcMits/viewset"
+	"github.com/TcMits/viewset/manager"
+	"github.com/TcMits/viewset/viewsettest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type author struct {
+	ID   uint   `gorm:"primarykey" mapstructure:"id"`
+	Name string `mapstructure:"name"`
+}
+
+type article struct {
+	ID       uint    `gorm:"primarykey" mapstructure:"id"`
+	Title    string  `mapstructure:"title"`
+	AuthorID uint    `mapstructure:"author_id"`
+	Author   *author `mapstructure:"author"`
+}
+
+type articleRequest struct {
+	Title    string `json:"title" mapstructure:"title" binding:"required"`
+	AuthorID uint   `json:"author_id" mapstructure:"author_id" binding:"required"`
+}
+
+type articleURI struct {
+	ID uint `uri:"pk" binding:"required"`
+}
+
+type testDocument struct {
+	Data     any              `json:"data"`
+	Included []map[string]any `json:"included"`
+	Links    map[string]any   `json:"links"`
+	Meta     map[string]any   `json:"meta"`
+	Errors   []Error          `json:"errors"`
+}
+
+var articleResource = Resource{
+	Type: "articles",
+	Relationships: map[string]Relationship{
+		"author": {Type: "people", ForeignKey: "author_id", Association: "Author"},
+	},
+}
+
+func newArticleClient(t *testing.T) (*viewsettest.Client, *gorm.DB) {
+	db, err := gorm.Open(
+		sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())),
+		&gorm.Config{Logger: logger.Default.LogMode(logger.Silent)},
+	)
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&author{}, &article{}))
+	require.NoError(t, db.Create(&author{Name: "phuc"}).Error)
+
+	articleManager := manager.NewGormManager[article, articleRequest, articleURI](
+		db.Model(&article{}), nil, nil, nil, nil, "db", IncludeScope(articleResource),
+	)
+	viewSet := Enable(viewset.NewViewSet[article, articleRequest](
+		"/articles", "/:pk", nil, nil, articleManager, nil, nil, nil, nil,
+	), articleResource)
+	return viewsettest.New(t, "/articles", viewSet).WithHeader("Content-Type", CONTENT_TYPE), db
+}
+
+func TestJSONAPIWithGormManager(t *testing.T) {
+	client, _ := newArticleClient(t)
+
+	created := client.Create(`{"data":{"type":"articles","attributes":{"title":"first"},
+		"relationships":{"author":{"data":{"type":"people","id":"1"}}}}}`).AssertStatus(http.StatusCreated)
+	assert.Equal(t, CONTENT_TYPE, created.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"data":{"type":"articles","id":"1","attributes":{"title":"first"},
+			"relationships":{"author":{"data":{"type":"people","id":"1"}}}},
+		"links":{"self":"http://example.com/articles/"}
+	}`, created.Body.String())
+	client.Create(`{"data":{"type":"articles","attributes":{"title":"second"},
+		"relationships":{"author":{"data":{"type":"people","id":"1"}}}}}`).AssertStatus(http.StatusCreated)
+
+	list := viewsettest.DecodeObject[testDocument](
+		client.WithQuery("include", "author").WithQuery("limit", "1").WithQuery("with_count", "true").
+			List().AssertStatus(http.StatusOK),
+	)
+	assert.Equal(t, []any{map[string]any{
+		"type": "articles", "id": "1", "attributes": map[string]any{"title": "first"},
+		"relationships": map[string]any{"author": map[string]any{
+			"data": map[string]any{"type": "people", "id": "1"},
+		}},
+	}}, list.Data)
+	assert.Equal(t, []map[string]any{
+		{"type": "people", "id": "1", "attributes": map[string]any{"name": "phuc"}},
+	}, list.Included)
+	assert.Equal(t, map[string]any{"count": float64(2)}, list.Meta)
+	assert.Equal(t, "http://example.com/articles/?include=author&limit=1&offset=1&with_count=true", list.Links["next"])
+	assert.Nil(t, list.Links["prev"])
+
+	updated := viewsettest.DecodeObject[testDocument](client.PartialUpdate(2, `{"data":{"type":"articles","id":"2",
+		"attributes":{"title":"edited"},"relationships":{"author":{"data":{"type":"people","id":"1"}}}}}`).
+		AssertStatus(http.StatusOK))
+	assert.Equal(t, "edited", updated.Data.(map[string]any)["attributes"].(map[string]any)["title"])
+}
+
+func TestJSONAPIErrors(t *testing.T) {
+	client, _ := newArticleClient(t)
+
+	errorDoc := viewsettest.DecodeObject[testDocument](client.Retrieve(5).AssertStatus(http.StatusNotFound))
+	assert.Equal(t, []Error{{Status: "404", Title: "Not Found", Detail: "object not found"}}, errorDoc.Errors)
+
+	errorDoc = viewsettest.DecodeObject[testDocument](
+		client.WithQuery("include", "comments").List().AssertStatus(http.StatusBadRequest),
+	)
+	assert.Equal(t, []Error{{
+		Status: "400", Title: "Invalid Query Parameter", Detail: `relationship "comments" cannot be included`,
+		Source: &ErrorSource{Parameter: "include"},
+	}}, errorDoc.Errors)
+
+	errorDoc = viewsettest.DecodeObject[testDocument](
+		client.Create(`{"data":{"type":"people","attributes":{"title":"first"}}}`).AssertStatus(http.StatusConflict),
+	)
+	assert.Equal(t, "Type Conflict", errorDoc.Errors[0].Title)
+	assert.Equal(t, "/data/type", errorDoc.Errors[0].Source.Pointer)
+
+	errorDoc = viewsettest.DecodeObject[testDocument](
+		client.Create(`{"data":{"type":"articles","attributes":{}}}`).AssertStatus(http.StatusBadRequest),
+	)
+	assert.ElementsMatch(t, []Error{{
+		Status: "400", Code: "required", Title: "Invalid Attribute", Detail: "title failed on the 'required' rule",
+		Source: &ErrorSource{Pointer: "/data/attributes/title"},
+	}, {
+		Status: "400", Code: "required", Title: "Invalid Relationship", Detail: "author_id failed on the 'required' rule",
+		Source: &ErrorSource{Pointer: "/data/relationships/author"},
+	}}, errorDoc.Errors)
+}
+
+func TestJSONAPIRejectsIncludeBeforeWriting(t *testing.T) {
+	client, db := newArticleClient(t)
+
+	errorDoc := viewsettest.DecodeObject[testDocument](client.WithQuery("include", "comments").Create(
+		`{"data":{"type":"articles","attributes":{"title":"first"},
+		"relationships":{"author":{"data":{"type":"people","id":"1"}}}}}`,
+	).AssertStatus(http.StatusBadRequest))
+	assert.Equal(t, "Invalid Query Parameter", errorDoc.Errors[0].Title)
+
+	count := int64(0)
+	require.NoError(t, db.Model(&article{}).Count(&count).Error)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestIncludeScopeFailsUnknownRelationships(t *testing.T) {
+	_, db := newArticleClient(t)
+	c, _ := newTestContext("/articles/?include=comments")
+
+	err := db.Model(&article{}).Scopes(IncludeScope(articleResource)(c)).Find(&[]article{}).Error
+	assert.Error(t, err)
+}
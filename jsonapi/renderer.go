@@ -0,0 +1,257 @@
+package jsonapi
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/TcMits/viewset"
+	"github.com/gin-gonic/gin"
+	"github.com/mitchellh/mapstructure"
+)
+
+var _ viewset.Renderer = &Renderer{}
+var _ viewset.QueryValidator = &Renderer{}
+
+// Renderer writes the serialized objects as JSON:API documents, related
+// objects of included relationships are added to the included member.
+type Renderer struct {
+	Resource Resource
+}
+
+// ValidateQuery rejects relationships the include query param cannot name,
+// before the manager runs.
+func (r *Renderer) ValidateQuery(c *gin.Context) error {
+	_, err := includes(r.Resource, c)
+	return err
+}
+
+func (r *Renderer) Render(statusCode int, response *map[string]any, c *gin.Context) error {
+	doc, err := newDocument(r.Resource, c)
+	if err != nil {
+		return err
+	}
+	data, err := doc.resourceObject(*response)
+	if err != nil {
+		return err
+	}
+	return doc.write(statusCode, data, map[string]any{"self": selfLink(c)}, nil, c)
+}
+
+func (r *Renderer) RenderMany(
+	statusCode int, manyResponse *[]map[string]any, paginatedMeta *map[string]any, c *gin.Context,
+) error {
+	doc, err := newDocument(r.Resource, c)
+	if err != nil {
+		return err
+	}
+	data := make([]any, 0, len(*manyResponse))
+	for _, object := range *manyResponse {
+		resourceObject, err := doc.resourceObject(object)
+		if err != nil {
+			return err
+		}
+		data = append(data, resourceObject)
+	}
+
+	links := map[string]any{"self": selfLink(c)}
+	meta := map[string]any{}
+	if paginatedMeta != nil {
+		for key, value := range *paginatedMeta {
+			switch key {
+			case "next":
+				links["next"] = absoluteLink(value, c)
+			case "previous":
+				links["prev"] = absoluteLink(value, c)
+			default:
+				meta[key] = value
+			}
+		}
+	}
+	return doc.write(statusCode, data, links, meta, c)
+}
+
+type document struct {
+	resource Resource
+	include  map[string]bool
+	included []any
+	seen     map[string]bool
+}
+
+func newDocument(resource Resource, c *gin.Context) (*document, error) {
+	names, err := includes(resource, c)
+	if err != nil {
+		return nil, err
+	}
+	doc := &document{resource: resource, include: map[string]bool{}, seen: map[string]bool{}}
+	for _, name := range names {
+		doc.include[name] = true
+	}
+	return doc, nil
+}
+
+func (doc *document) write(
+	statusCode int, data any, links map[string]any, meta map[string]any, c *gin.Context,
+) error {
+	body := map[string]any{"data": data, "links": links}
+	if len(meta) > 0 {
+		body["meta"] = meta
+	}
+	if len(doc.included) > 0 {
+		body["included"] = doc.included
+	}
+	c.Header("Content-Type", CONTENT_TYPE)
+	c.JSON(statusCode, body)
+	return nil
+}
+
+func (doc *document) resourceObject(object map[string]any) (map[string]any, error) {
+	idField := doc.resource.idField()
+	id, ok := object[idField]
+	if !ok || id == nil {
+		return nil, fmt.Errorf("serialized %s has no %q field", doc.resource.Type, idField)
+	}
+
+	names := make([]string, 0, len(doc.resource.Relationships))
+	relationshipFields := map[string]bool{}
+	for name, relationship := range doc.resource.Relationships {
+		names = append(names, name)
+		relationshipFields[relationship.field(name)] = true
+		if relationship.ForeignKey != "" {
+			relationshipFields[relationship.ForeignKey] = true
+		}
+	}
+	sort.Strings(names)
+
+	attributes := map[string]any{}
+	for key, value := range object {
+		if key != idField && !relationshipFields[key] {
+			attributes[key] = value
+		}
+	}
+	relationships := map[string]any{}
+	for _, name := range names {
+		relationship := doc.resource.Relationships[name]
+		value, ok := object[relationship.field(name)]
+		if (!ok || isNil(value)) && relationship.ForeignKey != "" {
+			if foreignKey, found := object[relationship.ForeignKey]; found {
+				value, ok = foreignKey, true
+			}
+		}
+		if !ok {
+			continue
+		}
+		linkage, err := doc.linkage(name, relationship, value)
+		if err != nil {
+			return nil, err
+		}
+		relationships[name] = map[string]any{"data": linkage}
+	}
+
+	resourceObject := map[string]any{"type": doc.resource.Type, "id": fmt.Sprint(id)}
+	if len(attributes) > 0 {
+		resourceObject["attributes"] = attributes
+	}
+	if len(relationships) > 0 {
+		resourceObject["relationships"] = relationships
+	}
+	return resourceObject, nil
+}
+
+// linkage returns the resource identifiers of a relationship value, which
+// is an id, a related object or a slice of them.
+func (doc *document) linkage(name string, relationship Relationship, value any) (any, error) {
+	if isNil(value) {
+		return nil, nil
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice {
+		return doc.identifier(name, relationship, value)
+	}
+	identifiers := make([]any, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		identifier, err := doc.identifier(name, relationship, rv.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		identifiers = append(identifiers, identifier)
+	}
+	return identifiers, nil
+}
+
+func (doc *document) identifier(name string, relationship Relationship, value any) (any, error) {
+	related, isObject, err := toObject(value)
+	if err != nil {
+		return nil, err
+	}
+	if !isObject {
+		return map[string]any{"type": relationship.Type, "id": fmt.Sprint(value)}, nil
+	}
+
+	idField := relationship.idField()
+	id, ok := related[idField]
+	if !ok || id == nil {
+		return nil, fmt.Errorf("related %s has no %q field", relationship.Type, idField)
+	}
+	identifier := map[string]any{"type": relationship.Type, "id": fmt.Sprint(id)}
+	key := relationship.Type + "/" + fmt.Sprint(id)
+	if doc.include[name] && !doc.seen[key] {
+		doc.seen[key] = true
+		attributes := map[string]any{}
+		for field, fieldValue := range related {
+			if field != idField {
+				attributes[field] = fieldValue
+			}
+		}
+		doc.included = append(doc.included, map[string]any{
+			"type":       relationship.Type,
+			"id":         fmt.Sprint(id),
+			"attributes": attributes,
+		})
+	}
+	return identifier, nil
+}
+
+func isNil(value any) bool {
+	if value == nil {
+		return true
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// toObject converts a related object, as a map or a struct, to a map.
+func toObject(value any) (map[string]any, bool, error) {
+	if object, ok := value.(map[string]any); ok {
+		return object, true, nil
+	}
+	rv := reflect.Indirect(reflect.ValueOf(value))
+	if rv.Kind() != reflect.Struct {
+		return nil, false, nil
+	}
+	object := map[string]any{}
+	if err := mapstructure.Decode(rv.Interface(), &object); err != nil {
+		return nil, false, err
+	}
+	return object, true, nil
+}
+
+func selfLink(c *gin.Context) string {
+	if c.Request == nil || c.Request.URL == nil {
+		return ""
+	}
+	return viewset.AbsoluteURL(c, c.Request.URL.RequestURI())
+}
+
+// absoluteLink completes the relative pagination links of the managers.
+func absoluteLink(value any, c *gin.Context) any {
+	if link, ok := value.(string); ok && strings.HasPrefix(link, "/") {
+		return viewset.AbsoluteURL(c, link)
+	}
+	return value
+}
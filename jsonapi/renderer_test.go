@@ -0,0 +1,88 @@
+package jsonapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestContext(target string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+	return c, w
+}
+
+func TestRendererRender(t *testing.T) {
+	renderer := &Renderer{Resource: Resource{
+		Type:    "articles",
+		IDField: "pk",
+		Relationships: map[string]Relationship{
+			"tags":   {Type: "tags", Field: "tag_list"},
+			"editor": {Type: "people", IDField: "pk"},
+			"author": {Type: "people"},
+		},
+	}}
+	c, w := newTestContext("/articles/1?include=tags,editor")
+	response := map[string]any{
+		"pk":       1,
+		"title":    "first",
+		"author":   9,
+		"editor":   (*author)(nil),
+		"tag_list": []map[string]any{{"id": "go", "label": "Go"}, {"id": "go", "label": "Go"}},
+	}
+
+	assert.NoError(t, renderer.Render(http.StatusOK, &response, c))
+	assert.Equal(t, CONTENT_TYPE, w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"data":{"type":"articles","id":"1","attributes":{"title":"first"},"relationships":{
+			"author":{"data":{"type":"people","id":"9"}},
+			"editor":{"data":null},
+			"tags":{"data":[{"type":"tags","id":"go"},{"type":"tags","id":"go"}]}
+		}},
+		"included":[{"type":"tags","id":"go","attributes":{"label":"Go"}}],
+		"links":{"self":"http://example.com/articles/1?include=tags,editor"}
+	}`, w.Body.String())
+}
+
+func TestRendererRenderMany(t *testing.T) {
+	renderer := &Renderer{Resource: Resource{
+		Type: "people",
+	}}
+	c, w := newTestContext("/people/?offset=1&limit=1")
+	manyResponse := []map[string]any{{"id": 2, "name": "huy"}}
+	paginatedMeta := map[string]any{
+		"count":    int64(3),
+		"next":     "/people/?limit=1&offset=2",
+		"previous": "https://api.example.com/people/?limit=1&offset=0",
+	}
+
+	assert.NoError(t, renderer.RenderMany(http.StatusOK, &manyResponse, &paginatedMeta, c))
+	assert.JSONEq(t, `{
+		"data":[{"type":"people","id":"2","attributes":{"name":"huy"}}],
+		"meta":{"count":3},
+		"links":{
+			"self":"http://example.com/people/?offset=1&limit=1",
+			"next":"http://example.com/people/?limit=1&offset=2",
+			"prev":"https://api.example.com/people/?limit=1&offset=0"
+		}
+	}`, w.Body.String())
+}
+
+func TestRendererWithoutID(t *testing.T) {
+	renderer := &Renderer{Resource: Resource{
+		Type:          "articles",
+		Relationships: map[string]Relationship{"author": {Type: "people"}},
+	}}
+	c, w := newTestContext("/articles/")
+
+	response := map[string]any{"title": "first"}
+	assert.EqualError(t, renderer.Render(http.StatusOK, &response, c), `serialized articles has no "id" field`)
+	response = map[string]any{"id": 1, "author": map[string]any{"name": "phuc"}}
+	assert.EqualError(t, renderer.Render(http.StatusOK, &response, c), `related people has no "id" field`)
+	assert.Equal(t, 0, w.Body.Len())
+}
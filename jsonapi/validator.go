@@ -0,0 +1,246 @@
+package jsonapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/TcMits/viewset"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+var _ viewset.FormValidator[any, any] = &Validator[any, any]{}
+
+type resourceObjectBody struct {
+	Type          string                      `json:"type"`
+	ID            string                      `json:"id"`
+	Attributes    map[string]json.RawMessage  `json:"attributes"`
+	Relationships map[string]relationshipBody `json:"relationships"`
+}
+
+type relationshipBody struct {
+	Data json.RawMessage `json:"data"`
+}
+
+type identifierBody struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// Validator parses a JSON:API request body into a flat JSON object of the
+// attributes and the relationship ids, then lets FormValidator bind and
+// validate it into ValidateType.
+type Validator[EntityType, ValidateType any] struct {
+	Resource      Resource
+	FormValidator viewset.FormValidator[EntityType, ValidateType]
+}
+
+func (v *Validator[EntityType, ValidateType]) Validate(
+	dest *ValidateType, entity *EntityType, c *gin.Context,
+) error {
+	if c.Request == nil || c.Request.Body == nil {
+		return invalidDocument("missing request body", "")
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return err
+	}
+	flattened, err := v.parse(body)
+	if err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(flattened)
+	if err != nil {
+		return err
+	}
+
+	contentType := c.Request.Header.Get("Content-Type")
+	c.Request.Header.Set("Content-Type", binding.MIMEJSON)
+	c.Request.Body = io.NopCloser(bytes.NewReader(encoded))
+	defer func() {
+		c.Request.Header.Set("Content-Type", contentType)
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	}()
+
+	var formValidator viewset.FormValidator[EntityType, ValidateType] = v.FormValidator
+	if formValidator == nil {
+		formValidator = &viewset.DefaultValidator[EntityType, ValidateType]{}
+	}
+	if err := formValidator.Validate(dest, entity, c); err != nil {
+		return v.convertError(err)
+	}
+	return nil
+}
+
+func (v *Validator[_, ValidateType]) parse(body []byte) (map[string]json.RawMessage, error) {
+	doc := struct {
+		Data *resourceObjectBody `json:"data"`
+	}{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, invalidDocument(err.Error(), "")
+	}
+	if doc.Data == nil {
+		return nil, invalidDocument("missing primary data", "/data")
+	}
+	if doc.Data.Type != v.Resource.Type {
+		return nil, typeConflict(doc.Data.Type, v.Resource.Type, "/data/type")
+	}
+
+	flattened := map[string]json.RawMessage{}
+	for key, value := range doc.Data.Attributes {
+		flattened[key] = value
+	}
+	validateType := reflect.TypeOf((*ValidateType)(nil)).Elem()
+	for name, linkage := range doc.Data.Relationships {
+		pointer := "/data/relationships/" + name
+		relationship, ok := v.Resource.Relationships[name]
+		if !ok {
+			return nil, invalidDocument(fmt.Sprintf("unknown relationship %q", name), pointer)
+		}
+		if len(linkage.Data) == 0 {
+			return nil, invalidDocument("missing relationship data", pointer+"/data")
+		}
+		field := relationship.inputField(name)
+		numeric := isNumericField(validateType, field)
+		if string(linkage.Data) == "null" {
+			flattened[field] = linkage.Data
+			continue
+		}
+
+		identifiers := []identifierBody{}
+		if relationship.ToMany {
+			if err := json.Unmarshal(linkage.Data, &identifiers); err != nil {
+				return nil, invalidDocument(err.Error(), pointer+"/data")
+			}
+		} else {
+			identifier := identifierBody{}
+			if err := json.Unmarshal(linkage.Data, &identifier); err != nil {
+				return nil, invalidDocument(err.Error(), pointer+"/data")
+			}
+			identifiers = append(identifiers, identifier)
+		}
+		ids := make([]any, 0, len(identifiers))
+		for _, identifier := range identifiers {
+			if identifier.Type != relationship.Type {
+				return nil, typeConflict(identifier.Type, relationship.Type, pointer+"/data")
+			}
+			if numeric {
+				ids = append(ids, json.Number(identifier.ID))
+			} else {
+				ids = append(ids, identifier.ID)
+			}
+		}
+		var value any = ids
+		if !relationship.ToMany {
+			value = ids[0]
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, invalidDocument(err.Error(), pointer+"/data")
+		}
+		flattened[field] = encoded
+	}
+	return flattened, nil
+}
+
+// convertError points validation errors to the attribute or the
+// relationship they come from.
+func (v *Validator[_, ValidateType]) convertError(err error) error {
+	validateType := reflect.TypeOf((*ValidateType)(nil)).Elem()
+	validationErrs := validator.ValidationErrors{}
+	if errors.As(err, &validationErrs) {
+		errs := make(Errors, 0, len(validationErrs))
+		for _, fieldErr := range validationErrs {
+			name := jsonName(validateType, fieldErr.StructField())
+			title, pointer := v.source(name)
+			errs = append(errs, Error{
+				Code:   fieldErr.Tag(),
+				Title:  title,
+				Detail: fmt.Sprintf("%s failed on the '%s' rule", name, fieldErr.Tag()),
+				Source: &ErrorSource{Pointer: pointer},
+			})
+		}
+		return errs
+	}
+	typeErr := new(json.UnmarshalTypeError)
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		title, pointer := v.source(strings.SplitN(typeErr.Field, ".", 2)[0])
+		return Errors{{Title: title, Detail: err.Error(), Source: &ErrorSource{Pointer: pointer}}}
+	}
+	return err
+}
+
+func (v *Validator[_, _]) source(field string) (string, string) {
+	for name, relationship := range v.Resource.Relationships {
+		if relationship.inputField(name) == field {
+			return "Invalid Relationship", "/data/relationships/" + name
+		}
+	}
+	return "Invalid Attribute", "/data/attributes/" + field
+}
+
+func invalidDocument(detail string, pointer string) Errors {
+	errorObject := Error{Title: "Invalid Document", Detail: detail}
+	if pointer != "" {
+		errorObject.Source = &ErrorSource{Pointer: pointer}
+	}
+	return Errors{errorObject}
+}
+
+func typeConflict(got string, expected string, pointer string) error {
+	detail := fmt.Sprintf("type %q does not match %q", got, expected)
+	return viewset.NewViewSetError(detail, http.StatusConflict, Errors{{
+		Title:  "Type Conflict",
+		Detail: detail,
+		Source: &ErrorSource{Pointer: pointer},
+	}})
+}
+
+// jsonName returns the key of a struct field in JSON documents.
+func jsonName(structType reflect.Type, fieldName string) string {
+	if structType.Kind() != reflect.Struct {
+		return fieldName
+	}
+	field, ok := structType.FieldByName(fieldName)
+	if !ok {
+		return fieldName
+	}
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" || name == "-" {
+		return fieldName
+	}
+	return name
+}
+
+// isNumericField reports whether the JSON key of ValidateType holds numbers,
+// JSON:API ids are strings but models often use integer keys.
+func isNumericField(structType reflect.Type, key string) bool {
+	if structType.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if jsonName(structType, field.Name) != key {
+			continue
+		}
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer || fieldType.Kind() == reflect.Slice {
+			fieldType = fieldType.Elem()
+		}
+		switch fieldType.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			return true
+		}
+		return false
+	}
+	return false
+}
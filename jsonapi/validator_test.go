@@ -0,0 +1,82 @@
+package jsonapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/TcMits/viewset"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type postRequest struct {
+	Title    string   `json:"title" binding:"required"`
+	Age      int      `json:"age"`
+	TagIDs   []string `json:"tag_ids"`
+	AuthorID *uint    `json:"author_id"`
+}
+
+var postValidator = &Validator[struct{}, postRequest]{Resource: Resource{
+	Type: "posts",
+	Relationships: map[string]Relationship{
+		"tags":   {Type: "tags", ForeignKey: "tag_ids", ToMany: true},
+		"author": {Type: "people", ForeignKey: "author_id"},
+	},
+}}
+
+func newBodyContext(body string) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/posts/", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", CONTENT_TYPE)
+	return c
+}
+
+func TestValidatorValidate(t *testing.T) {
+	c := newBodyContext(`{"data":{"type":"posts","attributes":{"title":"first","age":2},"relationships":{
+		"tags":{"data":[{"type":"tags","id":"go"},{"type":"tags","id":"gin"}]},
+		"author":{"data":{"type":"people","id":"9"}}}}}`)
+	dest := new(postRequest)
+
+	assert.NoError(t, postValidator.Validate(dest, nil, c))
+	authorID := uint(9)
+	assert.Equal(t, postRequest{Title: "first", Age: 2, TagIDs: []string{"go", "gin"}, AuthorID: &authorID}, *dest)
+	assert.Equal(t, CONTENT_TYPE, c.Request.Header.Get("Content-Type"))
+
+	c = newBodyContext(`{"data":{"type":"posts","attributes":{"title":"first"},"relationships":{
+		"author":{"data":null}}}}`)
+	dest = new(postRequest)
+	assert.NoError(t, postValidator.Validate(dest, nil, c))
+	assert.Nil(t, dest.AuthorID)
+}
+
+func TestValidatorValidateWithInvalidDocument(t *testing.T) {
+	cases := []struct {
+		body    string
+		pointer string
+	}{
+		{`{"data":`, ""},
+		{`{"meta":{}}`, "/data"},
+		{`{"data":{"type":"posts","relationships":{"editor":{"data":null}}}}`, "/data/relationships/editor"},
+		{`{"data":{"type":"posts","relationships":{"author":{}}}}`, "/data/relationships/author/data"},
+		{`{"data":{"type":"posts","relationships":{"tags":{"data":{"type":"tags","id":"go"}}}}}`, "/data/relationships/tags/data"},
+		{`{"data":{"type":"posts","attributes":{"title":"first","age":"two"}}}`, "/data/attributes/age"},
+	}
+	for _, tc := range cases {
+		err := postValidator.Validate(new(postRequest), nil, newBodyContext(tc.body))
+		errs, ok := err.(Errors)
+		if assert.True(t, ok, tc.body) && tc.pointer != "" {
+			assert.Equal(t, tc.pointer, errs[0].Source.Pointer, tc.body)
+		}
+	}
+
+	err := postValidator.Validate(new(postRequest), nil, newBodyContext(
+		`{"data":{"type":"posts","relationships":{"author":{"data":{"type":"tags","id":"go"}}}}}`,
+	))
+	viewSetErr, ok := err.(*viewset.ViewSetError)
+	if assert.True(t, ok) {
+		assert.Equal(t, http.StatusConflict, viewSetErr.StatusCode)
+	}
+}
@@ -0,0 +1,88 @@
+package viewset
+
+import (
+	"github.com/TcMits/viewset/pkg/i18n"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	LOCALE_CONTEXT_KEY  = "github.com/TcMits/viewset.locale"
+	CATALOG_CONTEXT_KEY = "github.com/TcMits/viewset.catalog"
+)
+
+var defaultCatalog = i18n.NewCatalog()
+
+// Localize makes viewsets write their messages from catalog, in the locale
+// preferred by the Accept-Language header of the request.
+func Localize(catalog i18n.Catalog) gin.HandlerFunc {
+	if catalog == nil {
+		panic("catalog is required")
+	}
+	return func(c *gin.Context) {
+		c.Set(CATALOG_CONTEXT_KEY, catalog)
+		locale := Locale(c)
+		c.Set(LOCALE_CONTEXT_KEY, locale)
+		c.Header("Content-Language", locale)
+		// caches must not serve a response in another language
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		c.Next()
+	}
+}
+
+// SetLocale overrides the locale of the rest of the request, e.g. with the
+// language of the current user.
+func SetLocale(c *gin.Context, locale string) {
+	c.Set(LOCALE_CONTEXT_KEY, locale)
+}
+
+// Locale returns the locale set by SetLocale or Localize, else the one of
+// the Accept-Language header supported by the catalog, else English.
+func Locale(c *gin.Context) string {
+	if locale, ok := c.Value(LOCALE_CONTEXT_KEY).(string); ok && locale != "" {
+		return locale
+	}
+	if c.Request == nil {
+		return i18n.DEFAULT_LOCALE
+	}
+	if locale := i18n.Match(c.GetHeader("Accept-Language"), catalog(c).Locales()); locale != "" {
+		return locale
+	}
+	return i18n.DEFAULT_LOCALE
+}
+
+func catalog(c *gin.Context) i18n.Catalog {
+	if catalog, ok := c.Value(CATALOG_CONTEXT_KEY).(i18n.Catalog); ok {
+		return catalog
+	}
+	return defaultCatalog
+}
+
+// message returns the first of keys found in the locale of the request, else
+// in English.
+func message(c *gin.Context, keys []string, params ...string) (string, bool) {
+	requestCatalog := catalog(c)
+	locale := Locale(c)
+	for _, key := range keys {
+		if text, ok := requestCatalog.Message(locale, key, params...); ok {
+			return text, true
+		}
+	}
+	for _, key := range keys {
+		if text, ok := requestCatalog.Message(i18n.DEFAULT_LOCALE, key, params...); ok {
+			return text, true
+		}
+		if text, ok := defaultCatalog.Message(i18n.DEFAULT_LOCALE, key, params...); ok {
+			return text, true
+		}
+	}
+	return "", false
+}
+
+// translate returns the message of key for the request, the key itself if
+// no catalog has it.
+func translate(c *gin.Context, key string, params ...string) string {
+	if text, ok := message(c, []string{key}, params...); ok {
+		return text
+	}
+	return key
+}
@@ -0,0 +1,143 @@
+package viewset
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/TcMits/viewset/manager"
+	"github.com/TcMits/viewset/pkg/i18n"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type testNote struct {
+	ID    uint   `mapstructure:"id" viewset:"read_only"`
+	Title string `mapstructure:"title"`
+}
+
+type testNoteRequest struct {
+	Title string `json:"title" mapstructure:"title" binding:"required,min=3"`
+}
+
+type testNoteURI struct {
+	ID uint `uri:"pk"`
+}
+
+type testDenyPermission struct{}
+
+func (_ *testDenyPermission) Check(_ string, c *gin.Context) error {
+	if c.GetHeader("X-Denied") != "" {
+		return fmt.Errorf("notes: %w", ErrPermissionDenied)
+	}
+	return nil
+}
+
+func newTestNoteRouter(catalog i18n.Catalog) *gin.Engine {
+	noteManager := manager.NewMemoryManager[testNote, testNoteRequest, testNoteURI]("ID")
+	viewSet := NewViewSet[testNote, testNoteRequest](
+		"/notes", "/:pk", nil, nil, noteManager, nil, &testDenyPermission{}, nil, nil,
+	)
+	router := SetUpRouter()
+	if catalog != nil {
+		router.Use(Localize(catalog))
+	}
+	viewSet.Register(router)
+	return router
+}
+
+func serveTestNote(router *gin.Engine, language string, denied bool, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/notes/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", language)
+	if denied {
+		req.Header.Set("X-Denied", "1")
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestLocalizeTranslatesMessages(t *testing.T) {
+	catalog := i18n.NewCatalog().Add("vi", map[string]string{
+		"error.permission_denied":    "không có quyền",
+		"validation.required":        "Trường này là bắt buộc.",
+		"validation.min.string":      "Tối thiểu {0} ký tự.",
+		"validation.invalid_json":    "JSON không hợp lệ.",
+		"validation.invalid_json_at": "JSON không hợp lệ tại vị trí {0}.",
+	})
+	router := newTestNoteRouter(catalog)
+
+	w := serveTestNote(router, "vi-VN,vi;q=0.9,en;q=0.8", false, `{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "vi", w.Header().Get("Content-Language"))
+	assert.Equal(t, "Accept-Language", w.Header().Get("Vary"))
+	assert.Equal(t, `{"errors":{"title":[{"code":"required","message":"Trường này là bắt buộc."}]}}`, w.Body.String())
+
+	w = serveTestNote(router, "vi", false, `{"title":"ab"}`)
+	assert.Equal(t, `{"errors":{"title":[{"code":"min","message":"Tối thiểu 3 ký tự."}]}}`, w.Body.String())
+
+	w = serveTestNote(router, "vi", true, `{"title":"abc"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, `{"message":"không có quyền"}`, w.Body.String())
+
+	w = serveTestNote(router, "fr, en;q=0.5", true, `{"title":"abc"}`)
+	assert.Equal(t, "en", w.Header().Get("Content-Language"))
+	assert.Equal(t, `{"message":"permission denied"}`, w.Body.String())
+
+	w = serveTestNote(router, "vi", false, `{"title":"abc"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+func TestLocalizeFallsBackToEnglish(t *testing.T) {
+	// vi misses the min message, the English one is used
+	router := newTestNoteRouter(i18n.NewCatalog().Add("vi", map[string]string{}))
+	w := serveTestNote(router, "vi", false, `{"title":"ab"}`)
+	assert.Equal(t, `{"errors":{"title":[{"code":"min","message":"Must be at least 3 characters."}]}}`, w.Body.String())
+
+	// without Localize the built-in English catalog is used
+	router = newTestNoteRouter(nil)
+	w = serveTestNote(router, "vi", false, `{}`)
+	assert.Equal(t, `{"errors":{"title":[{"code":"required","message":"This field is required."}]}}`, w.Body.String())
+}
+
+func TestLocaleAndTranslate(t *testing.T) {
+	c := newTestFieldContext("/notes/")
+	c.Request.Header.Set("Accept-Language", "vi")
+	assert.Equal(t, "en", Locale(c))
+	assert.Equal(t, "unknown expansion: author", translate(c, "error.unknown_expansion", "author"))
+	assert.Equal(t, "error.missing", translate(c, "error.missing"))
+
+	c.Set(CATALOG_CONTEXT_KEY, i18n.NewCatalog().Add("vi", map[string]string{"error.unknown_timezone": "múi giờ không hợp lệ: {0}"}))
+	assert.Equal(t, "vi", Locale(c))
+	c.Request.Header.Set(TIMEZONE_HEADER, "Mars/Olympus")
+	_, err := Timezone(c)
+	assert.EqualError(t, err, "múi giờ không hợp lệ: Mars/Olympus")
+	assert.ErrorIs(t, err, ErrUnknownTimezone)
+
+	SetLocale(c, "en")
+	assert.Equal(t, "en", Locale(c))
+	assert.Equal(t, "unknown timezone: UTC", translate(c, "error.unknown_timezone", "UTC"))
+
+	assert.Panics(t, func() { Localize(nil) })
+}
+
+func TestNewLocalizedError(t *testing.T) {
+	errOutOfStock := errors.New("out of stock")
+	c := newTestFieldContext("/notes/")
+	c.Request.Header.Set("Accept-Language", "vi")
+	c.Set(CATALOG_CONTEXT_KEY, i18n.NewCatalog().Add("vi", map[string]string{"error.out_of_stock": "hết hàng: {0}"}))
+
+	err := NewLocalizedError(c, "out_of_stock", http.StatusConflict, errOutOfStock, "pen")
+	assert.EqualError(t, err, "hết hàng: pen")
+	assert.Equal(t, "out_of_stock", err.Code)
+	assert.Equal(t, http.StatusConflict, err.StatusCode)
+	assert.ErrorIs(t, err, errOutOfStock)
+
+	assert.EqualError(t, NewLocalizedError(c, "sold_out", http.StatusConflict, errOutOfStock), "out of stock")
+	assert.EqualError(t, NewLocalizedError(c, "sold_out", http.StatusConflict, nil), "sold_out")
+	assert.EqualError(t, NewLocalizedError(c, "permission_denied", http.StatusForbidden, nil), "permission denied")
+}
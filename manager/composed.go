@@ -0,0 +1,92 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+var _ Manager[any, any] = &ComposedManager[any, any]{}
+
+var ErrNotSupported = errors.New("operation not supported")
+
+// ComposedManager builds a Manager out of partial implementations, missing
+// operations return ErrNotSupported.
+type ComposedManager[EntityType, ValidateType any] struct {
+	ObjectGetter  ObjectGetter[EntityType]
+	ObjectsGetter ObjectsGetter[EntityType]
+	Saver         Saver[EntityType, ValidateType]
+	Deleter       Deleter[EntityType]
+}
+
+// NewComposedManager takes the operations partial implements, it panics if
+// partial implements none of them for EntityType and ValidateType.
+func NewComposedManager[EntityType, ValidateType any](
+	partial any,
+) *ComposedManager[EntityType, ValidateType] {
+	composed := &ComposedManager[EntityType, ValidateType]{}
+	composed.ObjectGetter, _ = partial.(ObjectGetter[EntityType])
+	composed.ObjectsGetter, _ = partial.(ObjectsGetter[EntityType])
+	composed.Saver, _ = partial.(Saver[EntityType, ValidateType])
+	composed.Deleter, _ = partial.(Deleter[EntityType])
+	if composed.ObjectGetter == nil && composed.ObjectsGetter == nil && composed.Saver == nil && composed.Deleter == nil {
+		panic(fmt.Sprintf("%T implements no manager operation", partial))
+	}
+	return composed
+}
+
+func (manager *ComposedManager[EntityType, _]) GetObject(dest **EntityType, c *gin.Context) error {
+	if manager.ObjectGetter == nil {
+		return ErrNotSupported
+	}
+	return manager.ObjectGetter.GetObject(dest, c)
+}
+
+func (manager *ComposedManager[EntityType, _]) GetObjects(
+	dest *[]*EntityType, paginatedMeta *map[string]any, c *gin.Context) error {
+	if manager.ObjectsGetter == nil {
+		return ErrNotSupported
+	}
+	return manager.ObjectsGetter.GetObjects(dest, paginatedMeta, c)
+}
+
+func (manager *ComposedManager[EntityType, ValidateType]) Save(
+	dest **EntityType, validatedData *ValidateType, c *gin.Context) error {
+	if manager.Saver == nil {
+		return ErrNotSupported
+	}
+	return manager.Saver.Save(dest, validatedData, c)
+}
+
+func (manager *ComposedManager[EntityType, _]) Delete(dest **EntityType, c *gin.Context) error {
+	if manager.Deleter == nil {
+		return ErrNotSupported
+	}
+	return manager.Deleter.Delete(dest, c)
+}
+
+func (manager *ComposedManager[_, _]) IsAtomic(c *gin.Context) bool {
+	if txManager := manager.transactional(); txManager != nil {
+		return txManager.IsAtomic(c)
+	}
+	return false
+}
+
+func (manager *ComposedManager[_, _]) Atomic(c *gin.Context, fn func() error) error {
+	if txManager := manager.transactional(); txManager != nil {
+		return txManager.Atomic(c, fn)
+	}
+	return fn()
+}
+
+// transactional returns the first part running transactions, nil if there
+// is none.
+func (manager *ComposedManager[_, _]) transactional() TransactionalManager {
+	for _, part := range []any{manager.Saver, manager.Deleter, manager.ObjectGetter, manager.ObjectsGetter} {
+		if txManager, ok := part.(TransactionalManager); ok {
+			return txManager
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package manager
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type personGetter struct{}
+
+func (_ *personGetter) GetObject(dest **person, _ *gin.Context) error {
+	*dest = &person{ID: 1, Name: "phuc"}
+	return nil
+}
+
+func TestComposedManagerNotSupported(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	composed := NewComposedManager[person, personRequest](&personGetter{})
+
+	entity := new(person)
+	entities := []*person{}
+	paginatedMeta := map[string]any{}
+
+	assert.NoError(t, composed.GetObject(&entity, c))
+	assert.Equal(t, "phuc", entity.Name)
+	assert.Equal(t, ErrNotSupported, composed.GetObjects(&entities, &paginatedMeta, c))
+	assert.Equal(t, ErrNotSupported, composed.Save(&entity, &personRequest{}, c))
+	assert.Equal(t, ErrNotSupported, composed.Delete(&entity, c))
+	assert.False(t, composed.IsAtomic(c))
+	called := false
+	assert.NoError(t, composed.Atomic(c, func() error {
+		called = true
+		return nil
+	}))
+	assert.Equal(t, true, called)
+
+	assert.Panics(t, func() { NewComposedManager[person, personRequest](nil) })
+	assert.Panics(t, func() { NewComposedManager[person, personRequest](&struct{}{}) })
+}
+
+func TestComposedManagerDelegates(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/", nil)
+	memoryManager := NewMemoryManager[person, personRequest, personURI]("ID")
+	composed := NewComposedManager[person, personRequest](memoryManager)
+
+	var entity *person
+	entities := []*person{}
+	paginatedMeta := map[string]any{}
+
+	assert.NoError(t, composed.Save(&entity, &personRequest{Name: "phuc"}, c))
+	assert.NoError(t, composed.GetObjects(&entities, &paginatedMeta, c))
+	assert.Equal(t, 1, len(entities))
+	assert.NoError(t, composed.Delete(&entity, c))
+	assert.Equal(t, 0, len(memoryManager.entities))
+}
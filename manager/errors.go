@@ -0,0 +1,123 @@
+package manager
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"strings"
+	"syscall"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrObjectNotFound    = errors.New("object not found")
+	ErrDuplicatedPrimary = errors.New("duplicated primary key")
+
+	// managers may wrap these to classify their own errors
+	ErrConflict            = errors.New("conflict")
+	ErrForeignKeyViolation = errors.New("foreign key violation")
+	ErrCheckViolation      = errors.New("check violation")
+	ErrTimeout             = errors.New("timeout")
+	ErrUnavailable         = errors.New("unavailable")
+	ErrInvalid             = errors.New("invalid data")
+)
+
+type ErrorKind int
+
+const (
+	UNKNOWN_ERROR ErrorKind = iota
+	NOT_FOUND_ERROR
+	CONFLICT_ERROR // duplicate key
+	FOREIGN_KEY_ERROR
+	CHECK_ERROR // check or not null constraint
+	TIMEOUT_ERROR
+	UNAVAILABLE_ERROR // the database cannot be reached
+	INVALID_ERROR     // the request cannot be written, like a missing child
+)
+
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrObjectNotFound) || errors.Is(err, gorm.ErrRecordNotFound)
+}
+
+// Classify tells what went wrong from the errors of GORM, database/sql and
+// the SQLite and Postgres drivers, without depending on the drivers.
+func Classify(err error) ErrorKind {
+	switch {
+	case err == nil:
+		return UNKNOWN_ERROR
+	case IsNotFound(err), errors.Is(err, sql.ErrNoRows):
+		return NOT_FOUND_ERROR
+	case errors.Is(err, ErrConflict), errors.Is(err, ErrDuplicatedPrimary):
+		return CONFLICT_ERROR
+	case errors.Is(err, ErrForeignKeyViolation):
+		return FOREIGN_KEY_ERROR
+	case errors.Is(err, ErrCheckViolation):
+		return CHECK_ERROR
+	case errors.Is(err, ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return TIMEOUT_ERROR
+	case errors.Is(err, ErrUnavailable),
+		errors.Is(err, driver.ErrBadConn),
+		errors.Is(err, sql.ErrConnDone),
+		errors.Is(err, syscall.ECONNREFUSED),
+		errors.Is(err, syscall.ECONNRESET):
+		return UNAVAILABLE_ERROR
+	case errors.Is(err, ErrInvalid), errors.Is(err, ErrUnknownChild), errors.Is(err, ErrMissingChild):
+		return INVALID_ERROR
+	}
+
+	var stateErr interface{ SQLState() string }
+	if errors.As(err, &stateErr) {
+		return classifySQLState(stateErr.SQLState())
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return TIMEOUT_ERROR
+		}
+		return UNAVAILABLE_ERROR
+	}
+	return classifySQLiteMessage(err.Error())
+}
+
+// classifySQLState reads the SQLSTATE of a Postgres error.
+func classifySQLState(state string) ErrorKind {
+	switch state {
+	case "23505":
+		return CONFLICT_ERROR
+	case "23503":
+		return FOREIGN_KEY_ERROR
+	case "23514", "23502":
+		return CHECK_ERROR
+	case "57014", "55P03": // query_canceled, lock_not_available
+		return TIMEOUT_ERROR
+	case "53300", "57P01", "57P02", "57P03":
+		return UNAVAILABLE_ERROR
+	}
+	if strings.HasPrefix(state, "08") { // connection_exception
+		return UNAVAILABLE_ERROR
+	}
+	return UNKNOWN_ERROR
+}
+
+// classifySQLiteMessage reads the messages of SQLite, which are the same
+// for the cgo and the pure Go drivers.
+func classifySQLiteMessage(message string) ErrorKind {
+	switch {
+	case strings.Contains(message, "UNIQUE constraint failed"):
+		return CONFLICT_ERROR
+	case strings.Contains(message, "FOREIGN KEY constraint failed"):
+		return FOREIGN_KEY_ERROR
+	case strings.Contains(message, "CHECK constraint failed"),
+		strings.Contains(message, "NOT NULL constraint failed"):
+		return CHECK_ERROR
+	case strings.Contains(message, "database is locked"),
+		strings.Contains(message, "database table is locked"):
+		return TIMEOUT_ERROR
+	case strings.Contains(message, "unable to open database file"):
+		return UNAVAILABLE_ERROR
+	}
+	return UNKNOWN_ERROR
+}
@@ -0,0 +1,102 @@
+package manager
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestIsNotFound(t *testing.T) {
+	assert.True(t, IsNotFound(ErrObjectNotFound))
+	assert.True(t, IsNotFound(gorm.ErrRecordNotFound))
+	assert.True(t, IsNotFound(fmt.Errorf("get profile: %w", gorm.ErrRecordNotFound)))
+	assert.False(t, IsNotFound(ErrDuplicatedPrimary))
+	assert.False(t, IsNotFound(errors.New("object not found")))
+	assert.False(t, IsNotFound(nil))
+}
+
+type testTimeoutError struct{}
+
+func (testTimeoutError) Error() string   { return "i/o timeout" }
+func (testTimeoutError) Timeout() bool   { return true }
+func (testTimeoutError) Temporary() bool { return true }
+
+func TestClassify(t *testing.T) {
+	for _, test := range []struct {
+		err  error
+		kind ErrorKind
+	}{
+		{nil, UNKNOWN_ERROR},
+		{errors.New("boom"), UNKNOWN_ERROR},
+		{fmt.Errorf("get: %w", gorm.ErrRecordNotFound), NOT_FOUND_ERROR},
+		{sql.ErrNoRows, NOT_FOUND_ERROR},
+		{ErrDuplicatedPrimary, CONFLICT_ERROR},
+		{fmt.Errorf("%w: email is taken", ErrConflict), CONFLICT_ERROR},
+		{ErrForeignKeyViolation, FOREIGN_KEY_ERROR},
+		{ErrCheckViolation, CHECK_ERROR},
+		{fmt.Errorf("query: %w", context.DeadlineExceeded), TIMEOUT_ERROR},
+		{&net.OpError{Op: "read", Err: testTimeoutError{}}, TIMEOUT_ERROR},
+		{driver.ErrBadConn, UNAVAILABLE_ERROR},
+		{sql.ErrConnDone, UNAVAILABLE_ERROR},
+		{&net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, UNAVAILABLE_ERROR},
+		{&net.OpError{Op: "dial", Err: errors.New("no route to host")}, UNAVAILABLE_ERROR},
+		{sqlStateError("23505"), CONFLICT_ERROR},
+		{fmt.Errorf("save: %w", sqlStateError("23503")), FOREIGN_KEY_ERROR},
+		{sqlStateError("23514"), CHECK_ERROR},
+		{sqlStateError("23502"), CHECK_ERROR},
+		{sqlStateError("57014"), TIMEOUT_ERROR},
+		{sqlStateError("08006"), UNAVAILABLE_ERROR},
+		{sqlStateError("57P01"), UNAVAILABLE_ERROR},
+		{sqlStateError("42601"), UNKNOWN_ERROR},
+		{errors.New("database is locked"), TIMEOUT_ERROR},
+		{errors.New("unable to open database file: no such file or directory"), UNAVAILABLE_ERROR},
+	} {
+		assert.Equal(t, test.kind, Classify(test.err), "%v", test.err)
+	}
+}
+
+type testClassifyParent struct {
+	ID uint
+}
+
+type testClassifyChild struct {
+	ID       uint
+	Email    string `gorm:"uniqueIndex;not null"`
+	Age      int    `gorm:"check:age >= 0"`
+	ParentID uint
+	Parent   testClassifyParent
+}
+
+func TestClassifySQLiteErrors(t *testing.T) {
+	db, err := gorm.Open(
+		sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared&_foreign_keys=1", t.Name())),
+		&gorm.Config{Logger: logger.Default.LogMode(logger.Silent)},
+	)
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&testClassifyParent{}, &testClassifyChild{}))
+	require.NoError(t, db.Create(&testClassifyParent{ID: 1}).Error)
+	require.NoError(t, db.Create(&testClassifyChild{Email: "a@example.com", ParentID: 1}).Error)
+
+	err = db.Create(&testClassifyChild{Email: "a@example.com", ParentID: 1}).Error
+	assert.Equal(t, CONFLICT_ERROR, Classify(err), "%v", err)
+	err = db.Omit("Parent").Create(&testClassifyChild{Email: "b@example.com", ParentID: 2}).Error
+	assert.Equal(t, FOREIGN_KEY_ERROR, Classify(err), "%v", err)
+	err = db.Create(&testClassifyChild{Email: "c@example.com", Age: -1, ParentID: 1}).Error
+	assert.Equal(t, CHECK_ERROR, Classify(err), "%v", err)
+	err = db.Exec("INSERT INTO test_classify_children (age, parent_id) VALUES (1, 1)").Error
+	assert.Equal(t, CHECK_ERROR, Classify(err), "%v", err)
+	err = db.First(&testClassifyChild{}, 42).Error
+	assert.Equal(t, NOT_FOUND_ERROR, Classify(err), "%v", err)
+}
@@ -0,0 +1,34 @@
+package manager
+
+import (
+	"github.com/TcMits/viewset/pkg/fieldrule"
+	"github.com/gin-gonic/gin"
+)
+
+const FIELD_RULES_CONTEXT_KEY = "github.com/TcMits/viewset/manager.field_rules"
+
+// SetFieldRules makes the manager filter the writes of the rest of the
+// request with the given rules, merged over those EntityType declares, e.g.
+// the rules of the serializer.
+func SetFieldRules(c *gin.Context, rules fieldrule.Rules) {
+	c.Set(FIELD_RULES_CONTEXT_KEY, rules)
+}
+
+// FieldRules returns the rules set by SetFieldRules.
+func FieldRules(c *gin.Context) fieldrule.Rules {
+	rules, _ := c.Value(FIELD_RULES_CONTEXT_KEY).(fieldrule.Rules)
+	return rules
+}
+
+// FilterWritable removes the keys which must not be assigned to an
+// EntityType, by its rules and those of the request, then the hidden keys of
+// the request. The default create and update funcs call it, custom ones must
+// call it too.
+func FilterWritable[EntityType any](values map[string]any, c *gin.Context) {
+	rules := fieldrule.For[EntityType]()
+	if c != nil {
+		rules = rules.Merge(FieldRules(c))
+	}
+	rules.FilterWritable(values)
+	FilterHidden(values, c)
+}
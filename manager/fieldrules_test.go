@@ -0,0 +1,30 @@
+package manager
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TcMits/viewset/pkg/fieldrule"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterWritable(t *testing.T) {
+	type account struct {
+		ID    uint   `mapstructure:"id" viewset:"read_only"`
+		Name  string `mapstructure:"name"`
+		Email string `mapstructure:"email"`
+		Role  string `mapstructure:"role"`
+	}
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	values := map[string]any{"id": 1, "name": "phuc", "Email": "phuc@example.com", "role": "admin"}
+
+	assert.Nil(t, FieldRules(c))
+	SetFieldRules(c, fieldrule.Rules{"role": {ReadOnly: true}})
+	SetHiddenFields(c, "email")
+	FilterWritable[account](values, c)
+
+	assert.Equal(t, map[string]any{"name": "phuc"}, values)
+	FilterWritable[account](map[string]any{}, nil)
+}
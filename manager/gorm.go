@@ -1,8 +1,12 @@
 package manager
 
 import (
+	"errors"
+	"fmt"
+	"sort"
 	"strconv"
 
+	"github.com/TcMits/viewset/pkg/fieldset"
 	"github.com/TcMits/viewset/pkg/urlclone"
 	"github.com/gin-gonic/gin"
 	"github.com/mitchellh/mapstructure"
@@ -10,9 +14,17 @@ import (
 )
 
 var _ Manager[any, any] = &GormManager[any, any, any]{}
+var _ TransactionalManager = &GormManager[any, any, any]{}
+var _ SingletonManager[any, any] = &GormManager[any, any, any]{}
+var _ SingletonCreator[any, any] = &GormManager[any, any, any]{}
+
+var ErrSingletonScopeNotSet = errors.New("singleton scope is not set")
 
 type GormScopeGenerator func(c *gin.Context) func(*gorm.DB) *gorm.DB
 type GormPaginateFunc[EntityType any] func(*[]*EntityType, *map[string]any, *gorm.DB, *gin.Context) error
+
+// GormCreateFunc and GormUpdateFunc must leave out the keys of HiddenFields,
+// see FilterHidden.
 type GormCreateFunc[EntityType, ValidateType any] func(**EntityType, *ValidateType, *gorm.DB, *gin.Context) error
 type GormUpdateFunc[EntityType, ValidateType any] func(**EntityType, *ValidateType, *gorm.DB, *gin.Context) error
 type GormDeleteFunc[EntityType, ValidateType any] func(**EntityType, *gorm.DB, *gin.Context) error
@@ -31,6 +43,14 @@ type GormManager[EntityType any, ValidateType, URIType any] struct {
 	performUpdateFunc GormUpdateFunc[EntityType, ValidateType]
 	performDeleteFunc GormDeleteFunc[EntityType, ValidateType]
 	ginContextKey     string
+	atomic            bool
+	atomicMaxRetries  uint
+	singletonScope    GormScopeGenerator
+	singletonOwner    SingletonOwnerFunc
+	lookupFields      []GormLookupField
+	selectColumns     map[string]string
+	alwaysSelected    []string
+	nestedFields      []GormNestedField
 }
 
 func NewGormManager[EntityType, ValidateType, URIType any](
@@ -66,6 +86,130 @@ func NewGormManager[EntityType, ValidateType, URIType any](
 	}
 }
 
+// EnableAtomic wraps every write request (POST, PUT, PATCH, DELETE) in a
+// transaction stored under ginContextKey, retrying serialization failures
+// and deadlocks up to maxRetries times.
+func (manager *GormManager[EntityType, ValidateType, URIType]) EnableAtomic(
+	maxRetries uint,
+) *GormManager[EntityType, ValidateType, URIType] {
+	manager.atomic = true
+	manager.atomicMaxRetries = maxRetries
+	return manager
+}
+
+// SetSingletonScope sets the scope narrowing the query set to the object
+// returned by GetSingleton, e.g. the profile of the current user.
+func (manager *GormManager[EntityType, ValidateType, URIType]) SetSingletonScope(
+	singletonScope GormScopeGenerator,
+) *GormManager[EntityType, ValidateType, URIType] {
+	manager.singletonScope = singletonScope
+	return manager
+}
+
+// SetSingletonOwner sets the owner values assigned to the object created by
+// CreateSingleton, they must match the singleton scope so GetSingleton
+// finds the object afterwards.
+func (manager *GormManager[EntityType, ValidateType, URIType]) SetSingletonOwner(
+	singletonOwner SingletonOwnerFunc,
+) *GormManager[EntityType, ValidateType, URIType] {
+	manager.singletonOwner = singletonOwner
+	return manager
+}
+
+// SetLookupFields makes GetObject look the object up by the given URI params
+// instead of binding URIType, several fields are combined with AND.
+func (manager *GormManager[EntityType, ValidateType, URIType]) SetLookupFields(
+	lookupFields ...GormLookupField,
+) *GormManager[EntityType, ValidateType, URIType] {
+	manager.lookupFields = lookupFields
+	return manager
+}
+
+// SetSelectColumns narrows the SELECT list to the columns of the keys asked
+// by the fields query param, columns maps a serialized key to its column
+// and alwaysSelected lists the columns needed anyway, like primary and
+// foreign keys. Keys without a column are ignored. Only reads are narrowed,
+// the objects loaded to be updated or deleted and those preloading
+// associations, which may need any column, are loaded whole.
+func (manager *GormManager[EntityType, ValidateType, URIType]) SetSelectColumns(
+	columns map[string]string, alwaysSelected ...string,
+) *GormManager[EntityType, ValidateType, URIType] {
+	manager.selectColumns = columns
+	manager.alwaysSelected = alwaysSelected
+	return manager
+}
+
+func (manager *GormManager[_, _, _]) selectedColumns(c *gin.Context) []string {
+	if manager.selectColumns == nil || c.Request == nil || c.Request.URL == nil {
+		return nil
+	}
+	if isWriteMethod(c) || len(Preloads(c)) > 0 {
+		return nil
+	}
+	keys := fieldset.FromQuery(c.Request.URL.Query()).Keys()
+	if keys == nil {
+		return nil
+	}
+	sort.Strings(keys)
+	columns := append([]string{}, manager.alwaysSelected...)
+	for _, key := range keys {
+		if column, ok := manager.selectColumns[key]; ok {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// IsAtomic tells if atomic requests are enabled and the request is a write.
+func (manager *GormManager[_, _, _]) IsAtomic(c *gin.Context) bool {
+	return manager.atomic && isWriteMethod(c)
+}
+
+func (manager *GormManager[_, _, _]) Atomic(c *gin.Context, fn func() error) error {
+	if !manager.IsAtomic(c) {
+		return fn()
+	}
+	previousDB, hasPreviousDB := c.Get(manager.ginContextKey)
+	defer func() {
+		if hasPreviousDB {
+			c.Set(manager.ginContextKey, previousDB)
+		} else {
+			c.Set(manager.ginContextKey, nil)
+		}
+	}()
+
+	var err error
+	for attempt := uint(0); attempt <= manager.atomicMaxRetries; attempt++ {
+		err = manager.runInTransaction(c, fn)
+		if err == nil || !isRetryableTxError(err) {
+			return err
+		}
+	}
+	return err
+}
+
+func (manager *GormManager[_, _, _]) runInTransaction(c *gin.Context, fn func() error) (err error) {
+	tx := manager.db.WithContext(c).Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	c.Set(manager.ginContextKey, tx)
+
+	panicked := true
+	defer func() {
+		if panicked || err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	err = fn()
+	panicked = false
+	if err != nil {
+		return err
+	}
+	return tx.Commit().Error
+}
+
 func (manager *GormManager[_, _, _]) newDBWithContext(c *gin.Context) *gorm.DB {
 	newDB := manager.db.WithContext(c)
 	c.Set(manager.ginContextKey, newDB)
@@ -90,7 +234,14 @@ func (manager *GormManager[_, _, _]) GetQuerySet(c *gin.Context) *gorm.DB {
 	for _, gen := range manager.scopeGenerators {
 		scopeFunctions = append(scopeFunctions, gen(c))
 	}
-	return manager.GetDBWithContext(c).Scopes(scopeFunctions...)
+	db := manager.GetDBWithContext(c).Scopes(scopeFunctions...)
+	if columns := manager.selectedColumns(c); len(columns) > 0 {
+		db = db.Select(columns)
+	}
+	for _, association := range Preloads(c) {
+		db = db.Preload(association)
+	}
+	return db
 }
 
 func (manager *GormManager[EntityType, _, _]) GetObjects(
@@ -102,19 +253,52 @@ func (manager *GormManager[EntityType, _, _]) GetObjects(
 func (manager *GormManager[EntityType, _, URIType]) GetObject(
 	dest **EntityType, c *gin.Context) error {
 	*dest = new(EntityType)
+	if len(manager.lookupFields) > 0 {
+		conditions, err := lookupConditions(manager.lookupFields, c)
+		if err != nil {
+			return err
+		}
+		return manager.GetQuerySet(c).Clauses(conditions...).First(*dest).Error
+	}
 	paramsValidator := new(URIType)
 	if err := c.ShouldBindUri(paramsValidator); err != nil {
-		return err
+		return fmt.Errorf("%w: %v", ErrObjectNotFound, err)
 	}
-	if err := manager.GetQuerySet(c).First(*dest, paramsValidator).Error; err != nil {
+	db := manager.GetQuerySet(c)
+	conditions, err := uriConditions(db, paramsValidator, c)
+	if err != nil {
 		return err
 	}
-	return nil
+	return db.Clauses(conditions...).First(*dest).Error
+}
+
+func (manager *GormManager[EntityType, _, _]) GetSingleton(
+	dest **EntityType, c *gin.Context) error {
+	if manager.singletonScope == nil {
+		return ErrSingletonScopeNotSet
+	}
+	*dest = new(EntityType)
+	return manager.GetQuerySet(c).Scopes(manager.singletonScope(c)).First(*dest).Error
+}
+
+// CreateSingleton creates the object of the singleton with the values of
+// SetSingletonOwner, through the create func.
+func (manager *GormManager[EntityType, ValidateType, _]) CreateSingleton(
+	dest **EntityType, validatedData *ValidateType, c *gin.Context) error {
+	if manager.singletonOwner == nil {
+		return ErrSingletonOwnerNotSet
+	}
+	c.Set(SINGLETON_OWNER_CONTEXT_KEY, manager.singletonOwner(c))
+	*dest = nil
+	return manager.Save(dest, validatedData, c)
 }
 
 func (manager *GormManager[EntityType, ValidateType, _]) Save(
 	dest **EntityType, validatedData *ValidateType, c *gin.Context) error {
 	db := manager.GetDBWithContext(c)
+	if len(manager.nestedFields) > 0 {
+		return manager.saveNested(dest, validatedData, db, c)
+	}
 	if *dest == nil {
 		return manager.performCreateFunc(dest, validatedData, db, c)
 	}
@@ -131,10 +315,10 @@ func DefaultGormPaginateFunc[EntityType any](
 	dest *[]*EntityType, paginatedMeta *map[string]any, db *gorm.DB, c *gin.Context) error {
 	// NOTE: using limit offset
 	counter := 0
-	paginator := GormLimitOffsetPaginator{Limit: 20}
 	*paginatedMeta = map[string]any{}
 
-	if err := c.ShouldBindQuery(&paginator); err != nil {
+	paginator, err := bindLimitOffsetPaginator(c)
+	if err != nil {
 		return err
 	}
 	limit := int(paginator.Limit)
@@ -147,52 +331,65 @@ func DefaultGormPaginateFunc[EntityType any](
 			}
 			(*paginatedMeta)["count"] = *countEntities
 		}
-		rows, err := db.Limit(limit + 1).Offset(offset).Rows()
-		if err != nil {
+		// Find rather than Rows, so preloads in the scopes are applied
+		entities := make([]*EntityType, 0, limit+1)
+		if err := db.Limit(limit + 1).Offset(offset).Find(&entities).Error; err != nil {
 			return err
 		}
-		defer rows.Close()
-		for rows.Next() {
+		for _, entity := range entities {
 			counter += 1
 			if counter > limit {
 				break
 			}
-			entity := new(EntityType)
-			db.ScanRows(rows, entity)
 			*dest = append(*dest, entity)
 		}
-		if counter > limit {
-			// have next
-			nextURL := urlclone.CloneURL(c.Request.URL)
-			values := nextURL.Query()
-			values.Set("offset", strconv.Itoa(offset+limit))
-			nextURL.RawQuery = values.Encode()
-			(*paginatedMeta)["next"] = nextURL.String()
-		} else {
-			(*paginatedMeta)["next"] = nil
-		}
-		if offset > 0 {
-			// have previous
-			previousOffset := offset - limit
-			if previousOffset < 0 {
-				previousOffset = 0
-			}
-			previousURL := urlclone.CloneURL(c.Request.URL)
-			values := previousURL.Query()
-			values.Set("offset", strconv.Itoa(previousOffset))
-			previousURL.RawQuery = values.Encode()
-			(*paginatedMeta)["previous"] = previousURL.String()
-		} else {
-			(*paginatedMeta)["previous"] = nil
-		}
+		setLimitOffsetLinks(paginatedMeta, limit, offset, counter > limit, c)
 	}
 	return nil
 }
 
-func DefaultGormCreateFunc[EntityType, ValidateType any](dest **EntityType, validatedData *ValidateType, db *gorm.DB, _ *gin.Context) error {
+func bindLimitOffsetPaginator(c *gin.Context) (GormLimitOffsetPaginator, error) {
+	paginator := GormLimitOffsetPaginator{Limit: 20}
+	if err := c.ShouldBindQuery(&paginator); err != nil {
+		return paginator, err
+	}
+	return paginator, nil
+}
+
+func setLimitOffsetLinks(paginatedMeta *map[string]any, limit int, offset int, hasNext bool, c *gin.Context) {
+	if hasNext {
+		// have next
+		nextURL := urlclone.CloneURL(c.Request.URL)
+		values := nextURL.Query()
+		values.Set("offset", strconv.Itoa(offset+limit))
+		nextURL.RawQuery = values.Encode()
+		(*paginatedMeta)["next"] = nextURL.String()
+	} else {
+		(*paginatedMeta)["next"] = nil
+	}
+	if offset > 0 {
+		// have previous
+		previousOffset := offset - limit
+		if previousOffset < 0 {
+			previousOffset = 0
+		}
+		previousURL := urlclone.CloneURL(c.Request.URL)
+		values := previousURL.Query()
+		values.Set("offset", strconv.Itoa(previousOffset))
+		previousURL.RawQuery = values.Encode()
+		(*paginatedMeta)["previous"] = previousURL.String()
+	} else {
+		(*paginatedMeta)["previous"] = nil
+	}
+}
+
+func DefaultGormCreateFunc[EntityType, ValidateType any](dest **EntityType, validatedData *ValidateType, db *gorm.DB, c *gin.Context) error {
 	// NOTE: When creating from map, hooks won’t be invoked, associations won’t be saved and primary key values won’t be back filled
 	*dest = new(EntityType)
-	if err := mapstructure.Decode(validatedData, *dest); err != nil {
+	if err := decodeWritable(validatedData, *dest, c); err != nil {
+		return err
+	}
+	if err := ApplySingletonOwner(*dest, c); err != nil {
 		return err
 	}
 	if err := db.Create(*dest).Error; err != nil {
@@ -201,11 +398,12 @@ func DefaultGormCreateFunc[EntityType, ValidateType any](dest **EntityType, vali
 	return nil
 }
 
-func DefaultGormUpdateFunc[EntityType, ValidateType any](dest **EntityType, validatedData *ValidateType, db *gorm.DB, _ *gin.Context) error {
+func DefaultGormUpdateFunc[EntityType, ValidateType any](dest **EntityType, validatedData *ValidateType, db *gorm.DB, c *gin.Context) error {
 	mapValidatedData := new(map[string]any)
 	if err := mapstructure.Decode(validatedData, mapValidatedData); err != nil {
 		return err
 	}
+	FilterWritable[EntityType](*mapValidatedData, c)
 	if err := db.Model(*dest).Updates(mapValidatedData).Error; err != nil {
 		return err
 	}
@@ -218,3 +416,15 @@ func DefaultGormDeleteFunc[EntityType, ValidateType any](dest **EntityType, db *
 	}
 	return nil
 }
+
+// decodeWritable assigns validated data to an entity, leaving out its
+// read-only and hidden fields so they cannot be mass-assigned, by its rules
+// and those of the request, and the fields hidden from the request.
+func decodeWritable[EntityType, ValidateType any](validatedData *ValidateType, entity *EntityType, c *gin.Context) error {
+	mapValidatedData := map[string]any{}
+	if err := mapstructure.Decode(validatedData, &mapValidatedData); err != nil {
+		return err
+	}
+	FilterWritable[EntityType](mapValidatedData, c)
+	return mapstructure.Decode(mapValidatedData, entity)
+}
@@ -2,6 +2,7 @@ package manager
 
 import (
 	"database/sql"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -26,6 +27,21 @@ type personRequest struct {
 	Name string `mapstructure:"name"`
 }
 
+type member struct {
+	ID   uint   `gorm:"primarykey" mapstructure:"-"`
+	Name string `mapstructure:"name"`
+	Role string `mapstructure:"role" viewset:"read_only"`
+}
+
+type memberRequest struct {
+	Name string `mapstructure:"name"`
+	Role string `mapstructure:"role"`
+}
+
+func (member) TableName() string {
+	return "member"
+}
+
 func (person) TableName() string {
 	return "person"
 }
@@ -440,6 +456,98 @@ func (s *dbSuite) TestGormManagerGetObjectWithFirstError() {
 	assert.Error(s.T(), err)
 }
 
+func (s *dbSuite) TestGormManagerGetSingleton() {
+	mockURL, _ := url.Parse("https://example.com/")
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = &http.Request{
+		Header: make(http.Header),
+		URL:    mockURL,
+	}
+	c.Set("name", "phuc")
+
+	gormManager := NewGormManager[person, personRequest, personURI](
+		s.DB.Model(&person{}), nil, nil, nil, nil, "db",
+	)
+
+	entity := new(person)
+	assert.Equal(s.T(), ErrSingletonScopeNotSet, gormManager.GetSingleton(&entity, c))
+
+	gormManager.SetSingletonScope(func(c *gin.Context) func(*gorm.DB) *gorm.DB {
+		return func(db *gorm.DB) *gorm.DB {
+			return db.Where("name = ?", c.GetString("name"))
+		}
+	})
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "person" WHERE name = $1 ORDER BY "person"."id" LIMIT 1`),
+	).WithArgs("phuc").WillReturnRows(
+		sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "phuc"),
+	)
+
+	err := gormManager.GetSingleton(&entity, c)
+
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), person{1, "phuc"}, *entity)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "person" WHERE name = $1 ORDER BY "person"."id" LIMIT 1`),
+	).WithArgs("phuc").WillReturnRows(
+		sqlmock.NewRows([]string{"id", "name"}),
+	)
+
+	err = gormManager.GetSingleton(&entity, c)
+
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+	assert.True(s.T(), IsNotFound(err))
+}
+
+func (s *dbSuite) TestGormManagerGetObjectWithSelectColumns() {
+	mockURL, _ := url.Parse("https://example.com/?fields=name,books")
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = &http.Request{
+		Header: make(http.Header),
+		URL:    mockURL,
+	}
+	c.Params = []gin.Param{
+		{
+			Key:   "pk",
+			Value: "1",
+		},
+	}
+
+	gormManager := NewGormManager[person, personRequest, personURI](
+		s.DB.Model(&person{}), nil, nil, nil, nil, "db",
+	).SetSelectColumns(map[string]string{"name": "name"}, "id")
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT "id","name" FROM "person" WHERE "person"."id" = $1 ORDER BY "person"."id" LIMIT 1`),
+	).WithArgs(1).WillReturnRows(
+		sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "phuc"),
+	)
+
+	entity := new(person)
+	err := gormManager.GetObject(&entity, c)
+
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "phuc", entity.Name)
+
+	c.Request.URL, _ = url.Parse("https://example.com/?omit=name")
+	assert.Nil(s.T(), gormManager.selectedColumns(c))
+
+	c.Request.URL = mockURL
+	assert.Equal(s.T(), []string{"id", "name"}, gormManager.selectedColumns(c))
+	for _, method := range []string{http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		c.Request.Method = method
+		assert.Nil(s.T(), gormManager.selectedColumns(c), method)
+	}
+	c.Request.Method = http.MethodGet
+	SetPreloads(c, "Books")
+	assert.Nil(s.T(), gormManager.selectedColumns(c))
+}
+
 func (s *dbSuite) TestGormManagerDefaultCreateFunc() {
 	mockURL, _ := url.Parse("https://example.com/")
 	gin.SetMode(gin.TestMode)
@@ -502,6 +610,38 @@ func (s *dbSuite) TestGormManagerDefaultUpdateFunc() {
 	assert.Equal(s.T(), uint(1), entity.ID)
 }
 
+func (s *dbSuite) TestGormManagerSaveSkipsProtectedFields() {
+	mockURL, _ := url.Parse("https://example.com/")
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = &http.Request{
+		Header: make(http.Header),
+		URL:    mockURL,
+	}
+
+	gormManager := NewGormManager[member, memberRequest, personURI](
+		s.DB.Model(&member{}), nil, nil, nil, nil, "db",
+	)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`INSERT INTO "member" ("name","role") VALUES ($1,$2) RETURNING "id"`),
+	).WithArgs("phuc", "").WillReturnRows(
+		sqlmock.NewRows([]string{"id"}).AddRow(1),
+	)
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE "member" SET "name"=$1 WHERE "id" = $2`),
+	).WithArgs("phuc 2", 1).WillReturnResult(
+		sqlmock.NewResult(1, 1),
+	)
+
+	var entity *member
+	assert.NoError(s.T(), gormManager.Save(&entity, &memberRequest{Name: "phuc", Role: "admin"}, c))
+	assert.Equal(s.T(), "", entity.Role)
+	assert.NoError(s.T(), gormManager.Save(&entity, &memberRequest{Name: "phuc 2", Role: "admin"}, c))
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+	assert.Equal(s.T(), "", entity.Role)
+}
+
 func (s *dbSuite) TestGormManagerDefaultDeleteFunc() {
 	mockURL, _ := url.Parse("https://example.com/")
 	gin.SetMode(gin.TestMode)
@@ -528,6 +668,163 @@ func (s *dbSuite) TestGormManagerDefaultDeleteFunc() {
 	assert.NoError(s.T(), err)
 }
 
+type sqlStateError string
+
+func (err sqlStateError) Error() string {
+	return "sql state " + string(err)
+}
+
+func (err sqlStateError) SQLState() string {
+	return string(err)
+}
+
+func (s *dbSuite) TestGormManagerAtomicCommit() {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+
+	gormManager := NewGormManager[person, personRequest, personURI](
+		s.DB.Model(&person{}), nil, nil, nil, nil, "db",
+	).EnableAtomic(0)
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectCommit()
+
+	var dbInTransaction *gorm.DB
+	err := gormManager.Atomic(c, func() error {
+		dbInTransaction = gormManager.GetDBWithContext(c)
+		return nil
+	})
+
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+	assert.NoError(s.T(), err)
+	_, isTx := dbInTransaction.Statement.ConnPool.(gorm.TxCommitter)
+	assert.Equal(s.T(), true, isTx)
+	dbInContext, _ := c.Get("db")
+	assert.Equal(s.T(), nil, dbInContext)
+}
+
+func (s *dbSuite) TestGormManagerAtomicRollback() {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodDelete, "/", nil)
+
+	gormManager := NewGormManager[person, personRequest, personURI](
+		s.DB.Model(&person{}), nil, nil, nil, nil, "db",
+	).EnableAtomic(3)
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectRollback()
+
+	err := gormManager.Atomic(c, func() error {
+		return errors.New("handler error")
+	})
+
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+	assert.Equal(s.T(), "handler error", err.Error())
+}
+
+func (s *dbSuite) TestGormManagerAtomicRetry() {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPut, "/", nil)
+
+	gormManager := NewGormManager[person, personRequest, personURI](
+		s.DB.Model(&person{}), nil, nil, nil, nil, "db",
+	).EnableAtomic(2)
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectRollback()
+	s.mock.ExpectBegin()
+	s.mock.ExpectRollback()
+	s.mock.ExpectBegin()
+	s.mock.ExpectCommit()
+
+	attempts := 0
+	err := gormManager.Atomic(c, func() error {
+		attempts += 1
+		if attempts < 3 {
+			return sqlStateError("40001")
+		}
+		return nil
+	})
+
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), 3, attempts)
+}
+
+func (s *dbSuite) TestGormManagerAtomicRetryExhausted() {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPut, "/", nil)
+
+	gormManager := NewGormManager[person, personRequest, personURI](
+		s.DB.Model(&person{}), nil, nil, nil, nil, "db",
+	).EnableAtomic(1)
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectRollback()
+	s.mock.ExpectBegin()
+	s.mock.ExpectRollback()
+
+	attempts := 0
+	err := gormManager.Atomic(c, func() error {
+		attempts += 1
+		return sqlStateError("40P01")
+	})
+
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+	assert.Equal(s.T(), sqlStateError("40P01"), err)
+	assert.Equal(s.T(), 2, attempts)
+}
+
+func (s *dbSuite) TestGormManagerAtomicSkipsSafeMethods() {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+	gormManager := NewGormManager[person, personRequest, personURI](
+		s.DB.Model(&person{}), nil, nil, nil, nil, "db",
+	).EnableAtomic(1)
+	assert.False(s.T(), gormManager.IsAtomic(c))
+
+	called := false
+	err := gormManager.Atomic(c, func() error {
+		called = true
+		return nil
+	})
+
+	c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+	assert.True(s.T(), gormManager.IsAtomic(c))
+	assert.False(s.T(), NewGormManager[person, personRequest, personURI](
+		s.DB.Model(&person{}), nil, nil, nil, nil, "db",
+	).IsAtomic(c))
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), true, called)
+}
+
+func (s *dbSuite) TestGormManagerAtomicRollbackOnPanic() {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+
+	gormManager := NewGormManager[person, personRequest, personURI](
+		s.DB.Model(&person{}), nil, nil, nil, nil, "db",
+	).EnableAtomic(0)
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectRollback()
+
+	assert.Panics(s.T(), func() {
+		gormManager.Atomic(c, func() error {
+			panic("boom")
+		})
+	})
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+}
+
 func TestGorm(t *testing.T) {
 	suite.Run(t, &dbSuite{})
 }
@@ -0,0 +1,45 @@
+package manager
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const HIDDEN_FIELDS_CONTEXT_KEY = "github.com/TcMits/viewset/manager.hidden_fields"
+
+// SetHiddenFields asks the manager not to write the given keys of the
+// validated data, as named by mapstructure, for the rest of the request.
+func SetHiddenFields(c *gin.Context, keys ...string) {
+	c.Set(HIDDEN_FIELDS_CONTEXT_KEY, keys)
+}
+
+// HiddenFields returns the keys set by SetHiddenFields.
+func HiddenFields(c *gin.Context) []string {
+	keys, _ := c.Value(HIDDEN_FIELDS_CONTEXT_KEY).([]string)
+	return keys
+}
+
+func isHiddenField(key string, c *gin.Context) bool {
+	for _, hidden := range HiddenFields(c) {
+		if strings.EqualFold(key, hidden) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterHidden removes the hidden keys of the request, matched
+// case-insensitively like the field rules. The default create and update
+// funcs call it, custom ones must call it or read HiddenFields, else the
+// hidden input of the request is written.
+func FilterHidden(values map[string]any, c *gin.Context) {
+	if c == nil {
+		return
+	}
+	for key := range values {
+		if isHiddenField(key, c) {
+			delete(values, key)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package manager
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHiddenFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	assert.Nil(t, HiddenFields(c))
+	values := map[string]any{"name": "phuc", "Email": "phuc@example.com"}
+	FilterHidden(values, c)
+	assert.Len(t, values, 2)
+
+	SetHiddenFields(c, "email")
+	assert.Equal(t, []string{"email"}, HiddenFields(c))
+	FilterHidden(values, c)
+	assert.Equal(t, map[string]any{"name": "phuc"}, values)
+}
@@ -4,9 +4,69 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-type Manager[EntityType, ValidateType any] interface {
+type ObjectGetter[EntityType any] interface {
 	GetObject(**EntityType, *gin.Context) error
+}
+
+type ObjectsGetter[EntityType any] interface {
 	GetObjects(*[]*EntityType, *map[string]any, *gin.Context) error
+}
+
+type Saver[EntityType, ValidateType any] interface {
 	Save(**EntityType, *ValidateType, *gin.Context) error
+}
+
+type Deleter[EntityType any] interface {
 	Delete(**EntityType, *gin.Context) error
 }
+
+type Manager[EntityType, ValidateType any] interface {
+	ObjectGetter[EntityType]
+	ObjectsGetter[EntityType]
+	Saver[EntityType, ValidateType]
+	Deleter[EntityType]
+}
+
+type ReadManager[EntityType any] interface {
+	ObjectGetter[EntityType]
+	ObjectsGetter[EntityType]
+}
+
+type ListCreateManager[EntityType, ValidateType any] interface {
+	ObjectsGetter[EntityType]
+	Saver[EntityType, ValidateType]
+}
+
+type RetrieveUpdateManager[EntityType, ValidateType any] interface {
+	ObjectGetter[EntityType]
+	Saver[EntityType, ValidateType]
+}
+
+type RetrieveUpdateDeleteManager[EntityType, ValidateType any] interface {
+	ObjectGetter[EntityType]
+	Saver[EntityType, ValidateType]
+	Deleter[EntityType]
+}
+
+type SingletonGetter[EntityType any] interface {
+	GetSingleton(**EntityType, *gin.Context) error
+	// resolve the only object of a singleton resource from the request
+}
+
+type SingletonCreator[EntityType, ValidateType any] interface {
+	CreateSingleton(**EntityType, *ValidateType, *gin.Context) error
+	// create the only object of a singleton resource, owned by the request
+}
+
+type SingletonManager[EntityType, ValidateType any] interface {
+	SingletonGetter[EntityType]
+	Saver[EntityType, ValidateType]
+	Deleter[EntityType]
+}
+
+type TransactionalManager interface {
+	IsAtomic(*gin.Context) bool
+	// tell if the request runs in a transaction, the ViewSet buffers the response of those only
+	Atomic(*gin.Context, func() error) error
+	// run the function in a transaction, it is rolled back if the function returns an error
+}
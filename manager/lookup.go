@@ -0,0 +1,139 @@
+package manager
+
+import (
+	"encoding/hex"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+type GormLookupType string
+
+const (
+	LOOKUP_STRING GormLookupType = "string"
+	LOOKUP_INT    GormLookupType = "int"
+	LOOKUP_UINT   GormLookupType = "uint"
+	LOOKUP_UUID   GormLookupType = "uuid"
+)
+
+// GormLookupField maps a URI param to the column it is looked up by.
+type GormLookupField struct {
+	Param string
+	// name of the URI param
+	Column string
+	// optional, defaults to Param
+	Type GormLookupType
+	// optional, defaults to LOOKUP_STRING
+	CaseInsensitive bool
+	// compare LOWER(column) with the lowercased value, string lookups only
+}
+
+func (field GormLookupField) column() string {
+	if field.Column == "" {
+		return field.Param
+	}
+	return field.Column
+}
+
+func (field GormLookupField) parse(raw string) (any, bool) {
+	switch field.Type {
+	case "", LOOKUP_STRING:
+		if raw == "" {
+			return nil, false
+		}
+		if field.CaseInsensitive {
+			return strings.ToLower(raw), true
+		}
+		return raw, true
+	case LOOKUP_INT:
+		value, err := strconv.ParseInt(raw, 10, 64)
+		return value, err == nil
+	case LOOKUP_UINT:
+		value, err := strconv.ParseUint(raw, 10, 64)
+		return value, err == nil
+	case LOOKUP_UUID:
+		return parseUUID(raw)
+	}
+	return nil, false
+}
+
+func (field GormLookupField) expression(value any) clause.Expression {
+	column := clause.Column{Table: clause.CurrentTable, Name: field.column()}
+	if field.CaseInsensitive {
+		if _, ok := value.(string); ok {
+			return clause.Expr{SQL: "LOWER(?) = ?", Vars: []any{column, value}}
+		}
+	}
+	// clause.Eq keeps zero values, which struct conditions drop
+	return clause.Eq{Column: column, Value: value}
+}
+
+// lookupConditions builds one condition per lookup field from the URI params,
+// it returns ErrObjectNotFound if a param is missing or cannot be parsed.
+func lookupConditions(fields []GormLookupField, c *gin.Context) ([]clause.Expression, error) {
+	conditions := make([]clause.Expression, 0, len(fields))
+	for _, field := range fields {
+		raw, ok := c.Params.Get(field.Param)
+		if !ok {
+			return nil, ErrObjectNotFound
+		}
+		value, ok := field.parse(raw)
+		if !ok {
+			return nil, ErrObjectNotFound
+		}
+		conditions = append(conditions, field.expression(value))
+	}
+	return conditions, nil
+}
+
+var uriSchemas sync.Map
+
+// uriConditions matches each column of a bound URI struct like GORM struct
+// conditions, but keeps the zero values they drop so /items/0 does not match
+// the first row.
+func uriConditions(db *gorm.DB, uri any, c *gin.Context) ([]clause.Expression, error) {
+	uriSchema, err := schema.Parse(uri, &uriSchemas, db.NamingStrategy)
+	if err != nil {
+		return nil, err
+	}
+	value := reflect.Indirect(reflect.ValueOf(uri))
+	conditions := make([]clause.Expression, 0, len(uriSchema.Fields))
+	for _, field := range uriSchema.Fields {
+		if field.DBName == "" || !field.Readable {
+			continue
+		}
+		fieldValue, _ := field.ValueOf(c, value)
+		conditions = append(conditions, clause.Eq{
+			Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName},
+			Value:  fieldValue,
+		})
+	}
+	return conditions, nil
+}
+
+// parseUUID accepts the canonical and the undashed forms and returns the
+// canonical lowercase form.
+func parseUUID(raw string) (string, bool) {
+	undashed := raw
+	if len(raw) == 36 {
+		if raw[8] != '-' || raw[13] != '-' || raw[18] != '-' || raw[23] != '-' {
+			return "", false
+		}
+		undashed = raw[:8] + raw[9:13] + raw[14:18] + raw[19:23] + raw[24:]
+	}
+	if len(undashed) != 32 {
+		return "", false
+	}
+	if _, err := hex.DecodeString(undashed); err != nil {
+		return "", false
+	}
+	undashed = strings.ToLower(undashed)
+	return undashed[:8] + "-" + undashed[8:12] + "-" + undashed[12:16] + "-" +
+		undashed[16:20] + "-" + undashed[20:], true
+}
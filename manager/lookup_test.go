@@ -0,0 +1,154 @@
+package manager
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newLookupTestContext(params ...gin.Param) *gin.Context {
+	mockURL, _ := url.Parse("https://example.com/")
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = &http.Request{
+		Header: make(http.Header),
+		URL:    mockURL,
+	}
+	c.Params = params
+	return c
+}
+
+func TestGormLookupFieldParse(t *testing.T) {
+	cases := []struct {
+		field GormLookupField
+		raw   string
+		value any
+		ok    bool
+	}{
+		{GormLookupField{Param: "slug"}, "Hello", "Hello", true},
+		{GormLookupField{Param: "slug"}, "", nil, false},
+		{GormLookupField{Param: "slug", CaseInsensitive: true}, "Hello", "hello", true},
+		{GormLookupField{Param: "pk", Type: LOOKUP_INT}, "-3", int64(-3), true},
+		{GormLookupField{Param: "pk", Type: LOOKUP_INT}, "abc", int64(0), false},
+		{GormLookupField{Param: "pk", Type: LOOKUP_UINT}, "0", uint64(0), true},
+		{GormLookupField{Param: "pk", Type: LOOKUP_UINT}, "-1", uint64(0), false},
+		{
+			GormLookupField{Param: "id", Type: LOOKUP_UUID},
+			"6BA7B810-9DAD-11D1-80B4-00C04FD430C8", "6ba7b810-9dad-11d1-80b4-00c04fd430c8", true,
+		},
+		{
+			GormLookupField{Param: "id", Type: LOOKUP_UUID},
+			"6ba7b8109dad11d180b400c04fd430c8", "6ba7b810-9dad-11d1-80b4-00c04fd430c8", true,
+		},
+		{GormLookupField{Param: "id", Type: LOOKUP_UUID}, "6ba7b810-9dad-11d1-80b4-00c04fd430cz", "", false},
+		{GormLookupField{Param: "id", Type: LOOKUP_UUID}, "6ba7b810_9dad_11d1_80b4_00c04fd430c8", "", false},
+		{GormLookupField{Param: "id", Type: "float"}, "1.5", nil, false},
+	}
+	for _, tc := range cases {
+		value, ok := tc.field.parse(tc.raw)
+		assert.Equal(t, tc.ok, ok, tc.raw)
+		assert.Equal(t, tc.value, value, tc.raw)
+	}
+}
+
+func TestLookupConditionsNotFound(t *testing.T) {
+	fields := []GormLookupField{{Param: "pk", Type: LOOKUP_UINT}}
+
+	_, err := lookupConditions(fields, newLookupTestContext())
+	assert.Equal(t, ErrObjectNotFound, err)
+
+	_, err = lookupConditions(fields, newLookupTestContext(gin.Param{Key: "pk", Value: "abc"}))
+	assert.Equal(t, ErrObjectNotFound, err)
+}
+
+func (s *dbSuite) TestGormManagerGetObjectWithLookupFields() {
+	c := newLookupTestContext(gin.Param{Key: "pk", Value: "0"})
+
+	gormManager := NewGormManager[person, personRequest, personURI](
+		s.DB.Model(&person{}), nil, nil, nil, nil, "db",
+	).SetLookupFields(GormLookupField{Param: "pk", Column: "id", Type: LOOKUP_UINT})
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "person" WHERE "person"."id" = $1 ORDER BY "person"."id" LIMIT 1`),
+	).WithArgs(0).WillReturnRows(
+		sqlmock.NewRows([]string{"id", "name"}).AddRow(0, "zero"),
+	)
+
+	entity := new(person)
+	err := gormManager.GetObject(&entity, c)
+
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), person{0, "zero"}, *entity)
+}
+
+func (s *dbSuite) TestGormManagerGetObjectWithCompositeLookupFields() {
+	c := newLookupTestContext(
+		gin.Param{Key: "tenant", Value: "7"},
+		gin.Param{Key: "code", Value: "ABC"},
+	)
+
+	gormManager := NewGormManager[person, personRequest, personURI](
+		s.DB.Model(&person{}), nil, nil, nil, nil, "db",
+	).SetLookupFields(
+		GormLookupField{Param: "tenant", Column: "tenant_id", Type: LOOKUP_INT},
+		GormLookupField{Param: "code", CaseInsensitive: true},
+	)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "person" WHERE "person"."tenant_id" = $1 AND LOWER("person"."code") = $2 ORDER BY "person"."id" LIMIT 1`),
+	).WithArgs(7, "abc").WillReturnRows(
+		sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "phuc"),
+	)
+
+	entity := new(person)
+	err := gormManager.GetObject(&entity, c)
+
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "phuc", entity.Name)
+}
+
+func (s *dbSuite) TestGormManagerGetObjectWithLookupParseError() {
+	c := newLookupTestContext(gin.Param{Key: "pk", Value: "not-a-number"})
+
+	gormManager := NewGormManager[person, personRequest, personURI](
+		s.DB.Model(&person{}), nil, nil, nil, nil, "db",
+	).SetLookupFields(GormLookupField{Param: "pk", Column: "id", Type: LOOKUP_UINT})
+
+	entity := new(person)
+	err := gormManager.GetObject(&entity, c)
+
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+	assert.Equal(s.T(), ErrObjectNotFound, err)
+}
+
+type personOptionalURI struct {
+	ID uint `uri:"pk" mapstructure:"id"`
+}
+
+func (s *dbSuite) TestGormManagerGetObjectWithZeroURIParam() {
+	c := newLookupTestContext(gin.Param{Key: "pk", Value: "0"})
+
+	gormManager := NewGormManager[person, personRequest, personOptionalURI](
+		s.DB.Model(&person{}), nil, nil, nil, nil, "db",
+	)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "person" WHERE "person"."id" = $1 ORDER BY "person"."id" LIMIT 1`),
+	).WithArgs(0).WillReturnRows(
+		sqlmock.NewRows([]string{"id", "name"}),
+	)
+
+	entity := new(person)
+	err := gormManager.GetObject(&entity, c)
+
+	assert.NoError(s.T(), s.mock.ExpectationsWereMet())
+	assert.True(s.T(), IsNotFound(err))
+}
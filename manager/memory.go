@@ -0,0 +1,252 @@
+package manager
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+var _ Manager[any, any] = &MemoryManager[any, any, any]{}
+var _ SingletonManager[any, any] = &MemoryManager[any, any, any]{}
+var _ SingletonCreator[any, any] = &MemoryManager[any, any, any]{}
+
+type MemoryFilterFunc[EntityType any] func(*EntityType, *gin.Context) bool
+
+// MemoryManager keeps entities in memory, keyed by the primary key field
+// which must exist in both EntityType and URIType. Integer primary keys
+// left zero are assigned on create.
+type MemoryManager[EntityType, ValidateType, URIType any] struct {
+	mu       sync.RWMutex
+	pkField  string
+	entities []*EntityType
+	lastPK   uint64
+	filters  []MemoryFilterFunc[EntityType]
+	// selects the object returned by GetSingleton
+	singletonFilter MemoryFilterFunc[EntityType]
+	singletonOwner  SingletonOwnerFunc
+	// values assigned to the object created by CreateSingleton
+}
+
+func NewMemoryManager[EntityType, ValidateType, URIType any](
+	pkField string,
+	filters ...MemoryFilterFunc[EntityType],
+) *MemoryManager[EntityType, ValidateType, URIType] {
+	if _, ok := reflect.TypeOf(new(EntityType)).Elem().FieldByName(pkField); !ok {
+		panic(fmt.Sprintf("primary key field %s not found", pkField))
+	}
+	return &MemoryManager[EntityType, ValidateType, URIType]{
+		pkField: pkField,
+		filters: filters,
+	}
+}
+
+func (manager *MemoryManager[EntityType, ValidateType, URIType]) SetSingletonFilter(
+	singletonFilter MemoryFilterFunc[EntityType],
+) *MemoryManager[EntityType, ValidateType, URIType] {
+	manager.singletonFilter = singletonFilter
+	return manager
+}
+
+// SetSingletonOwner sets the owner values assigned to the object created by
+// CreateSingleton, they must match the singleton filter.
+func (manager *MemoryManager[EntityType, ValidateType, URIType]) SetSingletonOwner(
+	singletonOwner SingletonOwnerFunc,
+) *MemoryManager[EntityType, ValidateType, URIType] {
+	manager.singletonOwner = singletonOwner
+	return manager
+}
+
+func (manager *MemoryManager[EntityType, _, _]) pkOf(entity *EntityType) reflect.Value {
+	return reflect.ValueOf(entity).Elem().FieldByName(manager.pkField)
+}
+
+func (manager *MemoryManager[EntityType, _, _]) indexOf(pk reflect.Value) int {
+	// compare the printed values, URI and entity keys may be different types
+	key := fmt.Sprint(pk.Interface())
+	for i, entity := range manager.entities {
+		if fmt.Sprint(manager.pkOf(entity).Interface()) == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func (manager *MemoryManager[EntityType, _, _]) visible(entity *EntityType, c *gin.Context) bool {
+	for _, filter := range manager.filters {
+		if !filter(entity, c) {
+			return false
+		}
+	}
+	return true
+}
+
+func (manager *MemoryManager[EntityType, _, _]) assignPK(entity *EntityType) error {
+	pk := manager.pkOf(entity)
+	if !pk.IsZero() {
+		if manager.indexOf(pk) >= 0 {
+			return ErrDuplicatedPrimary
+		}
+		switch pk.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if pk.Int() > 0 && uint64(pk.Int()) > manager.lastPK {
+				manager.lastPK = uint64(pk.Int())
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if pk.Uint() > manager.lastPK {
+				manager.lastPK = pk.Uint()
+			}
+		}
+		return nil
+	}
+	switch pk.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		manager.lastPK += 1
+		pk.SetInt(int64(manager.lastPK))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		manager.lastPK += 1
+		pk.SetUint(manager.lastPK)
+	}
+	return nil
+}
+
+// Add stores copies of the entities as they are, e.g. to load fixtures.
+func (manager *MemoryManager[EntityType, _, _]) Add(entities ...EntityType) error {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+	for i := range entities {
+		entity := entities[i]
+		if err := manager.assignPK(&entity); err != nil {
+			return err
+		}
+		manager.entities = append(manager.entities, &entity)
+	}
+	return nil
+}
+
+func (manager *MemoryManager[EntityType, _, _]) GetObjects(
+	dest *[]*EntityType, paginatedMeta *map[string]any, c *gin.Context) error {
+	*paginatedMeta = map[string]any{}
+	paginator, err := bindLimitOffsetPaginator(c)
+	if err != nil {
+		return err
+	}
+	limit := int(paginator.Limit)
+	offset := int(paginator.Offset)
+
+	manager.mu.RLock()
+	defer manager.mu.RUnlock()
+	matched := 0
+	for _, entity := range manager.entities {
+		if !manager.visible(entity, c) {
+			continue
+		}
+		matched += 1
+		if matched > offset && matched <= offset+limit {
+			copied := *entity
+			*dest = append(*dest, &copied)
+		}
+	}
+	if paginator.WithCount {
+		(*paginatedMeta)["count"] = int64(matched)
+	}
+	setLimitOffsetLinks(paginatedMeta, limit, offset, matched > offset+limit, c)
+	return nil
+}
+
+func (manager *MemoryManager[EntityType, _, URIType]) GetObject(
+	dest **EntityType, c *gin.Context) error {
+	paramsValidator := new(URIType)
+	if err := c.ShouldBindUri(paramsValidator); err != nil {
+		return fmt.Errorf("%w: %v", ErrObjectNotFound, err)
+	}
+	pk := reflect.ValueOf(paramsValidator).Elem().FieldByName(manager.pkField)
+	if !pk.IsValid() {
+		return fmt.Errorf("primary key field %s not found in URI", manager.pkField)
+	}
+
+	manager.mu.RLock()
+	defer manager.mu.RUnlock()
+	i := manager.indexOf(pk)
+	if i < 0 || !manager.visible(manager.entities[i], c) {
+		return ErrObjectNotFound
+	}
+	copied := *manager.entities[i]
+	*dest = &copied
+	return nil
+}
+
+func (manager *MemoryManager[EntityType, _, _]) GetSingleton(
+	dest **EntityType, c *gin.Context) error {
+	manager.mu.RLock()
+	defer manager.mu.RUnlock()
+	for _, entity := range manager.entities {
+		if !manager.visible(entity, c) {
+			continue
+		}
+		if manager.singletonFilter == nil || manager.singletonFilter(entity, c) {
+			copied := *entity
+			*dest = &copied
+			return nil
+		}
+	}
+	return ErrObjectNotFound
+}
+
+// CreateSingleton creates the object of the singleton with the values of
+// SetSingletonOwner.
+func (manager *MemoryManager[EntityType, ValidateType, _]) CreateSingleton(
+	dest **EntityType, validatedData *ValidateType, c *gin.Context) error {
+	if manager.singletonOwner == nil {
+		return ErrSingletonOwnerNotSet
+	}
+	c.Set(SINGLETON_OWNER_CONTEXT_KEY, manager.singletonOwner(c))
+	*dest = nil
+	return manager.Save(dest, validatedData, c)
+}
+
+func (manager *MemoryManager[EntityType, ValidateType, _]) Save(
+	dest **EntityType, validatedData *ValidateType, c *gin.Context) error {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+	if *dest == nil {
+		entity := new(EntityType)
+		if err := decodeWritable(validatedData, entity, c); err != nil {
+			return err
+		}
+		if err := ApplySingletonOwner(entity, c); err != nil {
+			return err
+		}
+		if err := manager.assignPK(entity); err != nil {
+			return err
+		}
+		manager.entities = append(manager.entities, entity)
+		copied := *entity
+		*dest = &copied
+		return nil
+	}
+
+	i := manager.indexOf(manager.pkOf(*dest))
+	if i < 0 {
+		return ErrObjectNotFound
+	}
+	if err := decodeWritable(validatedData, *dest, c); err != nil {
+		return err
+	}
+	copied := **dest
+	manager.entities[i] = &copied
+	return nil
+}
+
+func (manager *MemoryManager[EntityType, _, _]) Delete(
+	dest **EntityType, c *gin.Context) error {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+	i := manager.indexOf(manager.pkOf(*dest))
+	if i < 0 {
+		return ErrObjectNotFound
+	}
+	manager.entities = append(manager.entities[:i], manager.entities[i+1:]...)
+	return nil
+}
@@ -0,0 +1,216 @@
+package manager
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMemoryTestContext(rawURL string, params ...gin.Param) *gin.Context {
+	mockURL, _ := url.Parse(rawURL)
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = &http.Request{
+		Header: make(http.Header),
+		URL:    mockURL,
+	}
+	c.Params = params
+	return c
+}
+
+func TestNewMemoryManager(t *testing.T) {
+	memoryManager := NewMemoryManager[person, personRequest, personURI]("ID")
+
+	assert.Equal(t, "ID", memoryManager.pkField)
+	assert.Panics(t, func() {
+		NewMemoryManager[person, personRequest, personURI]("Pk")
+	})
+}
+
+func TestMemoryManagerGetObjects(t *testing.T) {
+	c := newMemoryTestContext("https://example.com/?limit=1&offset=1&with_count=true")
+	memoryManager := NewMemoryManager[person, personRequest, personURI]("ID")
+	memoryManager.Add(person{Name: "phuc"}, person{Name: "huy"}, person{Name: "an"})
+
+	entities := make([]*person, 0, 20)
+	paginatedMeta := map[string]any{}
+	err := memoryManager.GetObjects(&entities, &paginatedMeta, c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(entities))
+	assert.Equal(t, "huy", entities[0].Name)
+	assert.Equal(t, uint(2), entities[0].ID)
+	assert.Equal(t, "https://example.com/?limit=1&offset=2&with_count=true", paginatedMeta["next"])
+	assert.Equal(t, "https://example.com/?limit=1&offset=0&with_count=true", paginatedMeta["previous"])
+	assert.Equal(t, int64(3), paginatedMeta["count"])
+
+	entities[0].Name = "changed"
+	c = newMemoryTestContext("https://example.com/")
+	entities = make([]*person, 0, 20)
+	memoryManager.GetObjects(&entities, &paginatedMeta, c)
+	assert.Equal(t, 3, len(entities))
+	assert.Equal(t, "huy", entities[1].Name)
+	assert.Equal(t, nil, paginatedMeta["next"])
+	assert.Equal(t, nil, paginatedMeta["previous"])
+}
+
+func TestMemoryManagerGetObjectsWithBindError(t *testing.T) {
+	c := newMemoryTestContext("https://example.com/?offset=-1")
+	memoryManager := NewMemoryManager[person, personRequest, personURI]("ID")
+
+	entities := make([]*person, 0, 20)
+	paginatedMeta := map[string]any{}
+
+	assert.Error(t, memoryManager.GetObjects(&entities, &paginatedMeta, c))
+}
+
+func TestMemoryManagerFilters(t *testing.T) {
+	memoryManager := NewMemoryManager[person, personRequest, personURI](
+		"ID",
+		func(p *person, c *gin.Context) bool {
+			return p.Name == c.Query("name")
+		},
+	)
+	memoryManager.Add(person{Name: "phuc"}, person{Name: "huy"})
+
+	c := newMemoryTestContext("https://example.com/?name=huy&with_count=true")
+	entities := make([]*person, 0, 20)
+	paginatedMeta := map[string]any{}
+	memoryManager.GetObjects(&entities, &paginatedMeta, c)
+	assert.Equal(t, 1, len(entities))
+	assert.Equal(t, "huy", entities[0].Name)
+	assert.Equal(t, int64(1), paginatedMeta["count"])
+
+	entity := new(person)
+	c = newMemoryTestContext("https://example.com/?name=huy", gin.Param{Key: "pk", Value: "1"})
+	assert.Equal(t, ErrObjectNotFound, memoryManager.GetObject(&entity, c))
+	c = newMemoryTestContext("https://example.com/?name=huy", gin.Param{Key: "pk", Value: "2"})
+	assert.NoError(t, memoryManager.GetObject(&entity, c))
+	assert.Equal(t, "huy", entity.Name)
+}
+
+func TestMemoryManagerGetObject(t *testing.T) {
+	memoryManager := NewMemoryManager[person, personRequest, personURI]("ID")
+	memoryManager.Add(person{ID: 5, Name: "phuc"})
+
+	entity := new(person)
+	c := newMemoryTestContext("https://example.com/", gin.Param{Key: "pk", Value: "5"})
+	assert.NoError(t, memoryManager.GetObject(&entity, c))
+	assert.Equal(t, person{ID: 5, Name: "phuc"}, *entity)
+
+	c = newMemoryTestContext("https://example.com/", gin.Param{Key: "pk", Value: "6"})
+	assert.Equal(t, ErrObjectNotFound, memoryManager.GetObject(&entity, c))
+
+	c = newMemoryTestContext("https://example.com/", gin.Param{Key: "id", Value: "5"})
+	assert.Error(t, memoryManager.GetObject(&entity, c))
+}
+
+func TestMemoryManagerSave(t *testing.T) {
+	c := newMemoryTestContext("https://example.com/")
+	memoryManager := NewMemoryManager[person, personRequest, personURI]("ID")
+	memoryManager.Add(person{ID: 5, Name: "phuc"})
+
+	var entity *person
+	assert.NoError(t, memoryManager.Save(&entity, &personRequest{Name: "huy"}, c))
+	assert.Equal(t, person{ID: 6, Name: "huy"}, *entity)
+
+	entity.Name = "not saved"
+	assert.NoError(t, memoryManager.Save(&entity, &personRequest{Name: "huy 2"}, c))
+	assert.Equal(t, "huy 2", entity.Name)
+
+	stored := new(person)
+	c = newMemoryTestContext("https://example.com/", gin.Param{Key: "pk", Value: "6"})
+	memoryManager.GetObject(&stored, c)
+	assert.Equal(t, person{ID: 6, Name: "huy 2"}, *stored)
+
+	missing := &person{ID: 7}
+	assert.Equal(t, ErrObjectNotFound, memoryManager.Save(&missing, &personRequest{Name: "an"}, c))
+	assert.Equal(t, ErrDuplicatedPrimary, memoryManager.Add(person{ID: 5}))
+}
+
+func TestMemoryManagerSaveSkipsProtectedFields(t *testing.T) {
+	c := newMemoryTestContext("https://example.com/")
+	memoryManager := NewMemoryManager[member, memberRequest, personURI]("ID")
+
+	var entity *member
+	assert.NoError(t, memoryManager.Save(&entity, &memberRequest{Name: "phuc", Role: "admin"}, c))
+	assert.Equal(t, member{ID: 1, Name: "phuc"}, *entity)
+	assert.NoError(t, memoryManager.Save(&entity, &memberRequest{Name: "huy", Role: "admin"}, c))
+	assert.Equal(t, member{ID: 1, Name: "huy"}, *entity)
+}
+
+func TestMemoryManagerSaveSkipsHiddenFields(t *testing.T) {
+	c := newMemoryTestContext("https://example.com/")
+	memoryManager := NewMemoryManager[person, personRequest, personURI]("ID")
+	memoryManager.Add(person{Name: "phuc"})
+	SetHiddenFields(c, "name")
+
+	entity := &person{ID: 1, Name: "phuc"}
+	assert.NoError(t, memoryManager.Save(&entity, &personRequest{Name: "huy"}, c))
+	assert.Equal(t, person{ID: 1, Name: "phuc"}, *entity)
+}
+
+func TestMemoryManagerDelete(t *testing.T) {
+	c := newMemoryTestContext("https://example.com/")
+	memoryManager := NewMemoryManager[person, personRequest, personURI]("ID")
+	memoryManager.Add(person{Name: "phuc"}, person{Name: "huy"})
+
+	entity := &person{ID: 1}
+	assert.NoError(t, memoryManager.Delete(&entity, c))
+	assert.Equal(t, ErrObjectNotFound, memoryManager.Delete(&entity, c))
+	assert.Equal(t, 1, len(memoryManager.entities))
+	assert.Equal(t, "huy", memoryManager.entities[0].Name)
+}
+
+func TestMemoryManagerConcurrentSave(t *testing.T) {
+	c := newMemoryTestContext("https://example.com/")
+	memoryManager := NewMemoryManager[person, personRequest, personURI]("ID")
+	wg := sync.WaitGroup{}
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var entity *person
+			memoryManager.Save(&entity, &personRequest{Name: "phuc"}, c)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 50, len(memoryManager.entities))
+	assert.Equal(t, uint64(50), memoryManager.lastPK)
+}
+
+func TestMemoryManagerGetSingleton(t *testing.T) {
+	memoryManager := NewMemoryManager[person, personRequest, personURI](
+		"ID",
+		func(p *person, c *gin.Context) bool {
+			return p.Name != "hidden"
+		},
+	)
+	memoryManager.Add(person{Name: "hidden"}, person{Name: "phuc"}, person{Name: "huy"})
+
+	entity := new(person)
+	c := newMemoryTestContext("https://example.com/")
+	assert.NoError(t, memoryManager.GetSingleton(&entity, c))
+	assert.Equal(t, "phuc", entity.Name)
+
+	memoryManager.SetSingletonFilter(func(p *person, c *gin.Context) bool {
+		return p.Name == c.Query("name")
+	})
+	c = newMemoryTestContext("https://example.com/?name=huy")
+	assert.NoError(t, memoryManager.GetSingleton(&entity, c))
+	assert.Equal(t, person{ID: 3, Name: "huy"}, *entity)
+
+	entity.Name = "changed"
+	stored := new(person)
+	memoryManager.GetSingleton(&stored, c)
+	assert.Equal(t, "huy", stored.Name)
+
+	c = newMemoryTestContext("https://example.com/?name=hidden")
+	assert.Equal(t, ErrObjectNotFound, memoryManager.GetSingleton(&entity, c))
+}
@@ -0,0 +1,317 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/TcMits/viewset/pkg/fieldrule"
+	"github.com/gin-gonic/gin"
+	"github.com/mitchellh/mapstructure"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+type GormMissingChildren int
+
+const (
+	DELETE_MISSING_CHILDREN GormMissingChildren = iota
+	KEEP_MISSING_CHILDREN
+	REJECT_MISSING_CHILDREN
+)
+
+var ErrUnknownChild = errors.New("child does not belong to the object")
+var ErrMissingChild = errors.New("child is missing")
+
+// NestedWriteError locates the child which failed to be written, e.g.
+// items[1].
+type NestedWriteError struct {
+	Path string
+	Err  error
+}
+
+func (err *NestedWriteError) Error() string {
+	return err.Path + ": " + err.Err.Error()
+}
+
+func (err *NestedWriteError) Unwrap() error {
+	return err.Err
+}
+
+// GormNestedField writes the child rows of a has-one or has-many association
+// from a struct or slice field of ValidateType. On update children are
+// matched by primary key, children without one are created.
+type GormNestedField struct {
+	Field string
+	// field of ValidateType holding the child payload(s)
+	Association string
+	// optional, association of EntityType written from Field, defaults to Field
+	Missing GormMissingChildren
+	// children of the object which are missing from the payload on update, deleted by default, a nil Field leaves all of them alone
+}
+
+func (nested GormNestedField) association() string {
+	if nested.Association == "" {
+		return nested.Field
+	}
+	return nested.Association
+}
+
+// SetNestedFields makes Save write the children of the given associations in
+// the same transaction as the object, the perform functions receive a DB
+// omitting them.
+func (manager *GormManager[EntityType, ValidateType, URIType]) SetNestedFields(
+	nestedFields ...GormNestedField,
+) *GormManager[EntityType, ValidateType, URIType] {
+	validateType := reflect.TypeOf(new(ValidateType)).Elem()
+	for _, nested := range nestedFields {
+		if _, ok := validateType.FieldByName(nested.Field); !ok {
+			panic(fmt.Sprintf("nested field %s not found", nested.Field))
+		}
+	}
+	manager.nestedFields = nestedFields
+	return manager
+}
+
+// nestedOmits returns the associations and the keys the perform functions
+// must not write, the key of a field decoded by mapstructure defaults to its
+// name.
+func (manager *GormManager[_, ValidateType, _]) nestedOmits() []string {
+	validateType := reflect.TypeOf(new(ValidateType)).Elem()
+	omits := make([]string, 0, 2*len(manager.nestedFields))
+	for _, nested := range manager.nestedFields {
+		field, _ := validateType.FieldByName(nested.Field)
+		omits = append(omits, nested.association(), tagName(field, "mapstructure"))
+	}
+	return omits
+}
+
+// tagName returns the name given to the field by a tag, the field name if
+// there is none.
+func tagName(field reflect.StructField, key string) string {
+	name := strings.Split(field.Tag.Get(key), ",")[0]
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+func (manager *GormManager[EntityType, ValidateType, _]) saveNested(
+	dest **EntityType, validatedData *ValidateType, db *gorm.DB, c *gin.Context,
+) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		creating := *dest == nil
+		omitted := tx.Omit(manager.nestedOmits()...)
+		var err error
+		if creating {
+			err = manager.performCreateFunc(dest, validatedData, omitted, c)
+		} else {
+			err = manager.performUpdateFunc(dest, validatedData, omitted, c)
+		}
+		if err != nil {
+			return err
+		}
+		for _, nested := range manager.nestedFields {
+			if err := writeChildren(tx, *dest, validatedData, nested, creating, c); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// writeChildren creates, updates and deletes the children of the parent so
+// they match the payload, then assigns them to the association field.
+func writeChildren[EntityType, ValidateType any](
+	tx *gorm.DB, parent *EntityType, validatedData *ValidateType, nested GormNestedField, creating bool, c *gin.Context,
+) error {
+	db := tx.Session(&gorm.Session{NewDB: true})
+	association := db.Model(parent).Association(nested.association())
+	if association.Error != nil {
+		return association.Error
+	}
+	relationship := association.Relationship
+	if relationship.Type != schema.HasMany && relationship.Type != schema.HasOne {
+		return fmt.Errorf("association %s is not has one or has many", relationship.Name)
+	}
+	childSchema := relationship.FieldSchema
+	primaryField := childSchema.PrioritizedPrimaryField
+	if primaryField == nil {
+		return fmt.Errorf("%s has no primary key", childSchema.Name)
+	}
+
+	existing := reflect.New(reflect.SliceOf(reflect.PointerTo(childSchema.ModelType)))
+	if !creating {
+		if err := association.Find(existing.Interface()); err != nil {
+			return err
+		}
+	}
+	existingChildren := existing.Elem()
+	payloadField, _ := reflect.TypeOf(validatedData).Elem().FieldByName(nested.Field)
+	payload := reflect.ValueOf(validatedData).Elem().FieldByName(nested.Field)
+	if !nestedProvided(payload) || isHiddenField(tagName(payloadField, "mapstructure"), c) {
+		// the children are left alone, e.g. by a PATCH leaving out the key
+		// or hiding it from the request
+		children := make([]reflect.Value, 0, existingChildren.Len())
+		for i := 0; i < existingChildren.Len(); i++ {
+			children = append(children, existingChildren.Index(i))
+		}
+		return assignChildren(reflect.ValueOf(parent).Elem().FieldByName(relationship.Name), children)
+	}
+	childRules := fieldrule.Of(childSchema.ModelType)
+	matched := make([]bool, existingChildren.Len())
+
+	path := tagName(payloadField, "json")
+	items, isSlice := nestedItems(payload)
+
+	children := make([]reflect.Value, 0, len(items))
+	for i, item := range items {
+		itemPath := path
+		if isSlice {
+			itemPath = fmt.Sprintf("%s[%d]", path, i)
+		}
+		values := map[string]any{}
+		if err := mapstructure.Decode(item.Interface(), &values); err != nil {
+			return &NestedWriteError{Path: itemPath, Err: err}
+		}
+		child := reflect.New(childSchema.ModelType)
+		if err := mapstructure.Decode(values, child.Interface()); err != nil {
+			return &NestedWriteError{Path: itemPath, Err: err}
+		}
+		pk, isZero := primaryField.ValueOf(c, child.Elem())
+		childRules.FilterWritable(values)
+		if isZero {
+			child = reflect.New(childSchema.ModelType)
+			if err := mapstructure.Decode(values, child.Interface()); err != nil {
+				return &NestedWriteError{Path: itemPath, Err: err}
+			}
+			if err := setParentKeys(relationship, reflect.ValueOf(parent).Elem(), child.Elem(), c); err != nil {
+				return &NestedWriteError{Path: itemPath, Err: err}
+			}
+			if err := db.Create(child.Interface()).Error; err != nil {
+				return &NestedWriteError{Path: itemPath, Err: err}
+			}
+			children = append(children, child)
+			continue
+		}
+
+		index := indexOfChild(existingChildren, primaryField, pk, c)
+		if index < 0 || matched[index] {
+			return &NestedWriteError{Path: itemPath, Err: fmt.Errorf("%w: %v", ErrUnknownChild, pk)}
+		}
+		matched[index] = true
+		omits := []string{primaryField.Name}
+		for _, reference := range relationship.References {
+			omits = append(omits, reference.ForeignKey.Name)
+		}
+		existingChild := existingChildren.Index(index)
+		if err := db.Model(existingChild.Interface()).Omit(omits...).Updates(values).Error; err != nil {
+			return &NestedWriteError{Path: itemPath, Err: err}
+		}
+		children = append(children, existingChild)
+	}
+
+	for i := 0; i < existingChildren.Len(); i++ {
+		if matched[i] {
+			continue
+		}
+		missing := existingChildren.Index(i)
+		switch nested.Missing {
+		case KEEP_MISSING_CHILDREN:
+			children = append(children, missing)
+		case REJECT_MISSING_CHILDREN:
+			pk, _ := primaryField.ValueOf(c, missing.Elem())
+			return &NestedWriteError{Path: path, Err: fmt.Errorf("%w: %v", ErrMissingChild, pk)}
+		default:
+			if err := db.Delete(missing.Interface()).Error; err != nil {
+				return &NestedWriteError{Path: path, Err: err}
+			}
+		}
+	}
+	return assignChildren(reflect.ValueOf(parent).Elem().FieldByName(relationship.Name), children)
+}
+
+// nestedProvided tells if the payload holds children, a nil slice or pointer
+// means the key was left out of the request. An empty slice is provided.
+func nestedProvided(payload reflect.Value) bool {
+	switch payload.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+		return !payload.IsNil()
+	}
+	return true
+}
+
+// nestedItems returns the child payloads of a slice, struct or pointer
+// field, a nil pointer holds none.
+func nestedItems(payload reflect.Value) ([]reflect.Value, bool) {
+	for payload.Kind() == reflect.Pointer {
+		if payload.IsNil() {
+			return nil, false
+		}
+		payload = payload.Elem()
+	}
+	if payload.Kind() != reflect.Slice {
+		return []reflect.Value{payload}, false
+	}
+	items := make([]reflect.Value, 0, payload.Len())
+	for i := 0; i < payload.Len(); i++ {
+		items = append(items, payload.Index(i))
+	}
+	return items, true
+}
+
+func setParentKeys(relationship *schema.Relationship, parent reflect.Value, child reflect.Value, c *gin.Context) error {
+	for _, reference := range relationship.References {
+		var value any = reference.PrimaryValue
+		if reference.OwnPrimaryKey {
+			value, _ = reference.PrimaryKey.ValueOf(c, parent)
+		}
+		if err := reference.ForeignKey.Set(c, child, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func indexOfChild(children reflect.Value, primaryField *schema.Field, pk any, c *gin.Context) int {
+	// compare the printed values, payload and entity keys may be different types
+	key := fmt.Sprint(pk)
+	for i := 0; i < children.Len(); i++ {
+		childPK, _ := primaryField.ValueOf(c, children.Index(i).Elem())
+		if fmt.Sprint(childPK) == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// assignChildren sets the written children, as pointers, to a slice, struct
+// or pointer association field.
+func assignChildren(field reflect.Value, children []reflect.Value) error {
+	if !field.CanSet() {
+		return fmt.Errorf("cannot assign children to %s", field.Type())
+	}
+	fieldType := field.Type()
+	if fieldType.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(fieldType, 0, len(children))
+		for _, child := range children {
+			if fieldType.Elem().Kind() != reflect.Pointer {
+				child = child.Elem()
+			}
+			slice = reflect.Append(slice, child)
+		}
+		field.Set(slice)
+		return nil
+	}
+	if len(children) == 0 {
+		field.Set(reflect.Zero(fieldType))
+		return nil
+	}
+	child := children[len(children)-1]
+	if fieldType.Kind() != reflect.Pointer {
+		child = child.Elem()
+	}
+	field.Set(child)
+	return nil
+}
@@ -0,0 +1,223 @@
+package manager
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type lineItem struct {
+	ID       uint `gorm:"primarykey"`
+	OrderID  uint
+	Product  string
+	Quantity int
+}
+
+type shipment struct {
+	ID      uint `gorm:"primarykey"`
+	OrderID uint
+	Address string
+}
+
+type order struct {
+	ID       uint `gorm:"primarykey"`
+	Customer string
+	Items    []lineItem
+	Shipment *shipment
+}
+
+type lineItemRequest struct {
+	ID       uint   `json:"id" mapstructure:"ID,omitempty"`
+	Product  string `json:"product" mapstructure:"Product"`
+	Quantity int    `json:"quantity" mapstructure:"Quantity"`
+}
+
+type shipmentRequest struct {
+	Address string `json:"address" mapstructure:"Address"`
+}
+
+type orderRequest struct {
+	Customer string            `json:"customer" mapstructure:"Customer"`
+	Items    []lineItemRequest `json:"items" mapstructure:"Items"`
+	Shipment *shipmentRequest  `json:"shipment" mapstructure:"Shipment"`
+}
+
+func newOrderManager(t *testing.T, missing GormMissingChildren) (*GormManager[order, orderRequest, any], *gorm.DB) {
+	db, err := gorm.Open(
+		sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())),
+		&gorm.Config{Logger: logger.Default.LogMode(logger.Silent)},
+	)
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&order{}, &lineItem{}, &shipment{}))
+	orderManager := NewGormManager[order, orderRequest, any](
+		db.Model(&order{}), nil, nil, nil, nil, "db",
+	).SetNestedFields(
+		GormNestedField{Field: "Items", Missing: missing},
+		GormNestedField{Field: "Shipment"},
+	)
+	return orderManager, db
+}
+
+func newOrderContext(method string) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(method, "/orders/", nil)
+	return c
+}
+
+func createOrder(t *testing.T, orderManager *GormManager[order, orderRequest, any]) *order {
+	var created *order
+	require.NoError(t, orderManager.Save(&created, &orderRequest{
+		Customer: "phuc",
+		Items:    []lineItemRequest{{Product: "pen", Quantity: 1}, {Product: "book", Quantity: 2}},
+		Shipment: &shipmentRequest{Address: "hanoi"},
+	}, newOrderContext(http.MethodPost)))
+	return created
+}
+
+func TestSetNestedFieldsPanicsOnUnknownField(t *testing.T) {
+	assert.Panics(t, func() {
+		NewGormManager[order, orderRequest, any](nil, nil, nil, nil, nil, "db").SetNestedFields(
+			GormNestedField{Field: "Lines"},
+		)
+	})
+}
+
+func TestNestedCreate(t *testing.T) {
+	orderManager, db := newOrderManager(t, DELETE_MISSING_CHILDREN)
+
+	created := createOrder(t, orderManager)
+	assert.Equal(t, uint(1), created.ID)
+	assert.Equal(t, []lineItem{
+		{ID: 1, OrderID: 1, Product: "pen", Quantity: 1},
+		{ID: 2, OrderID: 1, Product: "book", Quantity: 2},
+	}, created.Items)
+	assert.Equal(t, &shipment{ID: 1, OrderID: 1, Address: "hanoi"}, created.Shipment)
+
+	var items []lineItem
+	require.NoError(t, db.Order("id").Find(&items).Error)
+	assert.Len(t, items, 2)
+}
+
+func TestNestedUpdate(t *testing.T) {
+	orderManager, db := newOrderManager(t, DELETE_MISSING_CHILDREN)
+	created := createOrder(t, orderManager)
+
+	require.NoError(t, orderManager.Save(&created, &orderRequest{
+		Customer: "huy",
+		Items:    []lineItemRequest{{ID: 2, Product: "book", Quantity: 5}, {Product: "ink", Quantity: 3}},
+		Shipment: &shipmentRequest{Address: "hue"},
+	}, newOrderContext(http.MethodPut)))
+	assert.Equal(t, "huy", created.Customer)
+	assert.Equal(t, []lineItem{
+		{ID: 2, OrderID: 1, Product: "book", Quantity: 5},
+		{ID: 3, OrderID: 1, Product: "ink", Quantity: 3},
+	}, created.Items)
+	assert.Equal(t, "hue", created.Shipment.Address)
+
+	var items []lineItem
+	require.NoError(t, db.Order("id").Find(&items).Error)
+	assert.Equal(t, created.Items, items)
+	var count int64
+	require.NoError(t, db.Model(&shipment{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestNestedUpdateKeepsMissingChildren(t *testing.T) {
+	orderManager, db := newOrderManager(t, KEEP_MISSING_CHILDREN)
+	created := createOrder(t, orderManager)
+
+	require.NoError(t, orderManager.Save(&created, &orderRequest{
+		Customer: "phuc",
+		Items:    []lineItemRequest{{ID: 2, Product: "book", Quantity: 5}},
+		Shipment: &shipmentRequest{Address: "hanoi"},
+	}, newOrderContext(http.MethodPatch)))
+	assert.Len(t, created.Items, 2)
+
+	var count int64
+	require.NoError(t, db.Model(&lineItem{}).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestNestedUpdateErrorsRollBack(t *testing.T) {
+	orderManager, db := newOrderManager(t, REJECT_MISSING_CHILDREN)
+	created := createOrder(t, orderManager)
+
+	err := orderManager.Save(&created, &orderRequest{
+		Customer: "huy",
+		Items:    []lineItemRequest{{ID: 2, Product: "book", Quantity: 5}},
+	}, newOrderContext(http.MethodPut))
+	nestedErr := new(NestedWriteError)
+	require.ErrorAs(t, err, &nestedErr)
+	assert.ErrorIs(t, err, ErrMissingChild)
+	assert.Equal(t, "items: child is missing: 1", err.Error())
+
+	err = orderManager.Save(&created, &orderRequest{
+		Customer: "huy",
+		Items: []lineItemRequest{
+			{ID: 1, Product: "pen", Quantity: 1}, {ID: 2, Product: "book", Quantity: 2}, {ID: 9},
+		},
+	}, newOrderContext(http.MethodPut))
+	assert.ErrorIs(t, err, ErrUnknownChild)
+	assert.Equal(t, "items[2]: child does not belong to the object: 9", err.Error())
+
+	reloaded := new(order)
+	require.NoError(t, db.Preload("Items").First(reloaded).Error)
+	assert.Equal(t, "phuc", reloaded.Customer)
+	assert.Equal(t, 1, reloaded.Items[0].Quantity)
+}
+
+func TestNestedPatchLeavesOmittedChildren(t *testing.T) {
+	orderManager, db := newOrderManager(t, DELETE_MISSING_CHILDREN)
+	created := createOrder(t, orderManager)
+
+	require.NoError(t, orderManager.Save(&created, &orderRequest{Customer: "huy"}, newOrderContext(http.MethodPatch)))
+	assert.Equal(t, "huy", created.Customer)
+	assert.Len(t, created.Items, 2)
+	assert.Equal(t, "hanoi", created.Shipment.Address)
+
+	var count int64
+	require.NoError(t, db.Model(&lineItem{}).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+	require.NoError(t, db.Model(&shipment{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+
+	// an empty list is provided, the missing children are deleted
+	require.NoError(t, orderManager.Save(&created, &orderRequest{
+		Customer: "huy", Items: []lineItemRequest{},
+	}, newOrderContext(http.MethodPatch)))
+	assert.Len(t, created.Items, 0)
+	require.NoError(t, db.Model(&lineItem{}).Count(&count).Error)
+	assert.Equal(t, int64(0), count)
+	require.NoError(t, db.Model(&shipment{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestNestedWriteSkipsHiddenFields(t *testing.T) {
+	orderManager, db := newOrderManager(t, DELETE_MISSING_CHILDREN)
+	created := createOrder(t, orderManager)
+
+	c := newOrderContext(http.MethodPatch)
+	SetHiddenFields(c, "items")
+	require.NoError(t, orderManager.Save(&created, &orderRequest{
+		Customer: "huy",
+		Items:    []lineItemRequest{{Product: "ink", Quantity: 3}},
+		Shipment: &shipmentRequest{Address: "hue"},
+	}, c))
+	assert.Len(t, created.Items, 2)
+	assert.Equal(t, "hue", created.Shipment.Address)
+
+	var count int64
+	require.NoError(t, db.Model(&lineItem{}).Where("product = ?", "ink").Count(&count).Error)
+	assert.Equal(t, int64(0), count)
+	require.NoError(t, db.Model(&lineItem{}).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+}
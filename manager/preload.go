@@ -0,0 +1,16 @@
+package manager
+
+import "github.com/gin-gonic/gin"
+
+const PRELOADS_CONTEXT_KEY = "github.com/TcMits/viewset/manager.preloads"
+
+// SetPreloads asks the manager to load the associations, e.g. Author or
+// Publisher.Country, with the objects of the request.
+func SetPreloads(c *gin.Context, associations ...string) {
+	c.Set(PRELOADS_CONTEXT_KEY, associations)
+}
+
+func Preloads(c *gin.Context) []string {
+	associations, _ := c.Value(PRELOADS_CONTEXT_KEY).([]string)
+	return associations
+}
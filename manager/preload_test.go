@@ -0,0 +1,18 @@
+package manager
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreloads(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	assert.Nil(t, Preloads(c))
+	SetPreloads(c, "Author", "Publisher.Country")
+	assert.Equal(t, []string{"Author", "Publisher.Country"}, Preloads(c))
+}
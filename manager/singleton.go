@@ -0,0 +1,30 @@
+package manager
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mitchellh/mapstructure"
+)
+
+const SINGLETON_OWNER_CONTEXT_KEY = "github.com/TcMits/viewset/manager.singleton_owner"
+
+var ErrSingletonOwnerNotSet = errors.New("singleton owner is not set")
+
+// SingletonOwnerFunc returns the values of the fields which own the singleton
+// of the request, by field name, e.g. {"UserID": 1}.
+type SingletonOwnerFunc func(*gin.Context) map[string]any
+
+// ApplySingletonOwner assigns the owner values of the singleton created by
+// the request to the entity. The default create funcs call it, custom ones
+// must call it, else GetSingleton cannot find the created object.
+func ApplySingletonOwner(entity any, c *gin.Context) error {
+	if c == nil {
+		return nil
+	}
+	owner, ok := c.Value(SINGLETON_OWNER_CONTEXT_KEY).(map[string]any)
+	if !ok || len(owner) == 0 {
+		return nil
+	}
+	return mapstructure.Decode(owner, entity)
+}
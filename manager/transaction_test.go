@@ -0,0 +1,40 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsWriteMethod(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	assert.Equal(t, false, isWriteMethod(c))
+	for method, expected := range map[string]bool{
+		http.MethodGet:     false,
+		http.MethodHead:    false,
+		http.MethodOptions: false,
+		http.MethodPost:    true,
+		http.MethodPut:     true,
+		http.MethodPatch:   true,
+		http.MethodDelete:  true,
+	} {
+		c.Request = httptest.NewRequest(method, "/", nil)
+		assert.Equal(t, expected, isWriteMethod(c), method)
+	}
+}
+
+func TestIsRetryableTxError(t *testing.T) {
+	assert.Equal(t, true, isRetryableTxError(sqlStateError("40001")))
+	assert.Equal(t, true, isRetryableTxError(sqlStateError("40P01")))
+	assert.Equal(t, true, isRetryableTxError(fmt.Errorf("wrapped: %w", sqlStateError("40001"))))
+	assert.Equal(t, false, isRetryableTxError(sqlStateError("23505")))
+	assert.Equal(t, true, isRetryableTxError(errors.New("database is locked")))
+	assert.Equal(t, false, isRetryableTxError(errors.New("record not found")))
+}
@@ -0,0 +1,204 @@
+// Package managertest checks that a manager.Manager honors the contract the
+// viewset handlers rely on.
+package managertest
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/TcMits/viewset/manager"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Harness[EntityType, ValidateType any] struct {
+	New func(*testing.T) manager.Manager[EntityType, ValidateType]
+	// return an empty manager, called once per check
+	ValidatedData func(int) *ValidateType
+	// return a different valid payload for every index
+	Matches func(*EntityType, *ValidateType) bool
+	// report whether the entity holds the payload
+	URIParams func(*EntityType) gin.Params
+	// return the URI params identifying the entity
+	MissingURIParams gin.Params
+	// URI params identifying no entity
+	OutOfScope func(*gin.Context)
+	// optional, prepare a request for which no entity is in scope
+}
+
+// Run checks every behavior of the Manager contract in its own subtest.
+func Run[EntityType, ValidateType any](t *testing.T, harness Harness[EntityType, ValidateType]) {
+	checks := []struct {
+		name  string
+		check func(*testing.T, Harness[EntityType, ValidateType])
+	}{
+		{"GetObjectsEmpty", checkGetObjectsEmpty[EntityType, ValidateType]},
+		{"SaveNilEntityCreates", checkSaveNilEntityCreates[EntityType, ValidateType]},
+		{"SaveEntityUpdates", checkSaveEntityUpdates[EntityType, ValidateType]},
+		{"GetObjectMissing", checkGetObjectMissing[EntityType, ValidateType]},
+		{"GetObjectsPaginates", checkGetObjectsPaginates[EntityType, ValidateType]},
+		{"DeleteRemoves", checkDeleteRemoves[EntityType, ValidateType]},
+		{"ScopeHidesOutOfScopeData", checkScope[EntityType, ValidateType]},
+	}
+	for _, check := range checks {
+		check := check
+		t.Run(check.name, func(t *testing.T) {
+			check.check(t, harness)
+		})
+	}
+}
+
+// NewContext returns a gin context for a request to rawURL with params.
+func NewContext(rawURL string, params gin.Params) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", rawURL, nil)
+	c.Params = params
+	return c
+}
+
+func create[EntityType, ValidateType any](
+	t *testing.T,
+	m manager.Manager[EntityType, ValidateType],
+	harness Harness[EntityType, ValidateType],
+	count int,
+) []*EntityType {
+	entities := make([]*EntityType, 0, count)
+	for i := 0; i < count; i++ {
+		var entity *EntityType
+		require.NoError(t, m.Save(&entity, harness.ValidatedData(i), NewContext("/", nil)))
+		entities = append(entities, entity)
+	}
+	return entities
+}
+
+func list[EntityType, ValidateType any](
+	t *testing.T, m manager.Manager[EntityType, ValidateType], c *gin.Context,
+) ([]*EntityType, map[string]any) {
+	entities := make([]*EntityType, 0, 20)
+	paginatedMeta := map[string]any{}
+	require.NoError(t, m.GetObjects(&entities, &paginatedMeta, c))
+	return entities, paginatedMeta
+}
+
+func checkGetObjectsEmpty[EntityType, ValidateType any](t *testing.T, harness Harness[EntityType, ValidateType]) {
+	m := harness.New(t)
+
+	entities, paginatedMeta := list(t, m, NewContext("/?with_count=true", nil))
+
+	assert.Equal(t, 0, len(entities))
+	assert.Contains(t, paginatedMeta, "next")
+	assert.Contains(t, paginatedMeta, "previous")
+	assert.Nil(t, paginatedMeta["next"])
+	assert.Nil(t, paginatedMeta["previous"])
+	assert.EqualValues(t, 0, paginatedMeta["count"])
+}
+
+func checkSaveNilEntityCreates[EntityType, ValidateType any](t *testing.T, harness Harness[EntityType, ValidateType]) {
+	m := harness.New(t)
+	validatedData := harness.ValidatedData(0)
+
+	var entity *EntityType
+	require.NoError(t, m.Save(&entity, validatedData, NewContext("/", nil)))
+	require.NotNil(t, entity, "Save must allocate the entity when it is nil")
+	assert.True(t, harness.Matches(entity, validatedData), "created entity does not hold the payload")
+
+	found := new(EntityType)
+	require.NoError(t, m.GetObject(&found, NewContext("/", harness.URIParams(entity))))
+	assert.True(t, harness.Matches(found, validatedData), "created entity is not persisted")
+}
+
+func checkSaveEntityUpdates[EntityType, ValidateType any](t *testing.T, harness Harness[EntityType, ValidateType]) {
+	m := harness.New(t)
+	entity := create(t, m, harness, 2)[0]
+	validatedData := harness.ValidatedData(2)
+
+	found := new(EntityType)
+	require.NoError(t, m.GetObject(&found, NewContext("/", harness.URIParams(entity))))
+	require.NoError(t, m.Save(&found, validatedData, NewContext("/", nil)))
+	assert.True(t, harness.Matches(found, validatedData), "updated entity does not hold the payload")
+
+	updated := new(EntityType)
+	require.NoError(t, m.GetObject(&updated, NewContext("/", harness.URIParams(entity))))
+	assert.True(t, harness.Matches(updated, validatedData), "update is not persisted")
+
+	entities, paginatedMeta := list(t, m, NewContext("/?with_count=true", nil))
+	assert.Equal(t, 2, len(entities), "update must not create entities")
+	assert.EqualValues(t, 2, paginatedMeta["count"])
+}
+
+func checkGetObjectMissing[EntityType, ValidateType any](t *testing.T, harness Harness[EntityType, ValidateType]) {
+	m := harness.New(t)
+	create(t, m, harness, 1)
+
+	entity := new(EntityType)
+	err := m.GetObject(&entity, NewContext("/", harness.MissingURIParams))
+	assert.True(t, manager.IsNotFound(err), "missing object must be not found, got %v", err)
+}
+
+func checkGetObjectsPaginates[EntityType, ValidateType any](t *testing.T, harness Harness[EntityType, ValidateType]) {
+	m := harness.New(t)
+	create(t, m, harness, 3)
+
+	entities, paginatedMeta := list(t, m, NewContext("/?limit=2&with_count=true", nil))
+	assert.Equal(t, 2, len(entities))
+	assert.EqualValues(t, 3, paginatedMeta["count"])
+	assert.Nil(t, paginatedMeta["previous"])
+	if assert.IsType(t, "", paginatedMeta["next"]) {
+		next, err := url.Parse(paginatedMeta["next"].(string))
+		require.NoError(t, err)
+		assert.Equal(t, "2", next.Query().Get("offset"))
+		assert.Equal(t, "2", next.Query().Get("limit"))
+	}
+
+	entities, paginatedMeta = list(t, m, NewContext("/?limit=2&offset=2", nil))
+	assert.Equal(t, 1, len(entities))
+	assert.NotContains(t, paginatedMeta, "count")
+	assert.Nil(t, paginatedMeta["next"])
+	if assert.IsType(t, "", paginatedMeta["previous"]) {
+		previous, err := url.Parse(paginatedMeta["previous"].(string))
+		require.NoError(t, err)
+		assert.Equal(t, "0", previous.Query().Get("offset"))
+	}
+
+	entities = make([]*EntityType, 0, 20)
+	paginatedMeta = map[string]any{}
+	assert.Error(t, m.GetObjects(&entities, &paginatedMeta, NewContext("/?limit=-1", nil)))
+}
+
+func checkDeleteRemoves[EntityType, ValidateType any](t *testing.T, harness Harness[EntityType, ValidateType]) {
+	m := harness.New(t)
+	created := create(t, m, harness, 2)
+
+	entity := new(EntityType)
+	require.NoError(t, m.GetObject(&entity, NewContext("/", harness.URIParams(created[0]))))
+	require.NoError(t, m.Delete(&entity, NewContext("/", nil)))
+
+	deleted := new(EntityType)
+	assert.Error(t, m.GetObject(&deleted, NewContext("/", harness.URIParams(created[0]))))
+	remaining := new(EntityType)
+	assert.NoError(t, m.GetObject(&remaining, NewContext("/", harness.URIParams(created[1]))))
+	entities, _ := list(t, m, NewContext("/", nil))
+	assert.Equal(t, 1, len(entities))
+}
+
+func checkScope[EntityType, ValidateType any](t *testing.T, harness Harness[EntityType, ValidateType]) {
+	if harness.OutOfScope == nil {
+		t.Skip("Harness.OutOfScope is not set")
+	}
+	m := harness.New(t)
+	entity := create(t, m, harness, 1)[0]
+
+	c := NewContext("/?with_count=true", nil)
+	harness.OutOfScope(c)
+	entities, paginatedMeta := list(t, m, c)
+	assert.Equal(t, 0, len(entities))
+	assert.EqualValues(t, 0, paginatedMeta["count"])
+
+	c = NewContext("/", harness.URIParams(entity))
+	harness.OutOfScope(c)
+	found := new(EntityType)
+	assert.Error(t, m.GetObject(&found, c))
+}
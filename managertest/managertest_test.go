@@ -0,0 +1,83 @@
+package managertest
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/TcMits/viewset/manager"
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type book struct {
+	ID     uint   `mapstructure:"id" gorm:"primary_key"`
+	Title  string `mapstructure:"title"`
+	Author string `mapstructure:"author"`
+}
+
+type bookRequest struct {
+	Title  string `mapstructure:"title"`
+	Author string `mapstructure:"author"`
+}
+
+type bookURI struct {
+	ID uint `mapstructure:"id" uri:"pk" binding:"required"`
+}
+
+func newHarness(newManager func(*testing.T) manager.Manager[book, bookRequest]) Harness[book, bookRequest] {
+	return Harness[book, bookRequest]{
+		New: newManager,
+		ValidatedData: func(i int) *bookRequest {
+			return &bookRequest{Title: fmt.Sprintf("book %d", i), Author: "phuc"}
+		},
+		Matches: func(entity *book, validatedData *bookRequest) bool {
+			return entity.Title == validatedData.Title && entity.Author == validatedData.Author
+		},
+		URIParams: func(entity *book) gin.Params {
+			return gin.Params{{Key: "pk", Value: strconv.Itoa(int(entity.ID))}}
+		},
+		MissingURIParams: gin.Params{{Key: "pk", Value: "999"}},
+		OutOfScope: func(c *gin.Context) {
+			c.Request.Header.Set("X-Author", "nobody")
+		},
+	}
+}
+
+func authorScope(c *gin.Context) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if author := c.GetHeader("X-Author"); author != "" {
+			return db.Where("author = ?", author)
+		}
+		return db
+	}
+}
+
+func TestGormManagerConformance(t *testing.T) {
+	Run(t, newHarness(func(t *testing.T) manager.Manager[book, bookRequest] {
+		db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{
+			Logger: logger.Default.LogMode(logger.Silent),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		db.AutoMigrate(&book{})
+		return manager.NewGormManager[book, bookRequest, bookURI](
+			db.Model(&book{}), nil, nil, nil, nil, "db", authorScope,
+		)
+	}))
+}
+
+func TestMemoryManagerConformance(t *testing.T) {
+	Run(t, newHarness(func(t *testing.T) manager.Manager[book, bookRequest] {
+		return manager.NewMemoryManager[book, bookRequest, bookURI](
+			"ID",
+			func(entity *book, c *gin.Context) bool {
+				author := c.GetHeader("X-Author")
+				return author == "" || entity.Author == author
+			},
+		)
+	}))
+}
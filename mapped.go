@@ -0,0 +1,62 @@
+package viewset
+
+import (
+	"github.com/TcMits/viewset/pkg/fieldset"
+	"github.com/gin-gonic/gin"
+)
+
+var _ Serializer[any] = &MappedSerializer[any]{}
+
+// MappedSerializer works like DefaultSerializer but converts entities with
+// MapInto, e.g. a function written by cmd/viewset-gen which yields the keys
+// mapstructure would without reflection.
+type MappedSerializer[EntityType any] struct {
+	DefaultSerializer[EntityType]
+	MapInto func(map[string]any, *EntityType)
+}
+
+func NewMappedSerializer[EntityType any](
+	mapInto func(map[string]any, *EntityType),
+	additionalField map[string]Field[EntityType],
+) *MappedSerializer[EntityType] {
+	if mapInto == nil {
+		panic("mapInto is required")
+	}
+	return &MappedSerializer[EntityType]{
+		DefaultSerializer: DefaultSerializer[EntityType]{AdditionalField: additionalField},
+		MapInto:           mapInto,
+	}
+}
+
+func (s *MappedSerializer[EntityType]) Serialize(
+	dest *map[string]any, entity *EntityType, c *gin.Context,
+) error {
+	return s.serialize(dest, entity, requestFieldset(c), requestExpand(c), c)
+}
+
+func (s *MappedSerializer[EntityType]) serialize(
+	dest *map[string]any, entity *EntityType, fields fieldset.Set, expand fieldset.Tree, c *gin.Context,
+) error {
+	if *dest == nil {
+		*dest = map[string]any{}
+	}
+	if entity != nil {
+		s.MapInto(*dest, entity)
+	}
+	return s.complete(dest, entity, fields, expand, c)
+}
+
+func (s *MappedSerializer[EntityType]) ManySerialize(
+	dest *[]map[string]any, entities *[]*EntityType, c *gin.Context,
+) error {
+	fields := requestFieldset(c)
+	expand := requestExpand(c)
+	for _, entity := range *entities {
+		var destObject map[string]any
+		if err := s.serialize(&destObject, entity, fields, expand, c); err != nil {
+			return err
+		}
+		*dest = append(*dest, destObject)
+	}
+	return nil
+}
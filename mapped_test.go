@@ -0,0 +1,50 @@
+package viewset
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func mapTestListing(dest map[string]any, value *testListing) {
+	dest["id"] = value.ID
+	dest["title"] = value.Title
+	dest["description"] = value.Description
+	dest["password"] = value.Password
+}
+
+func TestMappedSerializerSerialize(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/?omit=description", nil)
+	serializer := NewMappedSerializer(mapTestListing, map[string]Field[testListing]{"summary": &testListingSummary{}})
+	result := map[string]any{}
+
+	assert.NoError(t, serializer.Serialize(&result, newTestListings(1)[0], c))
+	assert.Equal(t, map[string]any{
+		"id":      uint(1),
+		"title":   "listing 0",
+		"summary": map[string]any{"name": "listing 0"},
+	}, result)
+}
+
+func TestMappedSerializerManySerialize(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/?fields=id", nil)
+	serializer := NewMappedSerializer(mapTestListing, nil)
+	listings := newTestListings(2)
+	result := []map[string]any{}
+
+	assert.NoError(t, serializer.ManySerialize(&result, &listings, c))
+	assert.Equal(t, []map[string]any{{"id": uint(1)}, {"id": uint(2)}}, result)
+}
+
+func TestNewMappedSerializerRequiresMapInto(t *testing.T) {
+	assert.PanicsWithValue(t, "mapInto is required", func() {
+		NewMappedSerializer[testListing](nil, nil)
+	})
+}
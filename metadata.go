@@ -0,0 +1,223 @@
+package viewset
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/TcMits/viewset/manager"
+	"github.com/TcMits/viewset/pkg/fieldrule"
+	"github.com/gin-gonic/gin"
+)
+
+const DEFAULT_METADATA_ACTION = "metadata"
+
+var _ FieldRuleDeclarer = &DefaultSerializer[any]{}
+
+var (
+	metadataTimeType   = reflect.TypeOf(time.Time{})
+	metadataValuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+)
+
+// Metadata answers the OPTIONS requests of a path of the ViewSet, Fields
+// are keyed like the serialized objects and never hold hidden fields, nor
+// the fields the Visibility of the serializer hides from the request.
+type Metadata struct {
+	Methods []string                 `json:"methods"`
+	Fields  map[string]FieldMetadata `json:"fields"`
+}
+
+type FieldMetadata struct {
+	Type     string `json:"type"`
+	Required bool   `json:"required,omitempty"`
+	// required by the binding tag of ValidateType
+	ReadOnly bool `json:"read_only,omitempty"`
+	// serialized, never written
+	WriteOnly bool `json:"write_only,omitempty"`
+	// written, never serialized
+}
+
+// FieldRuleOverrides returns the rules merged over those of EntityType.
+func (s *DefaultSerializer[EntityType]) FieldRuleOverrides() fieldrule.Rules {
+	return s.FieldRules
+}
+
+// declareFieldRules hands the rules of the serializer to the manager, so
+// writes follow the rules of the output and of OPTIONS.
+func (viewSet *ViewSet[_, _]) declareFieldRules(c *gin.Context) {
+	if declarer, ok := viewSet.Serializer.(FieldRuleDeclarer); ok {
+		if rules := declarer.FieldRuleOverrides(); len(rules) > 0 {
+			manager.SetFieldRules(c, rules)
+		}
+	}
+}
+
+// metadataPaths returns the sub paths of the actions which have no OPTIONS
+// route, in the order of the actions.
+func (viewSet *ViewSet[_, _]) metadataPaths() []string {
+	paths := []string{}
+	seen := map[string]bool{}
+	for _, route := range viewSet.Actions {
+		if route.Method == http.MethodOptions {
+			seen[route.SubPath] = true
+		}
+	}
+	for _, route := range viewSet.Actions {
+		if !seen[route.SubPath] {
+			seen[route.SubPath] = true
+			paths = append(paths, route.SubPath)
+		}
+	}
+	return paths
+}
+
+// NewMetadata returns the OPTIONS handler of a sub path, describing the
+// methods of its routes and the fields of EntityType and ValidateType.
+func NewMetadata[EntityType, ValidateType any](subPath string) HandlerWithViewSetFunc[EntityType, ValidateType] {
+	return func(action string, viewSet *ViewSet[EntityType, ValidateType], c *gin.Context) {
+		methods := []string{}
+		for _, route := range viewSet.Actions {
+			if route.SubPath == subPath && !containsString(methods, route.Method) {
+				methods = append(methods, route.Method)
+			}
+		}
+		methods = append(methods, http.MethodOptions)
+
+		rules := fieldrule.For[EntityType]()
+		if declarer, ok := viewSet.Serializer.(FieldRuleDeclarer); ok {
+			rules = rules.Merge(declarer.FieldRuleOverrides())
+		}
+		metadata := &Metadata{
+			Methods: methods,
+			Fields: describeFields(
+				reflect.TypeOf(new(EntityType)).Elem(), reflect.TypeOf(new(ValidateType)).Elem(), rules,
+			),
+		}
+		if declarer, ok := viewSet.Serializer.(VisibilityDeclarer[EntityType]); ok {
+			// evaluated without an entity, like for the input of a create
+			hidden := declarer.FieldVisibility().hidden(nil, c)
+			for key := range metadata.Fields {
+				if isHidden(hidden, key) {
+					delete(metadata.Fields, key)
+				}
+			}
+		}
+		viewSet.observe(action, RENDER_PHASE, c, func() error {
+			c.Header("Allow", strings.Join(methods, ", "))
+			c.JSON(http.StatusOK, metadata)
+			return nil
+		})
+	}
+}
+
+// describeFields merges the serialized keys of the entity with the keys of
+// the validated data, matched case-insensitively like the field rules.
+func describeFields(entityType reflect.Type, validateType reflect.Type, rules fieldrule.Rules) map[string]FieldMetadata {
+	inputs := map[string]reflect.StructField{}
+	walkFields(validateType, "json", func(key string, field reflect.StructField) {
+		inputs[strings.ToLower(key)] = field
+	})
+
+	fields := map[string]FieldMetadata{}
+	walkFields(entityType, "mapstructure", func(key string, field reflect.StructField) {
+		readable, writable := rules.Readable(key), rules.Writable(key)
+		if !readable && !writable {
+			return
+		}
+		input, isInput := inputs[strings.ToLower(key)]
+		delete(inputs, strings.ToLower(key))
+		fields[key] = FieldMetadata{
+			Type:      metadataType(field.Type),
+			Required:  isInput && writable && isRequired(input),
+			ReadOnly:  !writable || !isInput,
+			WriteOnly: !readable,
+		}
+	})
+	walkFields(validateType, "json", func(key string, field reflect.StructField) {
+		if _, ok := inputs[strings.ToLower(key)]; !ok || !rules.Writable(key) {
+			return
+		}
+		fields[key] = FieldMetadata{
+			Type:      metadataType(field.Type),
+			Required:  isRequired(field),
+			WriteOnly: true,
+		}
+	})
+	return fields
+}
+
+// walkFields calls fn with the exported fields of a struct, named by the tag,
+// flattening squashed and untagged embedded structs.
+func walkFields(t reflect.Type, tag string, fn func(string, reflect.StructField)) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		options := strings.Split(field.Tag.Get(tag), ",")
+		if options[0] == "-" {
+			continue
+		}
+		if field.Anonymous && (options[0] == "" || containsString(options[1:], "squash")) {
+			walkFields(field.Type, tag, fn)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		key := options[0]
+		if key == "" {
+			key = field.Name
+		}
+		fn(key, field)
+	}
+}
+
+func isRequired(field reflect.StructField) bool {
+	return containsString(strings.Split(field.Tag.Get("binding"), ","), "required")
+}
+
+func metadataType(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == metadataTimeType:
+		return "datetime"
+	case t.Implements(metadataValuerType) || reflect.PointerTo(t).Implements(metadataValuerType):
+		return "any"
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "string"
+		}
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	}
+	return "any"
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
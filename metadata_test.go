@@ -0,0 +1,162 @@
+package viewset
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TcMits/viewset/manager"
+	"github.com/TcMits/viewset/pkg/fieldrule"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type testSignup struct {
+	ID        uint      `mapstructure:"id" viewset:"read_only"`
+	Name      string    `mapstructure:"name"`
+	Password  string    `mapstructure:"password" viewset:"write_only"`
+	Hash      string    `mapstructure:"hash" viewset:"hidden"`
+	Score     float64   `mapstructure:"score"`
+	Tags      []string  `mapstructure:"tags"`
+	CreatedAt time.Time `mapstructure:"created_at"`
+}
+
+type testSignupRequest struct {
+	Name     string `json:"name" binding:"required"`
+	Password string `json:"password"`
+	Hash     string `json:"hash"`
+	Invite   string `json:"invite"`
+}
+
+type testSignupURI struct {
+	ID uint `uri:"pk"`
+}
+
+func serveTestMetadata(t *testing.T, viewSet *ViewSet[testSignup, testSignupRequest], target string) (*httptest.ResponseRecorder, Metadata) {
+	router := SetUpRouter()
+	viewSet.Register(router)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, target, nil))
+	metadata := Metadata{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &metadata))
+	return w, metadata
+}
+
+func TestViewSetMetadata(t *testing.T) {
+	profileManager := manager.NewMemoryManager[testSignup, testSignupRequest, testSignupURI]("ID")
+	viewSet := NewViewSet[testSignup, testSignupRequest](
+		"/signups", "/:pk", nil, nil, profileManager, nil, nil, nil, nil,
+	)
+
+	w, metadata := serveTestMetadata(t, viewSet, "/signups/")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "GET, POST, OPTIONS", w.Header().Get("Allow"))
+	assert.Equal(t, []string{http.MethodGet, http.MethodPost, http.MethodOptions}, metadata.Methods)
+	assert.Equal(t, map[string]FieldMetadata{
+		"id":         {Type: "integer", ReadOnly: true},
+		"name":       {Type: "string", Required: true},
+		"password":   {Type: "string", WriteOnly: true},
+		"score":      {Type: "number", ReadOnly: true},
+		"tags":       {Type: "array", ReadOnly: true},
+		"created_at": {Type: "datetime", ReadOnly: true},
+		"invite":     {Type: "string", WriteOnly: true},
+	}, metadata.Fields)
+
+	w, metadata = serveTestMetadata(t, viewSet, "/signups/1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{
+		http.MethodGet, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions,
+	}, metadata.Methods)
+}
+
+func TestViewSetMetadataWithSerializerRules(t *testing.T) {
+	profileManager := manager.NewMemoryManager[testSignup, testSignupRequest, testSignupURI]("ID")
+	viewSet := NewViewSet[testSignup, testSignupRequest](
+		"/signups", "/:pk", nil, nil, profileManager, nil, nil,
+		&DefaultSerializer[testSignup]{FieldRules: fieldrule.Rules{"name": {ReadOnly: true}, "score": {Hidden: true}}},
+		nil,
+	)
+
+	_, metadata := serveTestMetadata(t, viewSet, "/signups/")
+	assert.Equal(t, FieldMetadata{Type: "string", ReadOnly: true}, metadata.Fields["name"])
+	assert.NotContains(t, metadata.Fields, "score")
+	assert.NotContains(t, metadata.Fields, "hash")
+}
+
+func TestViewSetMetadataHidesInvisibleFields(t *testing.T) {
+	signupManager := manager.NewMemoryManager[testSignup, testSignupRequest, testSignupURI]("ID")
+	hidden := PredicateRule[testSignup](func(_ *testSignup, c *gin.Context) bool {
+		return c.GetHeader("X-Role") == "admin"
+	})
+	viewSet := NewViewSet[testSignup, testSignupRequest](
+		"/signups", "/:pk", nil, nil, signupManager, nil, nil,
+		&DefaultSerializer[testSignup]{Visibility: FieldVisibility[testSignup]{
+			Rules: map[string]VisibilityRule[testSignup]{"score": hidden, "Invite": hidden},
+		}},
+		nil,
+	)
+
+	_, metadata := serveTestMetadata(t, viewSet, "/signups/")
+	assert.NotContains(t, metadata.Fields, "score")
+	assert.NotContains(t, metadata.Fields, "invite")
+	assert.Contains(t, metadata.Fields, "name")
+}
+
+type testRuledArticle struct {
+	ID    uint   `mapstructure:"id" viewset:"read_only"`
+	Title string `mapstructure:"title"`
+	Slug  string `mapstructure:"slug"`
+}
+
+type testRuledArticleRequest struct {
+	Title string `json:"title" mapstructure:"title"`
+	Slug  string `json:"slug" mapstructure:"slug"`
+}
+
+type testRuledArticleURI struct {
+	ID uint `uri:"pk"`
+}
+
+func TestViewSetWritesFollowSerializerRules(t *testing.T) {
+	db, err := gorm.Open(
+		sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())),
+		&gorm.Config{Logger: logger.Default.LogMode(logger.Silent)},
+	)
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&testRuledArticle{}))
+	articleManager := manager.NewGormManager[testRuledArticle, testRuledArticleRequest, testRuledArticleURI](
+		db.Model(&testRuledArticle{}), nil, nil, nil, nil, "db",
+	)
+	viewSet := NewViewSet[testRuledArticle, testRuledArticleRequest](
+		"/articles", "/:pk", nil, nil, articleManager, nil, nil,
+		&DefaultSerializer[testRuledArticle]{FieldRules: fieldrule.Rules{"slug": {ReadOnly: true}}},
+		nil,
+	)
+	router := SetUpRouter()
+	viewSet.Register(router)
+
+	for _, method := range []string{http.MethodPost, http.MethodPatch} {
+		target := "/articles/"
+		if method == http.MethodPatch {
+			target = "/articles/1"
+		}
+		req := httptest.NewRequest(method, target, strings.NewReader(`{"title":"first","slug":"forged"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Less(t, w.Code, 300, method)
+
+		stored := testRuledArticle{}
+		require.NoError(t, db.First(&stored, 1).Error)
+		assert.Equal(t, "first", stored.Title, method)
+		assert.Equal(t, "", stored.Slug, method)
+	}
+}
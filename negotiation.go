@@ -0,0 +1,109 @@
+package viewset
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+var _ Renderer = &NegotiatedRenderer{}
+var _ QueryValidator = &NegotiatedRenderer{}
+
+// NegotiatedRenderer picks a renderer by the Accept header of the request,
+// Default renders when no media type matches.
+type NegotiatedRenderer struct {
+	Default          Renderer
+	DefaultMediaType string
+	Renderers        map[string]Renderer
+	// media type -> renderer
+}
+
+func NewNegotiatedRenderer(defaultRenderer Renderer, renderers map[string]Renderer) *NegotiatedRenderer {
+	if defaultRenderer == nil {
+		defaultRenderer = &DefaultRenderer{}
+	}
+	return &NegotiatedRenderer{
+		Default:          defaultRenderer,
+		DefaultMediaType: binding.MIMEJSON,
+		Renderers:        renderers,
+	}
+}
+
+// negotiate returns the renderer of the accepted media type with the highest
+// quality, the first one on ties, wildcards select Default and q=0 refuses a
+// media type.
+func (r *NegotiatedRenderer) negotiate(c *gin.Context) Renderer {
+	if c.Request == nil {
+		return r.Default
+	}
+
+	type preference struct {
+		mediaType string
+		quality   float64
+	}
+	preferences := []preference{}
+	for _, accepted := range strings.Split(c.GetHeader("Accept"), ",") {
+		params := strings.Split(accepted, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+		quality := 1.0
+		valid := true
+		for _, param := range params[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.ToLower(strings.TrimSpace(key)) != "q" {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				valid = false
+				break
+			}
+			quality = parsed
+		}
+		if !valid || mediaType == "" || quality <= 0 {
+			continue
+		}
+		preferences = append(preferences, preference{mediaType, quality})
+	}
+	sort.SliceStable(preferences, func(i, j int) bool {
+		return preferences[i].quality > preferences[j].quality
+	})
+
+	for _, preference := range preferences {
+		if preference.mediaType == r.DefaultMediaType || strings.HasSuffix(preference.mediaType, "/*") {
+			return r.Default
+		}
+		if renderer, ok := r.Renderers[preference.mediaType]; ok {
+			return renderer
+		}
+	}
+	return r.Default
+}
+
+// ValidateQuery validates the query params with the negotiated renderer.
+func (r *NegotiatedRenderer) ValidateQuery(c *gin.Context) error {
+	if validator, ok := r.negotiate(c).(QueryValidator); ok {
+		return validator.ValidateQuery(c)
+	}
+	return nil
+}
+
+func (r *NegotiatedRenderer) Render(statusCode int, response *map[string]any, c *gin.Context) error {
+	varyAccept(c)
+	return r.negotiate(c).Render(statusCode, response, c)
+}
+
+func (r *NegotiatedRenderer) RenderMany(
+	statusCode int, manyResponse *[]map[string]any, paginatedMeta *map[string]any, c *gin.Context,
+) error {
+	varyAccept(c)
+	return r.negotiate(c).RenderMany(statusCode, manyResponse, paginatedMeta, c)
+}
+
+// varyAccept tells caches the response differs by Accept, added so
+// Vary: Accept-Language is kept.
+func varyAccept(c *gin.Context) {
+	c.Writer.Header().Add("Vary", "Accept")
+}
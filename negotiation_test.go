@@ -0,0 +1,100 @@
+package viewset
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type testMediaTypeRenderer struct {
+	DefaultRenderer
+	mediaType string
+}
+
+func (r *testMediaTypeRenderer) Render(statusCode int, response *map[string]any, c *gin.Context) error {
+	c.Header("Content-Type", r.mediaType)
+	return r.DefaultRenderer.Render(statusCode, response, c)
+}
+
+func TestNegotiatedRenderer(t *testing.T) {
+	halRenderer := &testMediaTypeRenderer{mediaType: "application/hal+json"}
+	renderer := NewNegotiatedRenderer(nil, map[string]Renderer{"application/hal+json": halRenderer})
+	assert.Equal(t, &DefaultRenderer{}, renderer.Default)
+
+	cases := []struct {
+		accept   string
+		expected Renderer
+	}{
+		{"", renderer.Default},
+		{"application/hal+json", halRenderer},
+		{"text/html, Application/HAL+JSON; q=0.9", halRenderer},
+		{"application/json, application/hal+json", renderer.Default},
+		{"*/*, application/hal+json", renderer.Default},
+		{"text/html", renderer.Default},
+		{"application/hal+json;q=0", renderer.Default},
+		{"application/hal+json; q=0, */*", renderer.Default},
+		{"application/json; q=0.5, application/hal+json", halRenderer},
+		{"*/*; q=0.1, application/hal+json; q=0.8", halRenderer},
+		{"application/json; q=0, application/hal+json; q=0.2", halRenderer},
+		{"application/hal+json; q=bad, text/html", renderer.Default},
+	}
+	for _, tc := range cases {
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		c.Request.Header.Set("Accept", tc.accept)
+		assert.Same(t, tc.expected, renderer.negotiate(c), tc.accept)
+	}
+}
+
+func TestNegotiatedRendererRender(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("Accept", "application/hal+json")
+	renderer := NewNegotiatedRenderer(nil, map[string]Renderer{
+		"application/hal+json": &testMediaTypeRenderer{mediaType: "application/hal+json"},
+	})
+
+	response := map[string]any{"name": "test"}
+	c.Writer.Header().Add("Vary", "Accept-Language")
+	assert.NoError(t, renderer.Render(http.StatusOK, &response, c))
+	assert.Equal(t, "application/hal+json", w.Header().Get("Content-Type"))
+	assert.Equal(t, []string{"Accept-Language", "Accept"}, w.Header().Values("Vary"))
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	manyResponse := []map[string]any{response}
+	assert.NoError(t, renderer.RenderMany(http.StatusOK, &manyResponse, &map[string]any{}, c))
+	assert.Equal(t, `{"meta":{},"results":[{"name":"test"}]}`, w.Body.String())
+}
+
+type testQueryRenderer struct {
+	testMediaTypeRenderer
+}
+
+func (r *testQueryRenderer) ValidateQuery(c *gin.Context) error {
+	if c.Query("include") != "" {
+		return errors.New("cannot include")
+	}
+	return nil
+}
+
+func TestNegotiatedRendererValidateQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	renderer := NewNegotiatedRenderer(nil, map[string]Renderer{
+		"application/vnd.api+json": &testQueryRenderer{},
+	})
+	for accept, fails := range map[string]bool{"application/vnd.api+json": true, "application/json": false} {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, "/?include=comments", nil)
+		c.Request.Header.Set("Accept", accept)
+		assert.Equal(t, fails, renderer.ValidateQuery(c) != nil, accept)
+	}
+}
@@ -1,9 +1,28 @@
 package viewset
 
-import "github.com/gin-gonic/gin"
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrPermissionDenied is answered with 403 and the permission denied
+// message in the locale of the request.
+var ErrPermissionDenied = errors.New("permission denied")
 
 var _ PermissionChecker = &AllowAny{}
 
+// permissionError keeps the status of a ViewSetError returned by a
+// PermissionChecker, other errors are answered with 403.
+func permissionError(err error, c *gin.Context) *ViewSetError {
+	viewSetErr := new(ViewSetError)
+	if !errors.As(err, &viewSetErr) && errors.Is(err, ErrPermissionDenied) {
+		return NewLocalizedError(c, "permission_denied", http.StatusForbidden, err)
+	}
+	return asViewSetError(err, http.StatusForbidden)
+}
+
 type AllowAny struct{}
 
 func (_ *AllowAny) Check(_ string, _ *gin.Context) error {
@@ -1,6 +1,9 @@
 package viewset
 
 import (
+	"errors"
+	"fmt"
+	"net/http"
 	"net/http/httptest"
 	"testing"
 
@@ -17,3 +20,20 @@ func TestAllowAnyCheck(t *testing.T) {
 
 	assert.Equal(t, nil, err)
 }
+
+func TestPermissionError(t *testing.T) {
+	c := newTestFieldContext("/notes/")
+
+	err := permissionError(fmt.Errorf("notes: %w", ErrPermissionDenied), c)
+	assert.Equal(t, http.StatusForbidden, err.StatusCode)
+	assert.EqualError(t, err, "permission denied")
+	assert.ErrorIs(t, err, ErrPermissionDenied)
+
+	err = permissionError(errors.New("staff only"), c)
+	assert.Equal(t, http.StatusForbidden, err.StatusCode)
+	assert.EqualError(t, err, "staff only")
+
+	err = permissionError(NewViewSetError("login required", http.StatusUnauthorized, ErrPermissionDenied), c)
+	assert.Equal(t, http.StatusUnauthorized, err.StatusCode)
+	assert.EqualError(t, err, "login required")
+}
@@ -0,0 +1,278 @@
+// Package fieldplan converts structs to maps with a plan built once per
+// type, instead of walking every value with reflection like mapstructure.
+package fieldplan
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	valuerType        = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+type valueKind int
+
+const (
+	// kept as it is
+	plainValue valueKind = iota
+	// converted with driver.Valuer, like sql.NullString
+	valuerValue
+	// converted to a map with its own plan
+	structValue
+	// converted to a slice of maps
+	structSliceValue
+)
+
+type field struct {
+	name      string
+	index     []int
+	omitEmpty bool
+	kind      valueKind
+	pointer   bool
+	// the value is a pointer, nil pointers are converted to nil
+	elem *Plan
+	// plan of the struct or slice element
+}
+
+// Plan holds the keys of a struct type and how to convert their values.
+type Plan struct {
+	fields []field
+}
+
+var (
+	cache   sync.Map // reflect.Type -> *Plan
+	buildMu sync.Mutex
+)
+
+// Of returns the plan of a struct type or a pointer to one, nil for other
+// types.
+func Of(t reflect.Type) *Plan {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	if plan, ok := cache.Load(t); ok {
+		return plan.(*Plan)
+	}
+	buildMu.Lock()
+	defer buildMu.Unlock()
+	building := map[reflect.Type]*Plan{}
+	plan := build(t, building)
+	// published once complete, nested plans may point back to plan
+	for builtType, builtPlan := range building {
+		cache.Store(builtType, builtPlan)
+	}
+	return plan
+}
+
+func build(t reflect.Type, building map[reflect.Type]*Plan) *Plan {
+	if plan, ok := cache.Load(t); ok {
+		return plan.(*Plan)
+	}
+	if plan, ok := building[t]; ok {
+		return plan
+	}
+	plan := &Plan{}
+	building[t] = plan
+	plan.fields = collect(t, nil, building)
+	return plan
+}
+
+// For returns the plan of the type parameter.
+func For[T any]() *Plan {
+	return Of(reflect.TypeOf(new(T)))
+}
+
+// Len returns the number of keys a converted value may have.
+func (plan *Plan) Len() int {
+	return len(plan.fields)
+}
+
+// fieldName reads the key from the mapstructure tag, then the json tag,
+// then the field name. Embedded structs are flattened when squashed or when
+// neither tag names them.
+func fieldName(structField reflect.StructField) (name string, omitEmpty bool, flatten bool, skip bool) {
+	named := false
+	for _, key := range []string{"mapstructure", "json"} {
+		tag, ok := structField.Tag.Lookup(key)
+		if !ok {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		if parts[0] == "-" && len(parts) == 1 {
+			return "", false, false, true
+		}
+		for _, option := range parts[1:] {
+			switch option {
+			case "omitempty":
+				omitEmpty = true
+			case "squash", "inline":
+				flatten = true
+			}
+		}
+		if parts[0] != "" {
+			name, named = parts[0], true
+		}
+		break
+	}
+	if !named {
+		name = structField.Name
+		flatten = flatten || structField.Anonymous
+	}
+	return name, omitEmpty, flatten, false
+}
+
+func collect(t reflect.Type, index []int, building map[reflect.Type]*Plan) []field {
+	fields := make([]field, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+		name, omitEmpty, flatten, skip := fieldName(structField)
+		if skip {
+			continue
+		}
+		fieldIndex := append(append([]int{}, index...), i)
+		fieldType := structField.Type
+		if flatten && structField.Anonymous {
+			embedded := fieldType
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				fields = append(fields, collect(embedded, fieldIndex, building)...)
+				continue
+			}
+		}
+		if !structField.IsExported() {
+			continue
+		}
+		f := field{name: name, index: fieldIndex, omitEmpty: omitEmpty}
+		if fieldType.Kind() == reflect.Pointer {
+			f.pointer = true
+			fieldType = fieldType.Elem()
+		}
+		f.kind, f.elem = kindOf(fieldType, building)
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+func kindOf(t reflect.Type, building map[reflect.Type]*Plan) (valueKind, *Plan) {
+	switch {
+	case t.Implements(valuerType) || reflect.PointerTo(t).Implements(valuerType):
+		return valuerValue, nil
+	case t == timeType || t.Implements(jsonMarshalerType):
+		return plainValue, nil
+	case t.Kind() == reflect.Struct:
+		return structValue, build(t, building)
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		elem := t.Elem()
+		if elem.Kind() == reflect.Pointer {
+			elem = elem.Elem()
+		}
+		if elem.Kind() == reflect.Struct && elem != timeType && !elem.Implements(jsonMarshalerType) &&
+			!elem.Implements(valuerType) {
+			return structSliceValue, build(elem, building)
+		}
+	}
+	return plainValue, nil
+}
+
+// Map converts a struct, or a pointer to one, to a map sized for the plan
+// and extra keys.
+func (plan *Plan) Map(value reflect.Value, extra int) map[string]any {
+	dest := make(map[string]any, len(plan.fields)+extra)
+	plan.MapInto(dest, value)
+	return dest
+}
+
+// MapInto writes the keys of a struct, or a pointer to one, into dest.
+func (plan *Plan) MapInto(dest map[string]any, value reflect.Value) {
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+	for i := range plan.fields {
+		f := &plan.fields[i]
+		fieldValue, ok := fieldByIndex(value, f.index)
+		if !ok {
+			continue
+		}
+		if f.omitEmpty && fieldValue.IsZero() {
+			continue
+		}
+		dest[f.name] = f.convert(fieldValue)
+	}
+}
+
+// fieldByIndex walks embedded pointers, reporting false on a nil one.
+func fieldByIndex(value reflect.Value, index []int) (reflect.Value, bool) {
+	for i, fieldIndex := range index {
+		if i > 0 && value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				return reflect.Value{}, false
+			}
+			value = value.Elem()
+		}
+		value = value.Field(fieldIndex)
+	}
+	return value, true
+}
+
+func (f *field) convert(value reflect.Value) any {
+	if f.pointer {
+		if value.IsNil() {
+			return nil
+		}
+		if f.kind == plainValue {
+			return value.Interface()
+		}
+		value = value.Elem()
+	}
+	switch f.kind {
+	case valuerValue:
+		converted, err := valueOf(value)
+		if err != nil {
+			return value.Interface()
+		}
+		return converted
+	case structValue:
+		return f.elem.Map(value, 0)
+	case structSliceValue:
+		if value.Kind() == reflect.Slice && value.IsNil() {
+			return nil
+		}
+		objects := make([]any, value.Len())
+		for i := range objects {
+			item := value.Index(i)
+			if item.Kind() == reflect.Pointer && item.IsNil() {
+				continue
+			}
+			objects[i] = f.elem.Map(item, 0)
+		}
+		return objects
+	}
+	return value.Interface()
+}
+
+func valueOf(value reflect.Value) (driver.Value, error) {
+	if valuer, ok := value.Interface().(driver.Valuer); ok {
+		return valuer.Value()
+	}
+	if value.CanAddr() {
+		return value.Addr().Interface().(driver.Valuer).Value()
+	}
+	pointer := reflect.New(value.Type())
+	pointer.Elem().Set(value)
+	return pointer.Interface().(driver.Valuer).Value()
+}
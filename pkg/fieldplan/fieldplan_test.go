@@ -0,0 +1,114 @@
+package fieldplan
+
+import (
+	"database/sql"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type base struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Audit struct {
+	By string `mapstructure:"by"`
+}
+
+type tag struct {
+	Name string `mapstructure:"name"`
+}
+
+type node struct {
+	Name     string  `json:"name"`
+	Children []*node `json:"children,omitempty"`
+}
+
+type article struct {
+	base
+	Audit    `mapstructure:"audit"`
+	Title    string         `mapstructure:"title" json:"ignored"`
+	Summary  string         `json:"summary,omitempty"`
+	Secret   string         `json:"-"`
+	Subtitle *string        `json:"subtitle"`
+	Rating   sql.NullInt64  `json:"rating"`
+	Note     sql.NullString `json:"note"`
+	Main     *tag           `json:"main"`
+	Tags     []tag          `json:"tags"`
+	Words    []string       `json:"words"`
+	private  string
+}
+
+func TestPlanMap(t *testing.T) {
+	created := time.Date(2022, 12, 1, 0, 0, 0, 0, time.UTC)
+	subtitle := "sub"
+	entity := &article{
+		base:     base{ID: 1, CreatedAt: created},
+		Audit:    Audit{By: "phuc"},
+		Title:    "first",
+		Secret:   "x",
+		Subtitle: &subtitle,
+		Rating:   sql.NullInt64{Int64: 5, Valid: true},
+		Main:     &tag{Name: "go"},
+		Tags:     []tag{{Name: "a"}, {Name: "b"}},
+		Words:    []string{"hello"},
+		private:  "p",
+	}
+
+	assert.Equal(t, map[string]any{
+		"id":         uint(1),
+		"created_at": created,
+		"audit":      map[string]any{"by": "phuc"},
+		"title":      "first",
+		"subtitle":   &subtitle,
+		"rating":     int64(5),
+		"note":       nil,
+		"main":       map[string]any{"name": "go"},
+		"tags":       []any{map[string]any{"name": "a"}, map[string]any{"name": "b"}},
+		"words":      []string{"hello"},
+	}, For[article]().Map(reflect.ValueOf(entity), 0))
+	assert.Equal(t, 11, For[article]().Len())
+
+	entity.Summary = "short"
+	entity.Main = nil
+	entity.Tags = nil
+	object := For[article]().Map(reflect.ValueOf(*entity), 0)
+	assert.Equal(t, "short", object["summary"])
+	assert.Nil(t, object["main"])
+	assert.Nil(t, object["tags"])
+}
+
+func TestPlanRecursiveType(t *testing.T) {
+	tree := &node{Name: "root", Children: []*node{{Name: "leaf"}, nil}}
+
+	assert.Equal(t, map[string]any{
+		"name":     "root",
+		"children": []any{map[string]any{"name": "leaf"}, nil},
+	}, For[node]().Map(reflect.ValueOf(tree), 0))
+}
+
+func TestPlanOf(t *testing.T) {
+	assert.Nil(t, Of(reflect.TypeOf(1)))
+	assert.Same(t, For[article](), Of(reflect.TypeOf(&article{})))
+
+	type concurrent struct {
+		Name string
+	}
+	plans := make([]*Plan, 8)
+	var wg sync.WaitGroup
+	for i := range plans {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			plans[i] = For[concurrent]()
+		}(i)
+	}
+	wg.Wait()
+	for _, plan := range plans {
+		assert.Same(t, plans[0], plan)
+	}
+}
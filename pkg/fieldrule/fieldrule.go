@@ -0,0 +1,313 @@
+// Package fieldrule reads which fields of an entity are read-only,
+// write-only or hidden, from `viewset:"..."` struct tags and from the rules
+// the type declares itself.
+package fieldrule
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+const (
+	TAG = "viewset"
+
+	READ_ONLY  = "read_only"
+	WRITE_ONLY = "write_only"
+	HIDDEN     = "hidden"
+)
+
+type Rule struct {
+	ReadOnly bool
+	// serialized, never assigned from validated data
+	WriteOnly bool
+	// assigned from validated data, never serialized
+	Hidden bool
+	// neither serialized nor assigned
+}
+
+// Rules maps the keys of a type, as named by mapstructure, to their rule.
+type Rules map[string]Rule
+
+// Declarer lets a type declare rules without struct tags, they are merged
+// over the tags.
+type Declarer interface {
+	FieldRules() Rules
+}
+
+var (
+	cache  sync.Map // reflect.Type -> Rules
+	nested sync.Map // reflect.Type -> bool
+)
+
+// Of returns the rules of a struct type or a pointer to one, nil if it has
+// none.
+func Of(t reflect.Type) Rules {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	if rules, ok := cache.Load(t); ok {
+		return rules.(Rules)
+	}
+	rules := Rules{}
+	collect(t, rules)
+	if declarer, ok := reflect.New(t).Interface().(Declarer); ok {
+		rules = rules.Merge(declarer.FieldRules())
+	}
+	if len(rules) == 0 {
+		rules = nil
+	}
+	cache.Store(t, rules)
+	return rules
+}
+
+// For returns the rules of the type parameter.
+func For[T any]() Rules {
+	return Of(reflect.TypeOf(new(T)))
+}
+
+func collect(t reflect.Type, rules Rules) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, squash := mapstructureName(field)
+		if squash {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				collect(embedded, rules)
+			}
+			continue
+		}
+		if rule, ok := parse(field.Tag.Get(TAG)); ok {
+			rules[name] = rule
+		}
+	}
+}
+
+func mapstructureName(field reflect.StructField) (string, bool) {
+	parts := strings.Split(field.Tag.Get("mapstructure"), ",")
+	for _, option := range parts[1:] {
+		if option == "squash" {
+			return "", true
+		}
+	}
+	if parts[0] == "" {
+		return field.Name, false
+	}
+	return parts[0], false
+}
+
+func parse(tag string) (Rule, bool) {
+	var rule Rule
+	found := false
+	for _, option := range strings.Split(tag, ",") {
+		switch strings.TrimSpace(option) {
+		case READ_ONLY:
+			rule.ReadOnly, found = true, true
+		case WRITE_ONLY:
+			rule.WriteOnly, found = true, true
+		case HIDDEN:
+			rule.Hidden, found = true, true
+		}
+	}
+	return rule, found
+}
+
+// Merge returns the rules with the other rules replacing those of the same
+// key.
+func (rules Rules) Merge(other Rules) Rules {
+	if len(other) == 0 {
+		return rules
+	}
+	merged := make(Rules, len(rules)+len(other))
+	for key, rule := range rules {
+		merged[key] = rule
+	}
+	for key, rule := range other {
+		merged[key] = rule
+	}
+	return merged
+}
+
+// lookup matches keys case-insensitively, validated data and entities may
+// name a field differently, like name and Name.
+func (rules Rules) lookup(key string) Rule {
+	if rule, ok := rules[key]; ok {
+		return rule
+	}
+	for ruleKey, rule := range rules {
+		if strings.EqualFold(ruleKey, key) {
+			return rule
+		}
+	}
+	return Rule{}
+}
+
+func (rules Rules) Readable(key string) bool {
+	rule := rules.lookup(key)
+	return !rule.WriteOnly && !rule.Hidden
+}
+
+func (rules Rules) Writable(key string) bool {
+	rule := rules.lookup(key)
+	return !rule.ReadOnly && !rule.Hidden
+}
+
+// FilterReadable removes the keys which must not be serialized.
+func (rules Rules) FilterReadable(object map[string]any) {
+	if len(rules) == 0 {
+		return
+	}
+	for key := range object {
+		if !rules.Readable(key) {
+			delete(object, key)
+		}
+	}
+}
+
+// FilterWritable removes the keys which must not be assigned.
+func (rules Rules) FilterWritable(object map[string]any) {
+	if len(rules) == 0 {
+		return
+	}
+	for key := range object {
+		if !rules.Writable(key) {
+			delete(object, key)
+		}
+	}
+}
+
+// FilterReadableOf removes the keys which must not be serialized from
+// object, decoded from a value of type t, then from its nested objects and
+// lists of objects with the rules of their own types. Structs left in lists
+// by the decoder are converted to maps when their type has rules.
+func (rules Rules) FilterReadableOf(t reflect.Type, object map[string]any) {
+	rules.FilterReadable(object)
+	t = indirect(t)
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	for key, value := range object {
+		field, ok := fieldByKey(t, key)
+		if !ok || !hasRules(field.Type, map[reflect.Type]bool{}) {
+			continue
+		}
+		object[key] = filterReadableValue(field.Type, value)
+	}
+}
+
+func filterReadableValue(t reflect.Type, value any) any {
+	t = indirect(t)
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case map[string]any:
+		Of(t).FilterReadableOf(t, v)
+		return v
+	case []map[string]any:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return v
+		}
+		for _, object := range v {
+			filterReadableValue(t.Elem(), object)
+		}
+		return v
+	case []any:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return v
+		}
+		for i, item := range v {
+			v[i] = filterReadableValue(t.Elem(), item)
+		}
+		return v
+	}
+
+	reflected := reflect.ValueOf(value)
+	switch reflected.Kind() {
+	case reflect.Struct, reflect.Pointer:
+		if indirect(reflected.Type()).Kind() != reflect.Struct {
+			return value
+		}
+		if reflected.Kind() == reflect.Pointer && reflected.IsNil() {
+			return nil
+		}
+		object := map[string]any{}
+		if err := mapstructure.Decode(value, &object); err != nil {
+			return value
+		}
+		return filterReadableValue(t, object)
+	case reflect.Slice, reflect.Array:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return value
+		}
+		if reflected.Kind() == reflect.Slice && reflected.IsNil() {
+			return value
+		}
+		items := make([]any, reflected.Len())
+		for i := range items {
+			items[i] = filterReadableValue(t.Elem(), reflected.Index(i).Interface())
+		}
+		return items
+	}
+	return value
+}
+
+// hasRules tells if the type, its fields or their elements have rules.
+func hasRules(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || visiting[t] {
+		return false
+	}
+	if found, ok := nested.Load(t); ok {
+		return found.(bool)
+	}
+	visiting[t] = true
+	found := Of(t) != nil
+	for i := 0; i < t.NumField() && !found; i++ {
+		if t.Field(i).IsExported() || t.Field(i).Anonymous {
+			found = hasRules(t.Field(i).Type, visiting)
+		}
+	}
+	nested.Store(t, found)
+	return found
+}
+
+// fieldByKey finds the field a key was decoded from, by its mapstructure
+// or json name, or its name, case-insensitively.
+func fieldByKey(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, squash := mapstructureName(field)
+		if squash || (field.Anonymous && field.Tag.Get("mapstructure") == "") {
+			if embedded := indirect(field.Type); embedded.Kind() == reflect.Struct {
+				if found, ok := fieldByKey(embedded, key); ok {
+					return found, true
+				}
+			}
+			if squash {
+				continue
+			}
+		}
+		jsonName := strings.Split(field.Tag.Get("json"), ",")[0]
+		if strings.EqualFold(name, key) || (jsonName != "" && strings.EqualFold(jsonName, key)) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
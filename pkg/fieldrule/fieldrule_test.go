@@ -0,0 +1,94 @@
+package fieldrule
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type audit struct {
+	CreatedBy string `mapstructure:"created_by" viewset:"read_only"`
+}
+
+type account struct {
+	audit        `mapstructure:",squash"`
+	ID           uint   `mapstructure:"id" viewset:"read_only"`
+	Email        string `mapstructure:"email"`
+	Password     string `mapstructure:"password" viewset:"write_only"`
+	PasswordHash string `viewset:"hidden"`
+	IsStaff      bool   `mapstructure:"is_staff"`
+}
+
+func (account) FieldRules() Rules {
+	return Rules{"is_staff": {ReadOnly: true}}
+}
+
+func TestOf(t *testing.T) {
+	rules := For[account]()
+
+	assert.Equal(t, Rules{
+		"created_by":   {ReadOnly: true},
+		"id":           {ReadOnly: true},
+		"password":     {WriteOnly: true},
+		"PasswordHash": {Hidden: true},
+		"is_staff":     {ReadOnly: true},
+	}, rules)
+	assert.Equal(t, rules, Of(reflect.TypeOf(&account{})))
+	assert.Nil(t, For[struct{ Name string }]())
+	assert.Nil(t, Of(reflect.TypeOf(1)))
+}
+
+func TestRulesReadableWritable(t *testing.T) {
+	rules := For[account]()
+
+	assert.True(t, rules.Readable("id"))
+	assert.False(t, rules.Writable("id"))
+	assert.False(t, rules.Readable("password"))
+	assert.True(t, rules.Writable("password"))
+	assert.False(t, rules.Readable("passwordhash"))
+	assert.False(t, rules.Writable("passwordhash"))
+	assert.True(t, rules.Readable("email"))
+	assert.True(t, rules.Writable("email"))
+}
+
+func TestRulesFilter(t *testing.T) {
+	rules := For[account]().Merge(Rules{"email": {ReadOnly: true}})
+
+	object := map[string]any{"id": 1, "email": "a@b.c", "password": "secret", "PasswordHash": "x"}
+	rules.FilterReadable(object)
+	assert.Equal(t, map[string]any{"id": 1, "email": "a@b.c"}, object)
+
+	object = map[string]any{"id": 1, "email": "a@b.c", "password": "secret", "is_staff": true}
+	rules.FilterWritable(object)
+	assert.Equal(t, map[string]any{"password": "secret"}, object)
+	assert.True(t, For[account]().Writable("email"))
+}
+
+type team struct {
+	Name    string     `json:"name"`
+	Owner   *account   `json:"owner"`
+	Members []*account `json:"members"`
+}
+
+func TestRulesFilterReadableOf(t *testing.T) {
+	object := map[string]any{
+		"name":  "core",
+		"owner": map[string]any{"email": "a@b.c", "password": "secret"},
+		"members": []*account{
+			{Email: "d@e.f", Password: "secret", PasswordHash: "x"},
+			nil,
+		},
+	}
+	For[team]().FilterReadableOf(reflect.TypeOf(team{}), object)
+
+	assert.Equal(t, map[string]any{
+		"name":  "core",
+		"owner": map[string]any{"email": "a@b.c"},
+		"members": []any{
+			map[string]any{"id": uint(0), "email": "d@e.f", "is_staff": false},
+			nil,
+		},
+	}, object)
+	assert.False(t, hasRules(reflect.TypeOf(struct{ Names []string }{}), map[reflect.Type]bool{}))
+}
@@ -0,0 +1,222 @@
+package fieldset
+
+import (
+	"encoding"
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+const (
+	FIELDS_PARAM = "fields"
+	OMIT_PARAM   = "omit"
+)
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Tree holds dotted paths by segment, a nil subtree stands for the whole
+// value under its key.
+type Tree map[string]Tree
+
+func (t Tree) add(path string) {
+	node := t
+	segments := strings.Split(path, ".")
+	for i, segment := range segments {
+		if segment == "" {
+			return
+		}
+		child, ok := node[segment]
+		if ok && child == nil {
+			// the whole value is already selected
+			return
+		}
+		if i == len(segments)-1 {
+			node[segment] = nil
+			return
+		}
+		if !ok {
+			child = Tree{}
+			node[segment] = child
+		}
+		node = child
+	}
+}
+
+// ParseTree parses comma separated dotted paths.
+func ParseTree(values []string) Tree {
+	var tree Tree
+	for _, value := range values {
+		for _, path := range strings.Split(value, ",") {
+			path = strings.TrimSpace(path)
+			if path == "" {
+				continue
+			}
+			if tree == nil {
+				tree = Tree{}
+			}
+			tree.add(path)
+		}
+	}
+	return tree
+}
+
+// Set is a sparse fieldset, Fields nil selects every key.
+type Set struct {
+	Fields Tree
+	Omit   Tree
+}
+
+// FromQuery parses the comma separated dotted paths of the fields and omit
+// query params.
+func FromQuery(query url.Values) Set {
+	return Set{
+		Fields: ParseTree(query[FIELDS_PARAM]),
+		Omit:   ParseTree(query[OMIT_PARAM]),
+	}
+}
+
+func (s Set) IsZero() bool {
+	return s.Fields == nil && s.Omit == nil
+}
+
+// Has reports whether the key is selected at the top level.
+func (s Set) Has(key string) bool {
+	if s.Fields != nil {
+		if _, ok := s.Fields[key]; !ok {
+			return false
+		}
+	}
+	if omit, ok := s.Omit[key]; ok && omit == nil {
+		return false
+	}
+	return true
+}
+
+// Sub returns the selection of the value under key.
+func (s Set) Sub(key string) Set {
+	sub := Set{Omit: s.Omit[key]}
+	if s.Fields != nil {
+		sub.Fields = s.Fields[key]
+	}
+	return sub
+}
+
+// Keys returns the selected top-level keys, nil if every key is selected.
+func (s Set) Keys() []string {
+	if s.Fields == nil {
+		return nil
+	}
+	keys := make([]string, 0, len(s.Fields))
+	for key := range s.Fields {
+		if s.Has(key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Apply removes the keys which are not selected from the object, nested
+// objects and lists of objects included.
+func (s Set) Apply(object map[string]any) {
+	if s.IsZero() {
+		return
+	}
+	for key, value := range object {
+		if !s.Has(key) {
+			delete(object, key)
+			continue
+		}
+		object[key] = s.Sub(key).Filter(value)
+	}
+}
+
+// Filter returns the value with the selection applied, structs and lists of
+// structs are converted to maps and lists of maps like mapstructure so
+// their keys can be selected, other values are returned as they are.
+func (s Set) Filter(value any) any {
+	if s.IsZero() {
+		return value
+	}
+	value = toMaps(value)
+	s.ApplyValue(value)
+	return value
+}
+
+// toMaps converts structs, pointers to structs and lists of them, values
+// marshaling themselves like time.Time are left as they are.
+func toMaps(value any) any {
+	reflected := reflect.ValueOf(value)
+	if !reflected.IsValid() || marshalsItself(reflected.Type()) {
+		return value
+	}
+	switch reflected.Kind() {
+	case reflect.Pointer:
+		if reflected.IsNil() || reflected.Elem().Kind() != reflect.Struct {
+			return value
+		}
+		return toMaps(reflected.Elem().Interface())
+	case reflect.Struct:
+		object := map[string]any{}
+		if err := mapstructure.Decode(value, &object); err != nil {
+			return value
+		}
+		return object
+	case reflect.Slice, reflect.Array:
+		switch value.(type) {
+		case []any, []map[string]any:
+			return value
+		}
+		elem := reflected.Type().Elem()
+		for elem.Kind() == reflect.Pointer {
+			elem = elem.Elem()
+		}
+		if elem.Kind() != reflect.Struct || marshalsItself(elem) {
+			return value
+		}
+		if reflected.Kind() == reflect.Slice && reflected.IsNil() {
+			return value
+		}
+		items := make([]any, reflected.Len())
+		for i := range items {
+			items[i] = toMaps(reflected.Index(i).Interface())
+		}
+		return items
+	}
+	return value
+}
+
+func marshalsItself(t reflect.Type) bool {
+	return t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) ||
+		reflect.PointerTo(t).Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType)
+}
+
+// ApplyValue applies the selection to a value which may be an object or a
+// list of objects, other values are left untouched. Lists are filtered in
+// place, use Filter for structs and lists of structs.
+func (s Set) ApplyValue(value any) {
+	if s.IsZero() {
+		return
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		s.Apply(v)
+	case *map[string]any:
+		if v != nil {
+			s.Apply(*v)
+		}
+	case []map[string]any:
+		for _, object := range v {
+			s.Apply(object)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = s.Filter(item)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package fieldset
+
+import (
+	"net/url"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromQuery(t *testing.T) {
+	set := FromQuery(url.Values{
+		FIELDS_PARAM: {"name, author.name", "author.email,tags,,author"},
+		OMIT_PARAM:   {"tags.id"},
+	})
+
+	assert.Equal(t, Tree{"name": nil, "author": nil, "tags": nil}, set.Fields)
+	assert.Equal(t, Tree{"tags": Tree{"id": nil}}, set.Omit)
+	assert.True(t, FromQuery(url.Values{}).IsZero())
+}
+
+func TestSetHasAndSub(t *testing.T) {
+	set := FromQuery(url.Values{
+		FIELDS_PARAM: {"name,author.name,author.email,tags"},
+		OMIT_PARAM:   {"author.email,tags"},
+	})
+
+	assert.True(t, set.Has("name"))
+	assert.True(t, set.Has("author"))
+	assert.False(t, set.Has("tags"))
+	assert.False(t, set.Has("age"))
+
+	author := set.Sub("author")
+	assert.True(t, author.Has("name"))
+	assert.False(t, author.Has("email"))
+	assert.True(t, set.Sub("name").IsZero())
+
+	keys := set.Keys()
+	sort.Strings(keys)
+	assert.Equal(t, []string{"author", "name"}, keys)
+	assert.Nil(t, Set{Omit: Tree{"age": nil}}.Keys())
+}
+
+func TestSetApply(t *testing.T) {
+	object := map[string]any{
+		"name": "phuc",
+		"age":  18,
+		"author": map[string]any{
+			"name":  "huy",
+			"email": "huy@example.com",
+		},
+		"tags": []any{
+			map[string]any{"id": 1, "label": "go"},
+			map[string]any{"id": 2, "label": "gin"},
+		},
+		"books": []map[string]any{{"id": 1, "title": "first"}},
+	}
+
+	FromQuery(url.Values{
+		FIELDS_PARAM: {"name,author,tags.label,books"},
+		OMIT_PARAM:   {"author.email,books.id"},
+	}).Apply(object)
+
+	assert.Equal(t, map[string]any{
+		"name":   "phuc",
+		"author": map[string]any{"name": "huy"},
+		"tags": []any{
+			map[string]any{"label": "go"},
+			map[string]any{"label": "gin"},
+		},
+		"books": []map[string]any{{"title": "first"}},
+	}, object)
+}
+
+func TestSetFilter(t *testing.T) {
+	type item struct {
+		Name string `mapstructure:"name"`
+		Qty  int    `mapstructure:"qty"`
+	}
+	set := FromQuery(url.Values{FIELDS_PARAM: {"items.name,first.qty,at,tags"}})
+	createdAt := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	object := map[string]any{
+		"items": []item{{Name: "a", Qty: 1}, {Name: "b", Qty: 2}},
+		"first": &item{Name: "a", Qty: 1},
+		"at":    createdAt,
+		"tags":  []string{"go"},
+	}
+
+	set.Apply(object)
+
+	assert.Equal(t, map[string]any{
+		"items": []any{map[string]any{"name": "a"}, map[string]any{"name": "b"}},
+		"first": map[string]any{"qty": 1},
+		"at":    createdAt,
+		"tags":  []string{"go"},
+	}, object)
+	assert.Equal(t, []item{{Name: "a"}}, Set{}.Filter([]item{{Name: "a"}}))
+}
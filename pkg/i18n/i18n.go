@@ -0,0 +1,349 @@
+// Package i18n holds the messages of viewset by locale and picks the locale
+// of a request from its Accept-Language header.
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	ut "github.com/go-playground/universal-translator"
+	"gopkg.in/yaml.v3"
+)
+
+const DEFAULT_LOCALE = "en"
+
+type Catalog interface {
+	Message(string, string, ...string) (string, bool)
+	// message of a key in a locale with {0}, {1}... replaced by the params, false if the locale does not have it
+	Locales() []string
+	// supported locales, matched against Accept-Language
+}
+
+var _ Catalog = &MemoryCatalog{}
+var _ Catalog = &UniversalCatalog{}
+var _ Catalog = Catalogs{}
+
+// English holds the messages viewset uses, keyed like validation.required.
+var English = map[string]string{
+	"error.permission_denied": "permission denied",
+	"error.unknown_expansion": "unknown expansion: {0}",
+	"error.unknown_timezone":  "unknown timezone: {0}",
+	"error.hidden_field":      "field is not writable: {0}",
+
+	"error.not_found":             "object not found",
+	"error.conflict":              "object conflicts with an existing one",
+	"error.foreign_key_violation": "object refers to an object which does not exist or is still referred to",
+	"error.check_violation":       "object has an invalid value",
+	"error.timeout":               "request timed out, try again later",
+	"error.unavailable":           "service unavailable, try again later",
+	"error.internal":              "internal server error",
+
+	"validation.required":        "This field is required.",
+	"validation.min":             "Must be at least {0}.",
+	"validation.min.string":      "Must be at least {0} characters.",
+	"validation.min.items":       "Must be at least {0} items.",
+	"validation.max":             "Must be at most {0}.",
+	"validation.max.string":      "Must be at most {0} characters.",
+	"validation.max.items":       "Must be at most {0} items.",
+	"validation.len":             "Must be exactly {0}.",
+	"validation.len.string":      "Must be exactly {0} characters.",
+	"validation.len.items":       "Must be exactly {0} items.",
+	"validation.gt":              "Must be more than {0}.",
+	"validation.gt.string":       "Must be more than {0} characters.",
+	"validation.gt.items":        "Must be more than {0} items.",
+	"validation.lt":              "Must be less than {0}.",
+	"validation.lt.string":       "Must be less than {0} characters.",
+	"validation.lt.items":        "Must be less than {0} items.",
+	"validation.oneof":           "Must be one of: {0}.",
+	"validation.email":           "Must be a valid email address.",
+	"validation.url":             "Must be a valid URL.",
+	"validation.default":         "Failed on the '{0}' rule.",
+	"validation.invalid_json":    "Malformed or empty JSON body.",
+	"validation.invalid_json_at": "Malformed JSON at offset {0}.",
+	"validation.invalid_type":    "Expected {0}, got {1}.",
+}
+
+// format replaces {0}, {1}... like universal-translator, in one pass so a
+// param holding a placeholder is kept as is.
+func format(text string, params []string) string {
+	if len(params) == 0 {
+		return text
+	}
+	pairs := make([]string, 0, 2*len(params))
+	for i, param := range params {
+		pairs = append(pairs, "{"+strconv.Itoa(i)+"}", param)
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+// MemoryCatalog holds messages loaded at startup, it is not safe to load
+// messages while requests read them.
+type MemoryCatalog struct {
+	messages map[string]map[string]string
+}
+
+// NewCatalog returns a catalog with English built in.
+func NewCatalog() *MemoryCatalog {
+	catalog := &MemoryCatalog{messages: map[string]map[string]string{}}
+	return catalog.Add(DEFAULT_LOCALE, English)
+}
+
+// Add merges messages into a locale, replacing those of the same key.
+func (catalog *MemoryCatalog) Add(locale string, messages map[string]string) *MemoryCatalog {
+	locale = normalize(locale)
+	if catalog.messages[locale] == nil {
+		catalog.messages[locale] = make(map[string]string, len(messages))
+	}
+	for key, text := range messages {
+		catalog.messages[locale][key] = text
+	}
+	return catalog
+}
+
+func (catalog *MemoryCatalog) Message(locale string, key string, params ...string) (string, bool) {
+	text, ok := catalog.messages[normalize(locale)][key]
+	if !ok {
+		return "", false
+	}
+	return format(text, params), true
+}
+
+func (catalog *MemoryCatalog) Locales() []string {
+	locales := make([]string, 0, len(catalog.messages))
+	for locale := range catalog.messages {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// LoadJSON adds the messages of a JSON object to a locale, nested objects
+// are flattened to dotted keys like validation.required.
+func (catalog *MemoryCatalog) LoadJSON(locale string, r io.Reader) error {
+	document := map[string]any{}
+	if err := json.NewDecoder(r).Decode(&document); err != nil {
+		return err
+	}
+	return catalog.load(locale, document)
+}
+
+// LoadYAML adds the messages of a YAML mapping to a locale, like LoadJSON.
+func (catalog *MemoryCatalog) LoadYAML(locale string, r io.Reader) error {
+	document := map[string]any{}
+	if err := yaml.NewDecoder(r).Decode(&document); err != nil && err != io.EOF {
+		return err
+	}
+	return catalog.load(locale, document)
+}
+
+func (catalog *MemoryCatalog) load(locale string, document map[string]any) error {
+	messages := map[string]string{}
+	if err := flatten(messages, "", document); err != nil {
+		return fmt.Errorf("locale %s: %w", locale, err)
+	}
+	catalog.Add(locale, messages)
+	return nil
+}
+
+func flatten(messages map[string]string, prefix string, document map[string]any) error {
+	for key, value := range document {
+		switch value := value.(type) {
+		case string:
+			messages[prefix+key] = value
+		case map[string]any:
+			if err := flatten(messages, prefix+key+".", value); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("message %s%s is not a string", prefix, key)
+		}
+	}
+	return nil
+}
+
+// LoadFile adds the messages of a .json, .yaml or .yml file named after its
+// locale, e.g. vi.json.
+func (catalog *MemoryCatalog) LoadFile(name string) error {
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return catalog.loadNamed(filepath.Base(name), file)
+}
+
+// LoadFS adds the messages of every file of dir named after its locale,
+// e.g. from an embed.FS.
+func (catalog *MemoryCatalog) LoadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !isCatalogFile(entry.Name()) {
+			continue
+		}
+		file, err := fsys.Open(path.Join(dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+		err = catalog.loadNamed(entry.Name(), file)
+		file.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isCatalogFile(name string) bool {
+	switch path.Ext(name) {
+	case ".json", ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+func (catalog *MemoryCatalog) loadNamed(name string, r io.Reader) error {
+	extension := path.Ext(name)
+	locale := strings.TrimSuffix(name, extension)
+	switch extension {
+	case ".json":
+		return catalog.LoadJSON(locale, r)
+	case ".yaml", ".yml":
+		return catalog.LoadYAML(locale, r)
+	}
+	return fmt.Errorf("unsupported catalog file %s", name)
+}
+
+// UniversalCatalog reads messages from a universal-translator, e.g. one
+// shared with the validator translations.
+type UniversalCatalog struct {
+	Translator *ut.UniversalTranslator
+	Supported  []string
+	// locales of Translator, it does not list them itself
+}
+
+func (catalog *UniversalCatalog) Message(locale string, key string, params ...string) (string, bool) {
+	translator, ok := catalog.Translator.GetTranslator(locale)
+	if !ok {
+		translator, ok = catalog.Translator.GetTranslator(strings.ReplaceAll(locale, "-", "_"))
+	}
+	if !ok {
+		return "", false
+	}
+	text, err := translator.T(key, params...)
+	if err != nil {
+		return "", false
+	}
+	return text, true
+}
+
+func (catalog *UniversalCatalog) Locales() []string {
+	return catalog.Supported
+}
+
+// Catalogs looks a message up in each catalog in turn.
+type Catalogs []Catalog
+
+func (catalogs Catalogs) Message(locale string, key string, params ...string) (string, bool) {
+	for _, catalog := range catalogs {
+		if text, ok := catalog.Message(locale, key, params...); ok {
+			return text, true
+		}
+	}
+	return "", false
+}
+
+func (catalogs Catalogs) Locales() []string {
+	seen := map[string]bool{}
+	locales := []string{}
+	for _, catalog := range catalogs {
+		for _, locale := range catalog.Locales() {
+			if !seen[locale] {
+				seen[locale] = true
+				locales = append(locales, locale)
+			}
+		}
+	}
+	return locales
+}
+
+// normalize writes locales like pt-BR, whichever separator and case they
+// come with.
+func normalize(locale string) string {
+	language, region, found := strings.Cut(strings.ReplaceAll(locale, "_", "-"), "-")
+	if !found {
+		return strings.ToLower(language)
+	}
+	return strings.ToLower(language) + "-" + strings.ToUpper(region)
+}
+
+// Match returns the supported locale preferred by an Accept-Language
+// header, a request for vi-VN matches vi. It returns an empty string if
+// none matches.
+func Match(acceptLanguage string, supported []string) string {
+	type preference struct {
+		locale  string
+		quality float64
+	}
+	preferences := []preference{}
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		locale, options, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if options = strings.TrimSpace(options); strings.HasPrefix(options, "q=") {
+			parsed, err := strconv.ParseFloat(strings.TrimPrefix(options, "q="), 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		if locale == "" || quality <= 0 {
+			continue
+		}
+		preferences = append(preferences, preference{normalize(locale), quality})
+	}
+	sort.SliceStable(preferences, func(i, j int) bool {
+		return preferences[i].quality > preferences[j].quality
+	})
+
+	for _, preference := range preferences {
+		if preference.locale == "*" {
+			return wildcard(supported)
+		}
+		for _, locale := range supported {
+			if normalize(locale) == preference.locale {
+				return locale
+			}
+		}
+		language, _, _ := strings.Cut(preference.locale, "-")
+		for _, locale := range supported {
+			if normalize(locale) == language {
+				return locale
+			}
+		}
+	}
+	return ""
+}
+
+// wildcard answers *, any locale, with the default one when it is supported,
+// not with whichever sorts first.
+func wildcard(supported []string) string {
+	for _, locale := range supported {
+		if normalize(locale) == DEFAULT_LOCALE {
+			return locale
+		}
+	}
+	if len(supported) > 0 {
+		return supported[0]
+	}
+	return ""
+}
@@ -0,0 +1,117 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/vi"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalogMessage(t *testing.T) {
+	catalog := NewCatalog().Add("pt_br", map[string]string{"validation.min": "Deve ser pelo menos {0}."})
+
+	text, ok := catalog.Message("en", "validation.min.string", "3")
+	assert.True(t, ok)
+	assert.Equal(t, "Must be at least 3 characters.", text)
+
+	text, ok = catalog.Message("PT-br", "validation.min", "3")
+	assert.True(t, ok)
+	assert.Equal(t, "Deve ser pelo menos 3.", text)
+
+	text, ok = catalog.Message("en", "validation.invalid_type", "{1}", "string")
+	assert.True(t, ok)
+	assert.Equal(t, "Expected {1}, got string.", text)
+
+	_, ok = catalog.Message("pt-BR", "validation.required")
+	assert.False(t, ok)
+	_, ok = catalog.Message("fr", "validation.required")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"en", "pt-BR"}, catalog.Locales())
+}
+
+func TestCatalogLoadJSONAndYAML(t *testing.T) {
+	catalog := NewCatalog()
+
+	require.NoError(t, catalog.LoadJSON("vi", strings.NewReader(
+		`{"validation": {"required": "Trường này là bắt buộc.", "min": {"string": "Tối thiểu {0} ký tự."}}}`,
+	)))
+	require.NoError(t, catalog.LoadYAML("fr", strings.NewReader(
+		"error:\n  permission_denied: permission refusée\nvalidation.required: Ce champ est obligatoire.\n",
+	)))
+
+	text, _ := catalog.Message("vi", "validation.required")
+	assert.Equal(t, "Trường này là bắt buộc.", text)
+	text, _ = catalog.Message("vi", "validation.min.string", "2")
+	assert.Equal(t, "Tối thiểu 2 ký tự.", text)
+	text, _ = catalog.Message("fr", "error.permission_denied")
+	assert.Equal(t, "permission refusée", text)
+	text, _ = catalog.Message("fr", "validation.required")
+	assert.Equal(t, "Ce champ est obligatoire.", text)
+
+	assert.EqualError(t, catalog.LoadJSON("vi", strings.NewReader(`{"validation": {"min": 1}}`)),
+		"locale vi: message validation.min is not a string")
+	assert.Error(t, catalog.LoadYAML("vi", strings.NewReader("- a")))
+}
+
+func TestCatalogLoadFileAndFS(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "de.yml")
+	require.NoError(t, os.WriteFile(name, []byte("validation:\n  required: Pflichtfeld.\n"), 0o600))
+
+	catalog := NewCatalog()
+	require.NoError(t, catalog.LoadFile(name))
+	text, _ := catalog.Message("de", "validation.required")
+	assert.Equal(t, "Pflichtfeld.", text)
+	assert.Error(t, catalog.LoadFile(filepath.Join(dir, "es.json")))
+
+	require.NoError(t, catalog.LoadFS(fstest.MapFS{
+		"locales/vi.json":   {Data: []byte(`{"validation.required": "Bắt buộc."}`)},
+		"locales/fr.yaml":   {Data: []byte("validation.required: Obligatoire.\n")},
+		"locales/README.md": {Data: []byte("not a catalog")},
+	}, "locales"))
+	assert.Equal(t, []string{"de", "en", "fr", "vi"}, catalog.Locales())
+
+	assert.Error(t, catalog.LoadFS(fstest.MapFS{"locales/es.json": {Data: []byte("{")}}, "locales"))
+	assert.Error(t, catalog.LoadFS(fstest.MapFS{}, "locales"))
+}
+
+func TestUniversalCatalog(t *testing.T) {
+	translator := ut.New(en.New(), en.New(), vi.New())
+	vietnamese, _ := translator.GetTranslator("vi")
+	require.NoError(t, vietnamese.Add("validation.min", "Tối thiểu {0}.", false))
+
+	catalog := Catalogs{&UniversalCatalog{Translator: translator, Supported: []string{"vi"}}, NewCatalog()}
+
+	text, ok := catalog.Message("vi", "validation.min", "3")
+	assert.True(t, ok)
+	assert.Equal(t, "Tối thiểu 3.", text)
+
+	_, ok = catalog.Message("vi", "validation.required")
+	assert.False(t, ok)
+
+	text, ok = catalog.Message("en", "validation.required")
+	assert.True(t, ok)
+	assert.Equal(t, "This field is required.", text)
+	assert.Equal(t, []string{"vi", "en"}, catalog.Locales())
+}
+
+func TestMatch(t *testing.T) {
+	supported := []string{"en", "vi", "pt-BR"}
+
+	assert.Equal(t, "vi", Match("vi-VN,vi;q=0.9,en;q=0.8", supported))
+	assert.Equal(t, "en", Match("fr;q=0.9, en;q=0.5", supported))
+	assert.Equal(t, "pt-BR", Match("en;q=0.4, pt_br", supported))
+	assert.Equal(t, "en", Match("*", supported))
+	assert.Equal(t, "", Match("fr, de;q=0.5, vi;q=0", supported))
+	assert.Equal(t, "", Match("vi;q=high", supported))
+	assert.Equal(t, "", Match("", supported))
+	assert.Equal(t, "en", Match("*", []string{"de", "en", "vi"}))
+	assert.Equal(t, "de", Match("fr, *;q=0.5", []string{"de", "vi"}))
+}
@@ -0,0 +1,121 @@
+package viewset
+
+import (
+	"github.com/TcMits/viewset/manager"
+)
+
+var defaultActions = []string{
+	DEFAULT_LIST_ACTION,
+	DEFAULT_RETRIEVE_ACTION,
+	DEFAULT_CREATE_ACTION,
+	DEFAULT_UPDATE_ACTION,
+	DEFAULT_DELETE_ACTION,
+}
+
+func onlyActions(actions ...string) []string {
+	excludeActions := make([]string, 0, len(defaultActions))
+	for _, action := range defaultActions {
+		if shouldAddAction(action, actions) {
+			excludeActions = append(excludeActions, action)
+		}
+	}
+	return excludeActions
+}
+
+// NewReadOnlyViewSet registers list and retrieve actions.
+func NewReadOnlyViewSet[EntityType, ValidateType any](
+	basePath string,
+	detailParams string,
+	additionalActions []Route[EntityType, ValidateType],
+
+	objectManager manager.ReadManager[EntityType],
+	exceptionHandler ExceptionHandler,
+	permissionChecker PermissionChecker,
+	serializer Serializer[EntityType],
+) *ViewSet[EntityType, ValidateType] {
+	return NewViewSet[EntityType, ValidateType](
+		basePath,
+		detailParams,
+		onlyActions(DEFAULT_LIST_ACTION, DEFAULT_RETRIEVE_ACTION),
+		additionalActions,
+		manager.NewComposedManager[EntityType, ValidateType](objectManager),
+		exceptionHandler,
+		permissionChecker,
+		serializer,
+		nil,
+	)
+}
+
+// NewListCreateViewSet registers list and create actions.
+func NewListCreateViewSet[EntityType, ValidateType any](
+	basePath string,
+	additionalActions []Route[EntityType, ValidateType],
+
+	objectManager manager.ListCreateManager[EntityType, ValidateType],
+	exceptionHandler ExceptionHandler,
+	permissionChecker PermissionChecker,
+	serializer Serializer[EntityType],
+	formValidator FormValidator[EntityType, ValidateType],
+) *ViewSet[EntityType, ValidateType] {
+	return NewViewSet[EntityType, ValidateType](
+		basePath,
+		"",
+		onlyActions(DEFAULT_LIST_ACTION, DEFAULT_CREATE_ACTION),
+		additionalActions,
+		manager.NewComposedManager[EntityType, ValidateType](objectManager),
+		exceptionHandler,
+		permissionChecker,
+		serializer,
+		formValidator,
+	)
+}
+
+// NewRetrieveUpdateViewSet registers retrieve and update actions.
+func NewRetrieveUpdateViewSet[EntityType, ValidateType any](
+	basePath string,
+	detailParams string,
+	additionalActions []Route[EntityType, ValidateType],
+
+	objectManager manager.RetrieveUpdateManager[EntityType, ValidateType],
+	exceptionHandler ExceptionHandler,
+	permissionChecker PermissionChecker,
+	serializer Serializer[EntityType],
+	formValidator FormValidator[EntityType, ValidateType],
+) *ViewSet[EntityType, ValidateType] {
+	return NewViewSet[EntityType, ValidateType](
+		basePath,
+		detailParams,
+		onlyActions(DEFAULT_RETRIEVE_ACTION, DEFAULT_UPDATE_ACTION),
+		additionalActions,
+		manager.NewComposedManager[EntityType, ValidateType](objectManager),
+		exceptionHandler,
+		permissionChecker,
+		serializer,
+		formValidator,
+	)
+}
+
+// NewRetrieveUpdateDeleteViewSet registers retrieve, update and delete actions.
+func NewRetrieveUpdateDeleteViewSet[EntityType, ValidateType any](
+	basePath string,
+	detailParams string,
+	additionalActions []Route[EntityType, ValidateType],
+
+	objectManager manager.RetrieveUpdateDeleteManager[EntityType, ValidateType],
+	exceptionHandler ExceptionHandler,
+	permissionChecker PermissionChecker,
+	serializer Serializer[EntityType],
+	formValidator FormValidator[EntityType, ValidateType],
+) *ViewSet[EntityType, ValidateType] {
+	return NewViewSet[EntityType, ValidateType](
+		basePath,
+		detailParams,
+		onlyActions(DEFAULT_RETRIEVE_ACTION, DEFAULT_UPDATE_ACTION, DEFAULT_DELETE_ACTION),
+		additionalActions,
+		manager.NewComposedManager[EntityType, ValidateType](objectManager),
+		exceptionHandler,
+		permissionChecker,
+		serializer,
+		formValidator,
+	)
+}
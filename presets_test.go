@@ -0,0 +1,87 @@
+package viewset
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type testReadOnlyManager struct {
+	objectManager testObjectManager
+}
+
+func (om *testReadOnlyManager) GetObject(dest **testObject, c *gin.Context) error {
+	return om.objectManager.GetObject(dest, c)
+}
+
+func (om *testReadOnlyManager) GetObjects(
+	dest *[]*testObject, paginatedMeta *map[string]any, c *gin.Context,
+) error {
+	return om.objectManager.GetObjects(dest, paginatedMeta, c)
+}
+
+func routeKeys[EntityType, ValidateType any](viewSet *ViewSet[EntityType, ValidateType]) []string {
+	keys := []string{}
+	for _, route := range viewSet.Actions {
+		keys = append(keys, route.Method+" "+route.Action)
+	}
+	return keys
+}
+
+func TestNewReadOnlyViewSet(t *testing.T) {
+	objectManager := &testReadOnlyManager{}
+	objectManager.objectManager.Database = append(
+		objectManager.objectManager.Database,
+		testObject{Pk: 1, Name: "test", Age: 20},
+	)
+	viewSet := NewReadOnlyViewSet[testObject, testObjectRequest](
+		"/objects", "/:pk", nil, objectManager, nil, nil, nil,
+	)
+	router := SetUpRouter()
+	viewSet.Register(router)
+
+	assert.Equal(t, []string{"GET list", "GET retrieve"}, routeKeys(viewSet))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/objects/1", nil))
+	assert.Equal(t, `{"age":20,"name":"test"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/objects/", strings.NewReader(`{}`)))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestNewListCreateViewSet(t *testing.T) {
+	viewSet := NewListCreateViewSet[testObject, testObjectRequest](
+		"/objects", nil, &testObjectManager{}, nil, nil, nil, nil,
+	)
+
+	assert.Equal(t, []string{"GET list", "POST create"}, routeKeys(viewSet))
+}
+
+func TestNewRetrieveUpdateViewSet(t *testing.T) {
+	viewSet := NewRetrieveUpdateViewSet[testObject, testObjectRequest](
+		"/objects", "/:pk", nil, &testObjectManager{}, nil, nil, nil, nil,
+	)
+
+	assert.Equal(t, []string{"GET retrieve", "PUT update", "PATCH update"}, routeKeys(viewSet))
+}
+
+func TestNewRetrieveUpdateDeleteViewSet(t *testing.T) {
+	additionalActions := []Route[testObject, testObjectRequest]{
+		{Action: "send", SubPath: "/:pk/send", Method: http.MethodPost},
+	}
+	viewSet := NewRetrieveUpdateDeleteViewSet[testObject, testObjectRequest](
+		"/objects", "/:pk", additionalActions, &testObjectManager{}, nil, nil, nil, nil,
+	)
+
+	assert.Equal(
+		t,
+		[]string{"GET retrieve", "PUT update", "PATCH update", "DELETE delete", "POST send"},
+		routeKeys(viewSet),
+	)
+}
@@ -0,0 +1,114 @@
+// Package problem writes ViewSet errors as problem details, see
+// https://www.rfc-editor.org/rfc/rfc7807.
+package problem
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/TcMits/viewset"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	CONTENT_TYPE = "application/problem+json"
+
+	DEFAULT_TYPE              = "about:blank"
+	DEFAULT_REQUEST_ID_HEADER = "X-Request-ID"
+)
+
+var _ viewset.ExceptionHandler = &ExceptionHandler{}
+
+// Problem is a problem details document, Code, RequestID, Errors and Stack
+// are extension members. Stack holds the stack trace of a panic in debug
+// mode.
+type Problem struct {
+	Type      string                   `json:"type"`
+	Title     string                   `json:"title"`
+	Status    int                      `json:"status"`
+	Detail    string                   `json:"detail,omitempty"`
+	Instance  string                   `json:"instance,omitempty"`
+	Code      string                   `json:"code,omitempty"`
+	RequestID string                   `json:"request_id,omitempty"`
+	Errors    viewset.ValidationErrors `json:"errors,omitempty"`
+	Stack     string                   `json:"stack,omitempty"`
+}
+
+// ExceptionHandler writes an application/problem+json document, the type
+// and code are taken from a ViewSetError in the chain.
+type ExceptionHandler struct {
+	TypeBaseURI string
+	// optional, errors with a code but no type get TypeBaseURI + code as type, e.g. https://example.com/problems/
+	RequestIDHeader string
+	// optional, header holding the request id, defaults to X-Request-ID
+	RequestIDContextKey string
+	// optional, gin context key of the request id, e.g. set by a middleware, checked before the header
+	ValidationStatusCode int
+	// optional, status of validation errors, e.g. 422, defaults to 400
+}
+
+func (h *ExceptionHandler) Handle(err error, c *gin.Context) {
+	c.Error(err)
+	problem := h.Problem(err, c)
+	c.Header("Content-Type", CONTENT_TYPE)
+	c.AbortWithStatusJSON(problem.Status, problem)
+}
+
+// Problem describes err, errors which are not a ViewSetError are bad
+// requests like with DefaultExceptionHandler.
+func (h *ExceptionHandler) Problem(err error, c *gin.Context) *Problem {
+	problem := &Problem{
+		Type:      DEFAULT_TYPE,
+		Status:    http.StatusBadRequest,
+		Detail:    err.Error(),
+		RequestID: h.requestID(c),
+	}
+	if c.Request != nil && c.Request.URL != nil {
+		problem.Instance = c.Request.URL.RequestURI()
+	}
+
+	viewSetErr := new(viewset.ViewSetError)
+	if errors.As(err, &viewSetErr) {
+		if viewSetErr.StatusCode != 0 {
+			problem.Status = viewSetErr.StatusCode
+		}
+		problem.Code = viewSetErr.Code
+		switch {
+		case viewSetErr.Type != "":
+			problem.Type = viewSetErr.Type
+		case viewSetErr.Code != "" && h.TypeBaseURI != "":
+			problem.Type = h.TypeBaseURI + viewSetErr.Code
+		}
+	}
+
+	validationErrs := viewset.ValidationErrors{}
+	if errors.As(err, &validationErrs) {
+		problem.Errors = validationErrs
+		if h.ValidationStatusCode != 0 {
+			problem.Status = h.ValidationStatusCode
+		}
+	}
+	panicErr := new(viewset.PanicError)
+	if gin.IsDebugging() && errors.As(err, &panicErr) {
+		problem.Stack = string(panicErr.Stack)
+	}
+	problem.Title = http.StatusText(problem.Status)
+	return problem
+}
+
+func (h *ExceptionHandler) requestID(c *gin.Context) string {
+	if h.RequestIDContextKey != "" {
+		if requestID, ok := c.Value(h.RequestIDContextKey).(string); ok && requestID != "" {
+			return requestID
+		}
+	}
+	if c.Request == nil {
+		return ""
+	}
+	header := h.RequestIDHeader
+	if header == "" {
+		header = DEFAULT_REQUEST_ID_HEADER
+	}
+	return strings.TrimSpace(c.GetHeader(header))
+}
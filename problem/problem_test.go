@@ -0,0 +1,123 @@
+package problem
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/TcMits/viewset"
+	"github.com/TcMits/viewset/manager"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestContext(target string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+	return c, w
+}
+
+func TestExceptionHandler(t *testing.T) {
+	c, w := newTestContext("/articles/?page=2")
+	c.Request.Header.Set(DEFAULT_REQUEST_ID_HEADER, "req-1")
+
+	(&ExceptionHandler{}).Handle(errors.New("bad request"), c)
+
+	assert.True(t, c.IsAborted())
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, CONTENT_TYPE, w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"type":"about:blank","title":"Bad Request","status":400,"detail":"bad request",
+		"instance":"/articles/?page=2","request_id":"req-1"
+	}`, w.Body.String())
+	assert.Equal(t, 1, len(c.Errors))
+}
+
+func TestExceptionHandlerWithCodeAndType(t *testing.T) {
+	handler := &ExceptionHandler{TypeBaseURI: "https://example.com/problems/", RequestIDContextKey: "request_id"}
+
+	c, w := newTestContext("/articles/1")
+	c.Set("request_id", "req-2")
+	c.Request.Header.Set(DEFAULT_REQUEST_ID_HEADER, "ignored")
+	handler.Handle(fmt.Errorf("handler: %w", viewset.NewViewSetError(
+		"permission denied", http.StatusForbidden, viewset.ErrPermissionDenied,
+	).WithCode("permission_denied")), c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.JSONEq(t, `{
+		"type":"https://example.com/problems/permission_denied","title":"Forbidden","status":403,
+		"detail":"handler: permission denied","instance":"/articles/1","code":"permission_denied","request_id":"req-2"
+	}`, w.Body.String())
+
+	problem := handler.Problem(viewset.NewViewSetError("gone", http.StatusGone, nil).
+		WithCode("archived").WithType("https://example.com/archived"), c)
+	assert.Equal(t, "https://example.com/archived", problem.Type)
+	assert.Equal(t, "Gone", problem.Title)
+	assert.Equal(t, "archived", problem.Code)
+
+	c, _ = newTestContext("/articles/1")
+	c.Request.Header.Set("X-Correlation-ID", " corr ")
+	problem = (&ExceptionHandler{RequestIDHeader: "X-Correlation-ID"}).Problem(viewset.NewViewSetError("", 0, nil), c)
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+	assert.Equal(t, DEFAULT_TYPE, problem.Type)
+	assert.Equal(t, "corr", problem.RequestID)
+}
+
+type testArticle struct {
+	ID    uint   `mapstructure:"id" viewset:"read_only"`
+	Title string `mapstructure:"title"`
+}
+
+type testArticleRequest struct {
+	Title string `json:"title" mapstructure:"title" binding:"required"`
+}
+
+type testArticleURI struct {
+	ID uint `uri:"pk"`
+}
+
+func TestExceptionHandlerWithValidationErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	articleManager := manager.NewMemoryManager[testArticle, testArticleRequest, testArticleURI]("ID")
+	viewSet := viewset.NewViewSet[testArticle, testArticleRequest](
+		"/articles", "/:pk", nil, nil, articleManager,
+		&ExceptionHandler{ValidationStatusCode: http.StatusUnprocessableEntity}, nil, nil, nil,
+	)
+	router := gin.New()
+	viewSet.Register(router)
+
+	req := httptest.NewRequest(http.MethodPost, "/articles/", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, CONTENT_TYPE, w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"type":"about:blank","title":"Unprocessable Entity","status":422,
+		"detail":"title: This field is required.","instance":"/articles/","code":"invalid",
+		"errors":{"title":[{"code":"required","message":"This field is required."}]}
+	}`, w.Body.String())
+}
+
+func TestExceptionHandlerWithPanic(t *testing.T) {
+	panicErr := viewset.NewViewSetError("internal server error", http.StatusInternalServerError, &viewset.PanicError{
+		Value: "boom", Stack: []byte("goroutine 1 [running]:"),
+	}).WithCode("panic")
+
+	c, _ := newTestContext("/articles/")
+	problem := (&ExceptionHandler{}).Problem(panicErr, c)
+	assert.Equal(t, http.StatusInternalServerError, problem.Status)
+	assert.Equal(t, "panic", problem.Code)
+	assert.Equal(t, "", problem.Stack)
+
+	gin.SetMode(gin.DebugMode)
+	defer gin.SetMode(gin.TestMode)
+	problem = (&ExceptionHandler{}).Problem(panicErr, c)
+	assert.Equal(t, "goroutine 1 [running]:", problem.Stack)
+}
@@ -0,0 +1,220 @@
+package viewset
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const PROMETHEUS_CONTENT_TYPE = "text/plain; version=0.0.4; charset=utf-8"
+
+var _ Instrumentation = &PrometheusInstrumentation{}
+
+var DefaultPrometheusBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type prometheusHistogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+type prometheusMetric struct {
+	help       string
+	metricType string
+	histograms map[string]*prometheusHistogram
+	counters   map[string]uint64
+}
+
+// PrometheusInstrumentation collects per action and per phase metrics and
+// serves them in the Prometheus text exposition format.
+type PrometheusInstrumentation struct {
+	Namespace string
+	Buckets   []float64
+
+	mu      sync.Mutex
+	metrics map[string]*prometheusMetric
+}
+
+func NewPrometheusInstrumentation(namespace string, buckets ...float64) *PrometheusInstrumentation {
+	if namespace == "" {
+		namespace = "viewset"
+	}
+	if len(buckets) == 0 {
+		buckets = DefaultPrometheusBuckets
+	}
+	sortedBuckets := append([]float64{}, buckets...)
+	sort.Float64s(sortedBuckets)
+	return &PrometheusInstrumentation{
+		Namespace: namespace,
+		Buckets:   sortedBuckets,
+		metrics:   map[string]*prometheusMetric{},
+	}
+}
+
+func (p *PrometheusInstrumentation) StartAction(action string, c *gin.Context) func() {
+	start := time.Now()
+	return func() {
+		status := strconv.Itoa(c.Writer.Status())
+		labels := formatPrometheusLabels(
+			"route", c.FullPath(),
+			"method", requestMethod(c),
+			"action", action,
+		)
+		statusLabels := formatPrometheusLabels(
+			"route", c.FullPath(),
+			"method", requestMethod(c),
+			"action", action,
+			"status", status,
+		)
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.observe(
+			"action_duration_seconds", "Latency of viewset actions.",
+			labels, time.Since(start).Seconds(),
+		)
+		p.inc("action_responses_total", "Responses written by viewset actions.", statusLabels)
+		if c.Writer.Status() >= http.StatusBadRequest {
+			p.inc("action_errors_total", "Error responses written by viewset actions.", statusLabels)
+		}
+	}
+}
+
+func (p *PrometheusInstrumentation) StartPhase(action string, phase string, c *gin.Context) func(error) {
+	start := time.Now()
+	return func(err error) {
+		labels := formatPrometheusLabels(
+			"route", c.FullPath(),
+			"action", action,
+			"phase", phase,
+		)
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.observe(
+			"phase_duration_seconds", "Latency of viewset action phases.",
+			labels, time.Since(start).Seconds(),
+		)
+		if err != nil {
+			p.inc("phase_errors_total", "Failed viewset action phases.", labels)
+		}
+	}
+}
+
+func (p *PrometheusInstrumentation) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Content-Type", PROMETHEUS_CONTENT_TYPE)
+		c.Status(http.StatusOK)
+		p.WriteTo(c.Writer)
+	}
+}
+
+func (p *PrometheusInstrumentation) WriteTo(w io.Writer) (int64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	buffer := new(bytes.Buffer)
+	names := make([]string, 0, len(p.metrics))
+	for name := range p.metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		metric := p.metrics[name]
+		fullName := p.Namespace + "_" + name
+		fmt.Fprintf(buffer, "# HELP %s %s\n", fullName, metric.help)
+		fmt.Fprintf(buffer, "# TYPE %s %s\n", fullName, metric.metricType)
+
+		if metric.metricType == "counter" {
+			for _, labels := range sortedKeys(metric.counters) {
+				fmt.Fprintf(buffer, "%s{%s} %d\n", fullName, labels, metric.counters[labels])
+			}
+			continue
+		}
+		for _, labels := range sortedKeys(metric.histograms) {
+			histogram := metric.histograms[labels]
+			for i, bound := range p.Buckets {
+				fmt.Fprintf(
+					buffer, "%s_bucket{%s,le=\"%s\"} %d\n",
+					fullName, labels, strconv.FormatFloat(bound, 'g', -1, 64), histogram.counts[i],
+				)
+			}
+			fmt.Fprintf(buffer, "%s_bucket{%s,le=\"+Inf\"} %d\n", fullName, labels, histogram.count)
+			fmt.Fprintf(buffer, "%s_sum{%s} %s\n", fullName, labels, strconv.FormatFloat(histogram.sum, 'g', -1, 64))
+			fmt.Fprintf(buffer, "%s_count{%s} %d\n", fullName, labels, histogram.count)
+		}
+	}
+	return buffer.WriteTo(w)
+}
+
+func (p *PrometheusInstrumentation) metric(name, help, metricType string) *prometheusMetric {
+	if p.metrics == nil {
+		p.metrics = map[string]*prometheusMetric{}
+	}
+	metric, ok := p.metrics[name]
+	if !ok {
+		metric = &prometheusMetric{
+			help:       help,
+			metricType: metricType,
+			histograms: map[string]*prometheusHistogram{},
+			counters:   map[string]uint64{},
+		}
+		p.metrics[name] = metric
+	}
+	return metric
+}
+
+func (p *PrometheusInstrumentation) inc(name, help, labels string) {
+	p.metric(name, help, "counter").counters[labels] += 1
+}
+
+func (p *PrometheusInstrumentation) observe(name, help, labels string, value float64) {
+	metric := p.metric(name, help, "histogram")
+	histogram, ok := metric.histograms[labels]
+	if !ok {
+		histogram = &prometheusHistogram{counts: make([]uint64, len(p.Buckets))}
+		metric.histograms[labels] = histogram
+	}
+	for i, bound := range p.Buckets {
+		if value <= bound {
+			histogram.counts[i] += 1
+		}
+	}
+	histogram.sum += value
+	histogram.count += 1
+}
+
+func requestMethod(c *gin.Context) string {
+	if c.Request == nil {
+		return ""
+	}
+	return c.Request.Method
+}
+
+var prometheusLabelReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func formatPrometheusLabels(pairs ...string) string {
+	labels := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		labels = append(labels, pairs[i]+`="`+prometheusLabelReplacer.Replace(pairs[i+1])+`"`)
+	}
+	return strings.Join(labels, ",")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,55 @@
+package viewset
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPrometheusInstrumentation(t *testing.T) {
+	instrumentation := NewPrometheusInstrumentation("", 1, 0.5)
+
+	assert.Equal(t, "viewset", instrumentation.Namespace)
+	assert.Equal(t, []float64{0.5, 1}, instrumentation.Buckets)
+	assert.Equal(t, DefaultPrometheusBuckets, NewPrometheusInstrumentation("api").Buckets)
+}
+
+func TestPrometheusInstrumentationHandler(t *testing.T) {
+	objectManager := &testObjectManager{}
+	objectManager.Database = append(
+		objectManager.Database,
+		testObject{Pk: 1, Name: "test", Age: 20},
+	)
+	viewSet := NewViewSet[testObject, testObjectRequest](
+		"/objects", "/:pk", nil, nil, objectManager, nil, nil, nil, nil,
+	)
+	instrumentation := NewPrometheusInstrumentation("api", 10)
+	viewSet.Instrumentation = instrumentation
+	router := SetUpRouter()
+	viewSet.Register(router)
+	router.GET("/metrics", instrumentation.Handler())
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/objects/", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/objects/2", nil))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := w.Body.String()
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, PROMETHEUS_CONTENT_TYPE, w.Header().Get("Content-Type"))
+	assert.Contains(t, body, "# TYPE api_action_duration_seconds histogram\n")
+	assert.Contains(t, body, `api_action_duration_seconds_bucket{route="/objects/",method="GET",action="list",le="10"} 1`)
+	assert.Contains(t, body, `api_action_duration_seconds_count{route="/objects/",method="GET",action="list"} 1`)
+	assert.Contains(t, body, `api_action_responses_total{route="/objects/",method="GET",action="list",status="200"} 1`)
+	assert.Contains(t, body, `api_action_errors_total{route="/objects/:pk",method="GET",action="retrieve",status="404"} 1`)
+	assert.Contains(t, body, `api_phase_duration_seconds_count{route="/objects/",action="list",phase="serialize"} 1`)
+	assert.Contains(t, body, `api_phase_errors_total{route="/objects/:pk",action="retrieve",phase="get_object"} 1`)
+	assert.NotContains(t, body, `api_action_errors_total{route="/objects/"`)
+}
+
+func TestFormatPrometheusLabels(t *testing.T) {
+	assert.Equal(t, `a="1",b="x\"y\\z\n"`, formatPrometheusLabels("a", "1", "b", "x\"y\\z\n"))
+	assert.Equal(t, "", formatPrometheusLabels())
+}
@@ -0,0 +1,78 @@
+package viewset
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+)
+
+var _ PanicReporter = PanicReporterFunc(nil)
+
+// PanicError is the cause of the ViewSetError of a recovered panic, the
+// exception handlers show its stack trace in debug mode only.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (err *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", err.Value)
+}
+
+// Unwrap returns the value of the panic if it is an error.
+func (err *PanicError) Unwrap() error {
+	valueErr, _ := err.Value.(error)
+	return valueErr
+}
+
+// PanicReporterFunc reports panics with a function.
+type PanicReporterFunc func(any, []byte, *gin.Context)
+
+func (f PanicReporterFunc) ReportPanic(value any, stack []byte, c *gin.Context) {
+	f(value, stack, c)
+}
+
+// panicStack returns the stack trace of a PanicError in err, nil if there
+// is none or gin is not in debug mode.
+func panicStack(err error) []byte {
+	panicErr := new(PanicError)
+	if !gin.IsDebugging() || !errors.As(err, &panicErr) {
+		return nil
+	}
+	return panicErr.Stack
+}
+
+// recoverPanic must be deferred, it reports a panic of the action and
+// answers it with the exception handler.
+func (viewSet *ViewSet[_, _]) recoverPanic(c *gin.Context) {
+	value := recover()
+	if value == nil {
+		return
+	}
+	if value == http.ErrAbortHandler {
+		// the handler gave up on the response on purpose
+		panic(value)
+	}
+	panicErr := &PanicError{Value: value, Stack: debug.Stack()}
+	if viewSet.PanicReporter != nil {
+		viewSet.PanicReporter.ReportPanic(value, panicErr.Stack, c)
+	}
+
+	message := panicErr.Error()
+	if !gin.IsDebugging() {
+		// keep the cause in the logs of the request
+		c.Error(panicErr).SetType(gin.ErrorTypePrivate)
+		message = translate(c, "error.internal")
+	}
+	err := NewViewSetError(message, http.StatusInternalServerError, panicErr).WithCode("panic")
+	if c.Writer.Written() {
+		// too late for another response
+		c.Error(err)
+		c.Abort()
+		return
+	}
+	viewSet.ExceptionHandler.Handle(err, c)
+}
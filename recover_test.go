@@ -0,0 +1,168 @@
+package viewset
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/TcMits/viewset/manager"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var errTestPanic = errors.New("serializer exploded")
+
+func newTestPanicSerializer() *DefaultSerializer[testNote] {
+	return &DefaultSerializer[testNote]{
+		AdditionalField: map[string]Field[testNote]{
+			"summary": &MethodField[testNote]{Method: func(note *testNote, c *gin.Context) (any, error) {
+				switch c.GetHeader("X-Panic") {
+				case "error":
+					panic(errTestPanic)
+				case "written":
+					c.String(http.StatusAccepted, "partial")
+					panic("too late")
+				case "value":
+					panic(fmt.Sprintf("bad note %d", note.ID))
+				}
+				return note.Title, nil
+			}},
+		},
+	}
+}
+
+type testPanicReport struct {
+	value any
+	stack []byte
+}
+
+func newTestPanicRouter(reports *[]testPanicReport) (*gin.Engine, *ViewSet[testNote, testNoteRequest]) {
+	noteManager := manager.NewMemoryManager[testNote, testNoteRequest, testNoteURI]("ID")
+	noteManager.Add(testNote{Title: "first"})
+	viewSet := NewViewSet[testNote, testNoteRequest](
+		"/notes", "/:pk", nil, nil, noteManager, nil, nil, newTestPanicSerializer(), nil,
+	)
+	viewSet.PanicReporter = PanicReporterFunc(func(value any, stack []byte, _ *gin.Context) {
+		*reports = append(*reports, testPanicReport{value, stack})
+	})
+	router := SetUpRouter()
+	viewSet.Register(router)
+	return router, viewSet
+}
+
+func serveTestPanic(router *gin.Engine, method string, target string, kind string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(`{"title":"second"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Panic", kind)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestViewSetRecoversPanics(t *testing.T) {
+	reports := []testPanicReport{}
+	router, _ := newTestPanicRouter(&reports)
+
+	w := serveTestPanic(router, http.MethodGet, "/notes/1", "value")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, `{"message":"internal server error"}`, w.Body.String())
+	require.Equal(t, 1, len(reports))
+	assert.Equal(t, "bad note 1", reports[0].value)
+	assert.Contains(t, string(reports[0].stack), "recover_test.go")
+
+	w = serveTestPanic(router, http.MethodGet, "/notes/", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, len(reports))
+}
+
+func TestViewSetShowsPanicsInDebugMode(t *testing.T) {
+	reports := []testPanicReport{}
+	router, _ := newTestPanicRouter(&reports)
+	gin.SetMode(gin.DebugMode)
+	defer gin.SetMode(gin.TestMode)
+
+	w := serveTestPanic(router, http.MethodGet, "/notes/", "error")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	body := map[string]string{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "panic: serializer exploded", body["message"])
+	assert.Contains(t, body["stack"], "recover_test.go")
+	assert.Equal(t, errTestPanic, reports[0].value)
+}
+
+func TestRecoverPanicKeepsCause(t *testing.T) {
+	reports := []testPanicReport{}
+	_, viewSet := newTestPanicRouter(&reports)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/notes/", nil)
+
+	func() {
+		defer viewSet.recoverPanic(c)
+		panic(errTestPanic)
+	}()
+	require.Equal(t, 2, len(c.Errors))
+	assert.Equal(t, "panic: serializer exploded", c.Errors[0].Error())
+	err := c.Errors.Last().Err
+	assert.ErrorIs(t, err, errTestPanic)
+	viewSetErr := new(ViewSetError)
+	require.True(t, errors.As(err, &viewSetErr))
+	assert.Equal(t, "panic", viewSetErr.Code)
+	panicErr := new(PanicError)
+	require.True(t, errors.As(err, &panicErr))
+	assert.Equal(t, errTestPanic, panicErr.Value)
+	assert.Nil(t, panicStack(err))
+
+	assert.Nil(t, (&PanicError{Value: "boom"}).Unwrap())
+
+	// panics which abort the response on purpose are left to net/http
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		defer viewSet.recoverPanic(c)
+		panic(http.ErrAbortHandler)
+	})
+	assert.Equal(t, 1, len(reports))
+}
+
+func TestViewSetRecoverPanicEdgeCases(t *testing.T) {
+	reports := []testPanicReport{}
+	router, _ := newTestPanicRouter(&reports)
+
+	w := serveTestPanic(router, http.MethodGet, "/notes/1", "written")
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "partial", w.Body.String())
+	assert.Equal(t, 1, len(reports))
+}
+
+func TestViewSetRecoversPanicsInTransactions(t *testing.T) {
+	db, err := gorm.Open(
+		sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())),
+		&gorm.Config{Logger: logger.Default.LogMode(logger.Silent)},
+	)
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&testNote{}))
+
+	noteManager := manager.NewGormManager[testNote, testNoteRequest, testNoteURI](
+		db.Model(&testNote{}), nil, nil, nil, nil, "db",
+	).EnableAtomic(0)
+	viewSet := NewViewSet[testNote, testNoteRequest](
+		"/notes", "/:pk", nil, nil, noteManager, nil, nil, newTestPanicSerializer(), nil,
+	)
+	router := SetUpRouter()
+	viewSet.Register(router)
+
+	w := serveTestPanic(router, http.MethodPost, "/notes/", "error")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, `{"message":"internal server error"}`, w.Body.String())
+
+	count := int64(0)
+	require.NoError(t, db.Model(&testNote{}).Count(&count).Error)
+	assert.Equal(t, int64(0), count)
+}
@@ -0,0 +1,38 @@
+package viewset
+
+import "github.com/gin-gonic/gin"
+
+var _ Renderer = &DefaultRenderer{}
+
+type DefaultRenderer struct{}
+
+func (_ *DefaultRenderer) Render(statusCode int, response *map[string]any, c *gin.Context) error {
+	c.JSON(statusCode, response)
+	return nil
+}
+
+func (_ *DefaultRenderer) RenderMany(
+	statusCode int, manyResponse *[]map[string]any, paginatedMeta *map[string]any, c *gin.Context,
+) error {
+	c.JSON(statusCode, map[string]any{
+		"meta":    paginatedMeta,
+		"results": manyResponse,
+	})
+	return nil
+}
+
+func (viewSet *ViewSet[_, _]) renderer() Renderer {
+	if viewSet.Renderer == nil {
+		return &DefaultRenderer{}
+	}
+	return viewSet.Renderer
+}
+
+// validateQuery lets a renderer reject the query params it reads before
+// anything is written.
+func (viewSet *ViewSet[_, _]) validateQuery(c *gin.Context) error {
+	if validator, ok := viewSet.renderer().(QueryValidator); ok {
+		return validator.ValidateQuery(c)
+	}
+	return nil
+}
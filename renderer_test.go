@@ -0,0 +1,70 @@
+package viewset
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type testRendererAlwaysError struct{}
+
+func (_ *testRendererAlwaysError) Render(_ int, _ *map[string]any, _ *gin.Context) error {
+	return errors.New("Render error")
+}
+
+func (_ *testRendererAlwaysError) RenderMany(
+	_ int, _ *[]map[string]any, _ *map[string]any, _ *gin.Context,
+) error {
+	return errors.New("Render error")
+}
+
+func TestDefaultRenderer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	renderer := &DefaultRenderer{}
+
+	response := map[string]any{"name": "test"}
+	assert.NoError(t, renderer.Render(http.StatusCreated, &response, c))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `{"name":"test"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	manyResponse := []map[string]any{response}
+	paginatedMeta := map[string]any{"count": 1}
+	assert.NoError(t, renderer.RenderMany(http.StatusOK, &manyResponse, &paginatedMeta, c))
+	assert.Equal(t, `{"meta":{"count":1},"results":[{"name":"test"}]}`, w.Body.String())
+}
+
+func TestViewSetWithoutRenderer(t *testing.T) {
+	viewSet := NewViewSet[testObject, testObjectRequest](
+		"/objects", "/:pk", nil, nil, &testObjectManager{}, nil, nil, nil, nil,
+	)
+	viewSet.Renderer = nil
+
+	assert.Equal(t, &DefaultRenderer{}, viewSet.renderer())
+}
+
+func TestRetrieveWithRenderError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	blw := &MockGinBodyResponseWriter{MockBody: bytes.NewBufferString(""), ResponseWriter: c.Writer}
+	c.Writer = blw
+	c.Params = []gin.Param{{Key: "pk", Value: "1"}}
+
+	objectManager := &testObjectManager{Database: []testObject{{Pk: 1, Name: "test", Age: 18}}}
+	viewSet := NewViewSet[testObject, testObjectRequest](
+		"/objects", "/:pk", nil, nil, objectManager, nil, nil, nil, nil,
+	)
+	viewSet.Renderer = &testRendererAlwaysError{}
+
+	Retrieve(DEFAULT_RETRIEVE_ACTION, viewSet, c)
+	assert.Equal(t, `{"message":"internal server error"}`, blw.MockBody.String())
+	assert.Equal(t, http.StatusInternalServerError, blw.MockStatusCode)
+}
@@ -1,39 +1,117 @@
 package viewset
 
 import (
+	"reflect"
+
+	"github.com/TcMits/viewset/pkg/fieldrule"
+	"github.com/TcMits/viewset/pkg/fieldset"
 	"github.com/gin-gonic/gin"
 	"github.com/mitchellh/mapstructure"
 )
 
 var _ Serializer[any] = &DefaultSerializer[any]{}
 
+var _ Expander = &DefaultSerializer[any]{}
+
+var _ VisibilityDeclarer[any] = &DefaultSerializer[any]{}
+
+// DefaultSerializer decodes entities with mapstructure and adds
+// AdditionalField, keeping only the keys selected by the fields and omit
+// query params. Additional fields which are not selected are not evaluated.
+// Write-only and hidden fields of EntityType and of the structs it nests, see
+// pkg/fieldrule, are never serialized, nor are the fields Visibility hides
+// from the request.
 type DefaultSerializer[EntityType any] struct {
 	AdditionalField map[string]Field[EntityType]
+	Expandable      map[string]Expansion
+	// optional, related objects nested when requested by the expand query param
+	FieldRules fieldrule.Rules
+	// optional, merged over the rules EntityType declares
+	Visibility FieldVisibility[EntityType]
+	// optional, fields shown or hidden per request
+}
+
+func (s *DefaultSerializer[EntityType]) Expansions() map[string]Expansion {
+	return s.Expandable
+}
+
+func (s *DefaultSerializer[EntityType]) FieldVisibility() FieldVisibility[EntityType] {
+	return s.Visibility
+}
+
+// requestFieldset returns the sparse fieldset asked by the request.
+func requestFieldset(c *gin.Context) fieldset.Set {
+	if c.Request == nil || c.Request.URL == nil {
+		return fieldset.Set{}
+	}
+	return fieldset.FromQuery(c.Request.URL.Query())
 }
 
 func (s *DefaultSerializer[EntityType]) Serialize(
 	dest *map[string]any, entity *EntityType, c *gin.Context,
+) error {
+	return s.serialize(dest, entity, requestFieldset(c), requestExpand(c), c)
+}
+
+func (s *DefaultSerializer[EntityType]) serialize(
+	dest *map[string]any, entity *EntityType, fields fieldset.Set, expand fieldset.Tree, c *gin.Context,
 ) error {
 	if err := mapstructure.Decode(entity, dest); err != nil {
 		return err
 	}
+	return s.complete(dest, entity, fields, expand, c)
+}
+
+// complete filters the decoded entity, then adds the additional fields and
+// the expanded objects which are not hidden.
+func (s *DefaultSerializer[EntityType]) complete(
+	dest *map[string]any, entity *EntityType, fields fieldset.Set, expand fieldset.Tree, c *gin.Context,
+) error {
+	fieldrule.For[EntityType]().Merge(s.FieldRules).FilterReadableOf(reflect.TypeOf(entity), *dest)
+	hidden := s.Visibility.hidden(entity, c)
+	for key := range hidden {
+		delete(*dest, key)
+	}
+	fields.Apply(*dest)
 	for k, field := range s.AdditionalField {
+		if !fields.Has(k) || hidden[k] {
+			continue
+		}
 		fieldValue, err := field.Serialize(entity, c)
 		if err != nil {
 			return err
 		}
-		(*dest)[k] = fieldValue
+		(*dest)[k] = fields.Sub(k).Filter(fieldValue)
 
 	}
+	for name, nested := range expand {
+		expansion, ok := s.Expandable[name]
+		if !ok {
+			return unknownExpansion(name, c)
+		}
+		if !fields.Has(name) || hidden[name] {
+			continue
+		}
+		related, err := expansion.expand(entity, fields.Sub(name), nested, c)
+		if err != nil {
+			return err
+		}
+		if expansion.ForeignKey != "" {
+			delete(*dest, expansion.ForeignKey)
+		}
+		(*dest)[name] = related
+	}
 	return nil
 }
 
 func (s *DefaultSerializer[EntityType]) ManySerialize(
 	dest *[]map[string]any, entities *[]*EntityType, c *gin.Context,
 ) error {
+	fields := requestFieldset(c)
+	expand := requestExpand(c)
 	for _, entity := range *entities {
 		var destObject map[string]any
-		err := s.Serialize(&destObject, entity, c)
+		err := s.serialize(&destObject, entity, fields, expand, c)
 		if err != nil {
 			return err
 		}
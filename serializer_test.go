@@ -1,9 +1,11 @@
 package viewset
 
 import (
+	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/TcMits/viewset/pkg/fieldrule"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 )
@@ -80,3 +82,119 @@ func TestDefaultSerializerManySerializeWithError(t *testing.T) {
 	err := serializer.ManySerialize(&results, &objects, c)
 	assert.Equal(t, "Fail", err.Error())
 }
+
+type testCountingField struct {
+	calls int
+}
+
+func (f *testCountingField) Serialize(object *testObject, c *gin.Context) (any, error) {
+	f.calls += 1
+	return map[string]any{"name": object.Name, "age": object.Age}, nil
+}
+
+func TestDefaultSerializerSerializeWithFieldset(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/?fields=name,summary.age", nil)
+	summary := &testCountingField{}
+	expensive := &testCountingField{}
+	serializer := DefaultSerializer[testObject]{
+		AdditionalField: map[string]Field[testObject]{
+			"summary":   summary,
+			"expensive": expensive,
+		},
+	}
+	object := testObject{Name: "test", Age: 18}
+	result := map[string]any{}
+
+	assert.NoError(t, serializer.Serialize(&result, &object, c))
+	assert.Equal(t, map[string]any{"name": "test", "summary": map[string]any{"age": 18}}, result)
+	assert.Equal(t, 1, summary.calls)
+	assert.Equal(t, 0, expensive.calls)
+
+	c.Request = httptest.NewRequest(http.MethodGet, "/?omit=age,expensive", nil)
+	objects := []*testObject{&object}
+	results := []map[string]any{}
+
+	assert.NoError(t, serializer.ManySerialize(&results, &objects, c))
+	assert.Equal(t, []map[string]any{{
+		"name":    "test",
+		"summary": map[string]any{"name": "test", "age": 18},
+	}}, results)
+	assert.Equal(t, 0, expensive.calls)
+}
+
+func TestDefaultSerializerSerializeWithFieldRules(t *testing.T) {
+	type account struct {
+		Name     string `mapstructure:"name"`
+		Password string `mapstructure:"password" viewset:"write_only"`
+		Token    string `mapstructure:"token" viewset:"hidden"`
+		Email    string `mapstructure:"email"`
+	}
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	serializer := DefaultSerializer[account]{
+		FieldRules: fieldrule.Rules{"email": {Hidden: true}},
+	}
+	result := map[string]any{}
+
+	assert.NoError(t, serializer.Serialize(&result, &account{Name: "phuc", Password: "p", Token: "t", Email: "e"}, c))
+	assert.Equal(t, map[string]any{"name": "phuc"}, result)
+}
+
+type testPostAuthor struct {
+	ID   uint   `mapstructure:"id"`
+	Hash string `mapstructure:"hash" viewset:"hidden"`
+}
+
+type testPost struct {
+	Title    string           `mapstructure:"title"`
+	Author   *testPostAuthor  `mapstructure:"author"`
+	Editors  []testPostAuthor `mapstructure:"editors"`
+	Reviewer testPostAuthor   `mapstructure:"reviewer"`
+}
+
+func newTestPost() *testPost {
+	return &testPost{
+		Title:    "post",
+		Author:   &testPostAuthor{ID: 2, Hash: "secret"},
+		Editors:  []testPostAuthor{{ID: 3, Hash: "secret"}},
+		Reviewer: testPostAuthor{ID: 4, Hash: "secret"},
+	}
+}
+
+func TestDefaultSerializerSerializeWithNestedFieldRules(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	serializer := DefaultSerializer[testPost]{}
+	result := map[string]any{}
+
+	assert.NoError(t, serializer.Serialize(&result, newTestPost(), c))
+	assert.Equal(t, map[string]any{
+		"title":    "post",
+		"author":   map[string]any{"id": uint(2)},
+		"editors":  []any{map[string]any{"id": uint(3)}},
+		"reviewer": map[string]any{"id": uint(4)},
+	}, result)
+}
+
+func TestDefaultSerializerSerializeWithNestedFieldset(t *testing.T) {
+	type item struct {
+		Name string `mapstructure:"name"`
+		Qty  int    `mapstructure:"qty"`
+	}
+	type order struct {
+		ID    uint   `mapstructure:"id"`
+		Items []item `mapstructure:"items"`
+	}
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/?fields=items.name", nil)
+	serializer := DefaultSerializer[order]{}
+	result := map[string]any{}
+
+	assert.NoError(t, serializer.Serialize(&result, &order{ID: 1, Items: []item{{Name: "a", Qty: 1}}}, c))
+	assert.Equal(t, map[string]any{"items": []any{map[string]any{"name": "a"}}}, result)
+}
@@ -0,0 +1,123 @@
+package viewset
+
+import (
+	"net/http"
+
+	"github.com/TcMits/viewset/manager"
+	"github.com/gin-gonic/gin"
+)
+
+// singletonObjectGetter lets the default handlers resolve the object of a
+// singleton resource through GetObject.
+type singletonObjectGetter[EntityType any] struct {
+	manager.SingletonGetter[EntityType]
+}
+
+func (getter singletonObjectGetter[EntityType]) GetObject(dest **EntityType, c *gin.Context) error {
+	return getter.GetSingleton(dest, c)
+}
+
+// singletonSaver creates the object of a singleton resource through
+// CreateSingleton when the manager supports it, so the object is owned by
+// the request.
+type singletonSaver[EntityType, ValidateType any] struct {
+	manager.SingletonManager[EntityType, ValidateType]
+}
+
+func (saver singletonSaver[EntityType, ValidateType]) Save(
+	dest **EntityType, validatedData *ValidateType, c *gin.Context,
+) error {
+	creator, ok := saver.SingletonManager.(manager.SingletonCreator[EntityType, ValidateType])
+	if *dest == nil && ok {
+		return creator.CreateSingleton(dest, validatedData, c)
+	}
+	return saver.SingletonManager.Save(dest, validatedData, c)
+}
+
+// NewSingletonViewSet registers retrieve, update and optionally delete
+// actions on the base path for resources without a primary key, like
+// /me/profile. With createOnPut, a PUT creates the object when the manager
+// cannot find it, the GormManager and the MemoryManager need a singleton
+// owner to assign to it.
+func NewSingletonViewSet[EntityType, ValidateType any](
+	basePath string,
+	createOnPut bool,
+	allowDelete bool,
+	additionalActions []Route[EntityType, ValidateType],
+
+	singletonManager manager.SingletonManager[EntityType, ValidateType],
+	exceptionHandler ExceptionHandler,
+	permissionChecker PermissionChecker,
+	serializer Serializer[EntityType],
+	formValidator FormValidator[EntityType, ValidateType],
+) *ViewSet[EntityType, ValidateType] {
+	if singletonManager == nil {
+		panic("manager is required")
+	}
+	composed := &manager.ComposedManager[EntityType, ValidateType]{
+		ObjectGetter: singletonObjectGetter[EntityType]{singletonManager},
+		Saver:        singletonSaver[EntityType, ValidateType]{singletonManager},
+		Deleter:      singletonManager,
+	}
+	viewSet := NewViewSet[EntityType, ValidateType](
+		basePath, "", defaultActions, nil, composed,
+		exceptionHandler, permissionChecker, serializer, formValidator,
+	)
+
+	viewSet.Actions = append(
+		viewSet.Actions,
+		Route[EntityType, ValidateType]{
+			Action:  DEFAULT_RETRIEVE_ACTION,
+			SubPath: "",
+			Method:  http.MethodGet,
+			Handler: Retrieve[EntityType, ValidateType],
+		}, Route[EntityType, ValidateType]{
+			Action:  DEFAULT_UPDATE_ACTION,
+			SubPath: "",
+			Method:  http.MethodPut,
+			Handler: NewSingletonUpdate[EntityType, ValidateType](createOnPut),
+		}, Route[EntityType, ValidateType]{
+			Action:  DEFAULT_UPDATE_ACTION,
+			SubPath: "",
+			Method:  http.MethodPatch,
+			Handler: Update[EntityType, ValidateType],
+		},
+	)
+	if allowDelete {
+		viewSet.Actions = append(viewSet.Actions, Route[EntityType, ValidateType]{
+			Action:  DEFAULT_DELETE_ACTION,
+			SubPath: "",
+			Method:  http.MethodDelete,
+			Handler: Delete[EntityType, ValidateType],
+		})
+	}
+	viewSet.Actions = append(viewSet.Actions, additionalActions...)
+	return viewSet
+}
+
+// NewSingletonUpdate returns an update handler which, with createOnPut,
+// creates the object instead of answering 404 when it does not exist yet.
+func NewSingletonUpdate[EntityType, ValidateType any](
+	createOnPut bool,
+) HandlerWithViewSetFunc[EntityType, ValidateType] {
+	return func(action string, viewSet *ViewSet[EntityType, ValidateType], c *gin.Context) {
+		if !createOnPut {
+			Update(action, viewSet, c)
+			return
+		}
+		entity := new(EntityType)
+
+		err := viewSet.observe(action, GET_OBJECT_PHASE, c, func() error {
+			return viewSet.Manager.GetObject(&entity, c)
+		})
+		if manager.IsNotFound(err) {
+			save(action, viewSet, c, nil, http.StatusCreated)
+			return
+		}
+		if err != nil {
+			viewSet.ExceptionHandler.Handle(classifiedError(err, c), c)
+			return
+		}
+		save(action, viewSet, c, entity, http.StatusOK)
+	}
+}
@@ -0,0 +1,155 @@
+package viewset
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/TcMits/viewset/manager"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type testProfile struct {
+	Owner string `mapstructure:"owner"`
+	Name  string `mapstructure:"name"`
+}
+
+type testProfileRequest struct {
+	Name string `json:"name" mapstructure:"name" binding:"required"`
+}
+
+type testProfileURI struct{}
+
+func newTestProfileRouter(createOnPut bool, allowDelete bool) (*gin.Engine, *ViewSet[testProfile, testProfileRequest]) {
+	profileManager := manager.NewMemoryManager[testProfile, testProfileRequest, testProfileURI](
+		"Owner",
+	).SetSingletonFilter(func(profile *testProfile, c *gin.Context) bool {
+		return profile.Owner == c.GetHeader("X-User")
+	}).SetSingletonOwner(func(c *gin.Context) map[string]any {
+		return map[string]any{"Owner": c.GetHeader("X-User")}
+	})
+	profileManager.Add(testProfile{Owner: "phuc", Name: "Phuc"})
+	viewSet := NewSingletonViewSet[testProfile, testProfileRequest](
+		"/me/profile", createOnPut, allowDelete, nil, profileManager, nil, nil, nil, nil,
+	)
+	router := SetUpRouter()
+	viewSet.Register(router)
+	return router, viewSet
+}
+
+func serveTestProfile(router *gin.Engine, method string, user string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/me/profile", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User", user)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestNewSingletonViewSet(t *testing.T) {
+	_, viewSet := newTestProfileRouter(false, false)
+	assert.Equal(t, []string{"GET retrieve", "PUT update", "PATCH update"}, routeKeys(viewSet))
+
+	_, viewSet = newTestProfileRouter(false, true)
+	assert.Equal(t, []string{"GET retrieve", "PUT update", "PATCH update", "DELETE delete"}, routeKeys(viewSet))
+}
+
+func TestSingletonRetrieveAndUpdate(t *testing.T) {
+	router, _ := newTestProfileRouter(false, false)
+
+	w := serveTestProfile(router, http.MethodGet, "phuc", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"name":"Phuc","owner":"phuc"}`, w.Body.String())
+
+	w = serveTestProfile(router, http.MethodPatch, "phuc", `{"name":"Phuc 2"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"name":"Phuc 2","owner":"phuc"}`, w.Body.String())
+
+	w = serveTestProfile(router, http.MethodGet, "huy", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = serveTestProfile(router, http.MethodPut, "huy", `{"name":"Huy"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = serveTestProfile(router, http.MethodDelete, "phuc", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestSingletonCreateOnPut(t *testing.T) {
+	router, _ := newTestProfileRouter(true, true)
+
+	w := serveTestProfile(router, http.MethodPatch, "huy", `{"name":"Huy"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = serveTestProfile(router, http.MethodPut, "huy", `{"name":"Huy"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `{"name":"Huy","owner":"huy"}`, w.Body.String())
+	w = serveTestProfile(router, http.MethodGet, "huy", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"name":"Huy","owner":"huy"}`, w.Body.String())
+
+	w = serveTestProfile(router, http.MethodPut, "phuc", `{"name":"Phuc 2"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"name":"Phuc 2","owner":"phuc"}`, w.Body.String())
+
+	w = serveTestProfile(router, http.MethodPut, "phuc", `{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = serveTestProfile(router, http.MethodDelete, "phuc", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = serveTestProfile(router, http.MethodGet, "phuc", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+type testOwnedProfile struct {
+	ID    uint   `mapstructure:"id" gorm:"primaryKey"`
+	Owner string `mapstructure:"owner"`
+	Name  string `mapstructure:"name"`
+}
+
+func TestSingletonCreateOnPutWithGorm(t *testing.T) {
+	db, err := gorm.Open(
+		sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())),
+		&gorm.Config{Logger: logger.Default.LogMode(logger.Silent)},
+	)
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&testOwnedProfile{}))
+
+	profileManager := manager.NewGormManager[testOwnedProfile, testProfileRequest, testProfileURI](
+		db.Model(&testOwnedProfile{}), nil, nil, nil, nil, "db",
+	).SetSingletonScope(func(c *gin.Context) func(*gorm.DB) *gorm.DB {
+		return func(db *gorm.DB) *gorm.DB {
+			return db.Where("owner = ?", c.GetHeader("X-User"))
+		}
+	})
+	viewSet := NewSingletonViewSet[testOwnedProfile, testProfileRequest](
+		"/me/profile", true, false, nil, profileManager, nil, nil, nil, nil,
+	)
+	router := SetUpRouter()
+	viewSet.Register(router)
+
+	w := serveTestProfile(router, http.MethodPut, "huy", `{"name":"Huy"}`)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	profileManager.SetSingletonOwner(func(c *gin.Context) map[string]any {
+		return map[string]any{"Owner": c.GetHeader("X-User")}
+	})
+	w = serveTestProfile(router, http.MethodPut, "huy", `{"name":"Huy"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `{"id":1,"name":"Huy","owner":"huy"}`, w.Body.String())
+
+	w = serveTestProfile(router, http.MethodGet, "huy", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"id":1,"name":"Huy","owner":"huy"}`, w.Body.String())
+
+	w = serveTestProfile(router, http.MethodPut, "huy", `{"name":"Huy 2"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	count := int64(0)
+	require.NoError(t, db.Model(&testOwnedProfile{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
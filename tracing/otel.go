@@ -0,0 +1,72 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var _ Tracer = &OpenTelemetryTracer{}
+var _ Span = &openTelemetrySpan{}
+
+// OpenTelemetryTracer adapts an OpenTelemetry trace.Tracer, spans are
+// exported by whatever TracerProvider created it.
+type OpenTelemetryTracer struct {
+	Tracer     trace.Tracer
+	Propagator propagation.TextMapPropagator
+}
+
+type openTelemetrySpan struct {
+	span trace.Span
+}
+
+func NewOpenTelemetryTracer(tracer trace.Tracer) *OpenTelemetryTracer {
+	return &OpenTelemetryTracer{
+		Tracer:     tracer,
+		Propagator: propagation.TraceContext{},
+	}
+}
+
+func (t *OpenTelemetryTracer) Extract(ctx context.Context, header http.Header) context.Context {
+	return t.Propagator.Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+func (t *OpenTelemetryTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	ctx, span := t.Tracer.Start(ctx, name)
+	return ctx, &openTelemetrySpan{span: span}
+}
+
+func (s *openTelemetrySpan) SetAttribute(key string, value any) {
+	s.span.SetAttributes(toAttribute(key, value))
+}
+
+func (s *openTelemetrySpan) RecordError(err error) {
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+func (s *openTelemetrySpan) End() {
+	s.span.End()
+}
+
+func toAttribute(key string, value any) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case float64:
+		return attribute.Float64(key, v)
+	default:
+		return attribute.String(key, fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,36 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestOpenTelemetryTracerExtract(t *testing.T) {
+	tracer := NewOpenTelemetryTracer(trace.NewNoopTracerProvider().Tracer("viewset"))
+	header := http.Header{}
+	header.Set(TRACEPARENT_HEADER, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	ctx := tracer.Extract(context.Background(), header)
+	ctx, span := tracer.Start(ctx, "viewset.list")
+	span.SetAttribute("http.status_code", 200)
+	span.RecordError(errors.New("testing"))
+	span.End()
+
+	sc := trace.SpanContextFromContext(ctx)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID().String())
+	assert.Equal(t, true, sc.IsSampled())
+}
+
+func TestToAttribute(t *testing.T) {
+	assert.Equal(t, "value", toAttribute("key", "value").Value.AsString())
+	assert.Equal(t, true, toAttribute("key", true).Value.AsBool())
+	assert.Equal(t, int64(1), toAttribute("key", 1).Value.AsInt64())
+	assert.Equal(t, int64(2), toAttribute("key", int64(2)).Value.AsInt64())
+	assert.Equal(t, 1.5, toAttribute("key", 1.5).Value.AsFloat64())
+	assert.Equal(t, "[1 2]", toAttribute("key", []int{1, 2}).Value.AsString())
+}
@@ -0,0 +1,120 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var _ Tracer = &Recorder{}
+var _ Span = &RecordedSpan{}
+
+type spanContextKey struct{}
+
+// RecordedSpan is a span kept in memory by Recorder.
+type RecordedSpan struct {
+	Name         string
+	SpanContext  SpanContext
+	Parent       SpanContext
+	Attributes   map[string]any
+	Errors       []error
+	StartTime    time.Time
+	EndTime      time.Time
+	Ended        bool
+	recorderLock *sync.Mutex
+}
+
+func (s *RecordedSpan) SetAttribute(key string, value any) {
+	s.recorderLock.Lock()
+	defer s.recorderLock.Unlock()
+	s.Attributes[key] = value
+}
+
+func (s *RecordedSpan) RecordError(err error) {
+	s.recorderLock.Lock()
+	defer s.recorderLock.Unlock()
+	s.Errors = append(s.Errors, err)
+}
+
+func (s *RecordedSpan) End() {
+	s.recorderLock.Lock()
+	defer s.recorderLock.Unlock()
+	if s.Ended {
+		return
+	}
+	s.EndTime = time.Now()
+	s.Ended = true
+}
+
+func (s *RecordedSpan) Duration() time.Duration {
+	return s.EndTime.Sub(s.StartTime)
+}
+
+// Recorder is an in-memory Tracer, meant for tests and debugging.
+type Recorder struct {
+	mu    sync.Mutex
+	spans []*RecordedSpan
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+func (r *Recorder) Extract(ctx context.Context, header http.Header) context.Context {
+	sc, err := ParseTraceParent(header.Get(TRACEPARENT_HEADER))
+	if err != nil {
+		return ctx
+	}
+	sc.TraceState = header.Get(TRACESTATE_HEADER)
+	return ContextWithSpanContext(ctx, sc)
+}
+
+func (r *Recorder) Start(ctx context.Context, name string) (context.Context, Span) {
+	parent := SpanContextFromContext(ctx)
+	sc := SpanContext{
+		TraceID:    parent.TraceID,
+		TraceFlags: parent.TraceFlags,
+		TraceState: parent.TraceState,
+	}
+	if !parent.IsValid() {
+		rand.Read(sc.TraceID[:])
+		sc.TraceFlags = 0x01
+	}
+	rand.Read(sc.SpanID[:])
+
+	span := &RecordedSpan{
+		Name:         name,
+		SpanContext:  sc,
+		Parent:       parent,
+		Attributes:   map[string]any{},
+		StartTime:    time.Now(),
+		recorderLock: &r.mu,
+	}
+	r.mu.Lock()
+	r.spans = append(r.spans, span)
+	r.mu.Unlock()
+	return ContextWithSpanContext(ctx, sc), span
+}
+
+func (r *Recorder) Spans() []*RecordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*RecordedSpan{}, r.spans...)
+}
+
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = nil
+}
+
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	sc, _ := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc
+}
@@ -0,0 +1,52 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorderStart(t *testing.T) {
+	recorder := NewRecorder()
+
+	ctx, parent := recorder.Start(context.Background(), "parent")
+	_, child := recorder.Start(ctx, "child")
+	child.SetAttribute("key", "value")
+	child.RecordError(errors.New("testing"))
+	child.End()
+	child.End()
+	parent.End()
+
+	spans := recorder.Spans()
+	assert.Equal(t, 2, len(spans))
+	assert.Equal(t, true, spans[0].SpanContext.IsValid())
+	assert.Equal(t, true, spans[0].SpanContext.IsSampled())
+	assert.Equal(t, false, spans[0].Parent.IsValid())
+	assert.Equal(t, spans[0].SpanContext.TraceID, spans[1].SpanContext.TraceID)
+	assert.Equal(t, spans[0].SpanContext.SpanID, spans[1].Parent.SpanID)
+	assert.Equal(t, "value", spans[1].Attributes["key"])
+	assert.Equal(t, "testing", spans[1].Errors[0].Error())
+	assert.Equal(t, true, spans[1].Ended)
+	assert.GreaterOrEqual(t, int64(spans[1].Duration()), int64(0))
+
+	recorder.Reset()
+	assert.Equal(t, 0, len(recorder.Spans()))
+}
+
+func TestRecorderExtract(t *testing.T) {
+	recorder := NewRecorder()
+	header := http.Header{}
+	header.Set(TRACEPARENT_HEADER, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	header.Set(TRACESTATE_HEADER, "vendor=value")
+
+	sc := SpanContextFromContext(recorder.Extract(context.Background(), header))
+	assert.Equal(t, true, sc.IsValid())
+	assert.Equal(t, false, sc.IsSampled())
+	assert.Equal(t, "vendor=value", sc.TraceState)
+
+	sc = SpanContextFromContext(recorder.Extract(context.Background(), http.Header{}))
+	assert.Equal(t, false, sc.IsValid())
+}
@@ -0,0 +1,148 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/TcMits/viewset"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	TRACEPARENT_HEADER = "traceparent"
+	TRACESTATE_HEADER  = "tracestate"
+
+	actionSpanKey = "github.com/TcMits/viewset/tracing.actionSpan"
+)
+
+var _ viewset.Instrumentation = &Instrumentation{}
+
+var ErrInvalidTraceParent = errors.New("invalid traceparent header")
+
+type Span interface {
+	SetAttribute(string, any)
+	RecordError(error)
+	End()
+}
+
+type Tracer interface {
+	Extract(context.Context, http.Header) context.Context
+	// return a context carrying the remote span described by the headers, if any
+	Start(context.Context, string) (context.Context, Span)
+	// start a span, child of the span found in the context
+}
+
+type SpanContext struct {
+	TraceID    [16]byte
+	SpanID     [8]byte
+	TraceFlags byte
+	TraceState string
+	Remote     bool
+}
+
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != [16]byte{} && sc.SpanID != [8]byte{}
+}
+
+func (sc SpanContext) IsSampled() bool {
+	return sc.TraceFlags&0x01 == 0x01
+}
+
+func (sc SpanContext) TraceParent() string {
+	return "00-" + hex.EncodeToString(sc.TraceID[:]) + "-" +
+		hex.EncodeToString(sc.SpanID[:]) + "-" + hex.EncodeToString([]byte{sc.TraceFlags})
+}
+
+// ParseTraceParent parses a W3C trace context traceparent header value.
+func ParseTraceParent(value string) (SpanContext, error) {
+	sc := SpanContext{}
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 ||
+		len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return sc, ErrInvalidTraceParent
+	}
+	version, err := hex.DecodeString(parts[0])
+	if err != nil || version[0] == 0xff || (version[0] == 0 && len(parts) != 4) {
+		return sc, ErrInvalidTraceParent
+	}
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return sc, ErrInvalidTraceParent
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return sc, ErrInvalidTraceParent
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return sc, ErrInvalidTraceParent
+	}
+	sc.TraceFlags = flags[0]
+	sc.Remote = true
+	if !sc.IsValid() {
+		return SpanContext{}, ErrInvalidTraceParent
+	}
+	return sc, nil
+}
+
+// Instrumentation starts a span for each viewset action, continuing the
+// trace of the incoming traceparent header, and a child span for each phase.
+type Instrumentation struct {
+	Tracer Tracer
+}
+
+func NewInstrumentation(tracer Tracer) *Instrumentation {
+	return &Instrumentation{Tracer: tracer}
+}
+
+func (i *Instrumentation) StartAction(action string, c *gin.Context) func() {
+	ctx := context.Background()
+	if c.Request != nil {
+		ctx = i.Tracer.Extract(c.Request.Context(), c.Request.Header)
+	}
+	ctx, span := i.Tracer.Start(ctx, "viewset."+action)
+	span.SetAttribute("viewset.action", action)
+	span.SetAttribute("http.route", c.FullPath())
+	if c.Request != nil {
+		span.SetAttribute("http.method", c.Request.Method)
+		c.Request = c.Request.WithContext(ctx)
+	}
+	c.Set(actionSpanKey, ctx)
+
+	return func() {
+		status := c.Writer.Status()
+		span.SetAttribute("http.status_code", status)
+		if status >= http.StatusInternalServerError && len(c.Errors) > 0 {
+			span.RecordError(c.Errors.Last().Err)
+		}
+		span.End()
+	}
+}
+
+func (i *Instrumentation) StartPhase(action string, phase string, c *gin.Context) func(error) {
+	ctx := ActionContext(c)
+	_, span := i.Tracer.Start(ctx, "viewset."+action+"."+phase)
+	span.SetAttribute("viewset.action", action)
+	span.SetAttribute("viewset.phase", phase)
+	return func(err error) {
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+	}
+}
+
+// ActionContext returns the context carrying the span of the current action,
+// use it to start spans or propagate the trace from custom code.
+func ActionContext(c *gin.Context) context.Context {
+	if value, ok := c.Get(actionSpanKey); ok {
+		if ctx, ok := value.(context.Context); ok {
+			return ctx
+		}
+	}
+	if c.Request != nil {
+		return c.Request.Context()
+	}
+	return context.Background()
+}
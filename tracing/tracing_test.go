@@ -0,0 +1,138 @@
+package tracing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TcMits/viewset"
+	"github.com/TcMits/viewset/manager"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type book struct {
+	ID    int    `mapstructure:"id"`
+	Title string `mapstructure:"title"`
+}
+
+type bookRequest struct {
+	Title string `json:"title" binding:"required"`
+}
+
+type bookManager struct{}
+
+func (_ *bookManager) GetObjects(dest *[]*book, paginatedMeta *map[string]any, _ *gin.Context) error {
+	*paginatedMeta = map[string]any{}
+	*dest = append(*dest, &book{ID: 1, Title: "test"})
+	return nil
+}
+
+func (_ *bookManager) GetObject(dest **book, c *gin.Context) error {
+	if c.Param("pk") != "1" {
+		return manager.ErrObjectNotFound
+	}
+	*dest = &book{ID: 1, Title: "test"}
+	return nil
+}
+
+func (_ *bookManager) Save(dest **book, validatedData *bookRequest, _ *gin.Context) error {
+	*dest = &book{ID: 2, Title: validatedData.Title}
+	return nil
+}
+
+func (_ *bookManager) Delete(_ **book, _ *gin.Context) error {
+	return nil
+}
+
+func setUpRouter(recorder *Recorder) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	viewSet := viewset.NewViewSet[book, bookRequest](
+		"/books", "/:pk", nil, nil, &bookManager{}, nil, nil, nil, nil,
+	)
+	viewSet.Instrumentation = NewInstrumentation(recorder)
+	viewSet.Register(router)
+	return router
+}
+
+func TestParseTraceParent(t *testing.T) {
+	sc, err := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	assert.NoError(t, err)
+	assert.Equal(t, true, sc.IsValid())
+	assert.Equal(t, true, sc.IsSampled())
+	assert.Equal(t, true, sc.Remote)
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", sc.TraceParent())
+}
+
+func TestParseTraceParentInvalid(t *testing.T) {
+	for _, value := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473z-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	} {
+		_, err := ParseTraceParent(value)
+		assert.Equal(t, ErrInvalidTraceParent, err, value)
+	}
+}
+
+func TestInstrumentationSpans(t *testing.T) {
+	recorder := NewRecorder()
+	router := setUpRouter(recorder)
+
+	req := httptest.NewRequest(http.MethodGet, "/books/1", nil)
+	req.Header.Set(TRACEPARENT_HEADER, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Spans()
+	names := []string{}
+	for _, span := range spans {
+		names = append(names, span.Name)
+		assert.Equal(t, true, span.Ended)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext.TraceParent()[3:35])
+	}
+	assert.Equal(t, []string{
+		"viewset.retrieve",
+		"viewset.retrieve.permission",
+		"viewset.retrieve.get_object",
+		"viewset.retrieve.serialize",
+		"viewset.retrieve.render",
+	}, names)
+
+	actionSpan := spans[0]
+	assert.Equal(t, "00f067aa0ba902b7", actionSpan.Parent.TraceParent()[36:52])
+	assert.Equal(t, true, actionSpan.Parent.Remote)
+	assert.Equal(t, http.StatusOK, actionSpan.Attributes["http.status_code"])
+	assert.Equal(t, "/books/:pk", actionSpan.Attributes["http.route"])
+	assert.Equal(t, http.MethodGet, actionSpan.Attributes["http.method"])
+	for _, span := range spans[1:] {
+		assert.Equal(t, actionSpan.SpanContext.SpanID, span.Parent.SpanID)
+	}
+}
+
+func TestInstrumentationSpansWithError(t *testing.T) {
+	recorder := NewRecorder()
+	router := setUpRouter(recorder)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/books/2", nil))
+
+	spans := recorder.Spans()
+	assert.Equal(t, 3, len(spans))
+	assert.Equal(t, "viewset.retrieve.get_object", spans[2].Name)
+	assert.Equal(t, "object not found", spans[2].Errors[0].Error())
+	assert.Equal(t, http.StatusNotFound, spans[0].Attributes["http.status_code"])
+	assert.Equal(t, false, spans[0].Parent.IsValid())
+}
+
+func TestActionContextWithoutAction(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	assert.NotNil(t, ActionContext(c))
+	assert.Equal(t, false, SpanContextFromContext(ActionContext(c)).IsValid())
+}
@@ -3,6 +3,7 @@ package viewset
 import (
 	"errors"
 
+	"github.com/TcMits/viewset/manager"
 	"github.com/gin-gonic/gin"
 )
 
@@ -65,7 +66,7 @@ func (om *testObjectManager) GetObject(
 			return nil
 		}
 	}
-	return errors.New("Object not found")
+	return manager.ErrObjectNotFound
 }
 
 func (om *testObjectManager) Save(
@@ -105,7 +106,7 @@ func (om *testObjectManager) Delete(
 			return nil
 		}
 	}
-	return errors.New("Object not found")
+	return manager.ErrObjectNotFound
 }
 
 func SetUpRouter() *gin.Engine {
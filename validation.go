@@ -0,0 +1,284 @@
+package viewset
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const NON_FIELD_ERRORS = "non_field_errors"
+
+type ValidationError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors maps the path of each invalid field, as named in the
+// request like items[1].name, to its errors. A FormValidator may return it
+// as is, DefaultExceptionHandler renders it under errors.
+type ValidationErrors map[string][]ValidationError
+
+func (errs ValidationErrors) Error() string {
+	fields := make([]string, 0, len(errs))
+	for field := range errs {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	messages := make([]string, 0, len(fields))
+	for _, field := range fields {
+		for _, err := range errs[field] {
+			messages = append(messages, field+": "+err.Message)
+		}
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (errs ValidationErrors) add(field string, code string, message string) {
+	errs[field] = append(errs[field], ValidationError{Code: code, Message: message})
+}
+
+// validationError converts the errors of binding and validating a
+// ValidateType to ValidationErrors in the locale of the request, other errors
+// are returned as they are.
+func validationError[ValidateType any](err error, c *gin.Context) error {
+	validateType := reflect.TypeOf((*ValidateType)(nil)).Elem()
+	errs := ValidationErrors{}
+
+	validationErrs := validator.ValidationErrors{}
+	fieldErrs := FieldErrors{}
+	syntaxErr := new(json.SyntaxError)
+	typeErr := new(json.UnmarshalTypeError)
+	switch {
+	case errors.As(err, &errs):
+		return err
+	case errors.As(err, &validationErrs):
+		for _, fieldErr := range validationErrs {
+			errs.add(
+				fieldPath(validateType, fieldErr.StructNamespace()),
+				fieldErr.Tag(),
+				validationMessage(c, fieldErr.Tag(), fieldErr.Param(), fieldErr.Kind()),
+			)
+		}
+	case errors.As(err, &fieldErrs):
+		for _, fieldErr := range fieldErrs {
+			errs.add(
+				fieldPath(validateType, fieldErr.Namespace),
+				fieldErr.Tag,
+				validationMessage(c, fieldErr.Tag, fieldErr.Param, fieldKind(validateType, fieldErr.Namespace)),
+			)
+		}
+	case errors.As(err, &syntaxErr):
+		errs.add(
+			NON_FIELD_ERRORS, "invalid_json",
+			translate(c, "validation.invalid_json_at", strconv.FormatInt(syntaxErr.Offset, 10)),
+		)
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		errs.add(NON_FIELD_ERRORS, "invalid_json", translate(c, "validation.invalid_json"))
+	case errors.As(err, &typeErr):
+		field := typeErrorPath(validateType, typeErr.Field)
+		if field == "" {
+			field = NON_FIELD_ERRORS
+		}
+		errs.add(field, "invalid_type", translate(c, "validation.invalid_type", typeErr.Type.String(), typeErr.Value))
+	default:
+		return err
+	}
+	return NewViewSetError(errs.Error(), http.StatusBadRequest, errs).WithCode("invalid")
+}
+
+// fieldPath turns the Go namespace of a field, like BookRequest.Items[1].Name,
+// into the path of the request, like items[1].name. Names come from the json
+// tag, then the form tag, fields of embedded structs are not nested.
+func fieldPath(validateType reflect.Type, namespace string) string {
+	segments := strings.Split(namespace, ".")[1:]
+	path := make([]string, 0, len(segments))
+	current := validateType
+	for _, segment := range segments {
+		name, index, _ := strings.Cut(segment, "[")
+		if index != "" {
+			index = "[" + index
+		}
+		for current != nil && current.Kind() == reflect.Pointer {
+			current = current.Elem()
+		}
+		var field reflect.StructField
+		found := false
+		if current != nil && current.Kind() == reflect.Struct {
+			field, found = current.FieldByName(name)
+		}
+		if !found {
+			path = append(path, segment)
+			current = nil
+			continue
+		}
+		current = field.Type
+		for i := strings.Count(index, "["); i > 0 && current != nil; i-- {
+			for current.Kind() == reflect.Pointer {
+				current = current.Elem()
+			}
+			switch current.Kind() {
+			case reflect.Slice, reflect.Array, reflect.Map:
+				current = current.Elem()
+			default:
+				current = nil
+			}
+		}
+		requestName, named := requestFieldName(field)
+		if field.Anonymous && !named {
+			if index != "" && len(path) > 0 {
+				path[len(path)-1] += index
+			}
+			continue
+		}
+		path = append(path, requestName+index)
+	}
+	return strings.Join(path, ".")
+}
+
+// typeErrorPath turns the path of a json.UnmarshalTypeError, like
+// items.1.name, into the path of the request, like items[1].name.
+func typeErrorPath(validateType reflect.Type, field string) string {
+	if field == "" {
+		return ""
+	}
+	path := ""
+	current := validateType
+	for _, segment := range strings.Split(field, ".") {
+		for current != nil && current.Kind() == reflect.Pointer {
+			current = current.Elem()
+		}
+		isElem := false
+		switch {
+		case current == nil, current.Kind() == reflect.Interface:
+			// unknown type, like any, numbers are taken as indexes
+			_, err := strconv.Atoi(segment)
+			isElem = err == nil
+			current = nil
+		case current.Kind() == reflect.Slice, current.Kind() == reflect.Array, current.Kind() == reflect.Map:
+			isElem = true
+			current = current.Elem()
+		case current.Kind() == reflect.Struct:
+			current = jsonFieldType(current, segment)
+		default:
+			current = nil
+		}
+		if isElem {
+			path += "[" + segment + "]"
+			continue
+		}
+		if path != "" {
+			path += "."
+		}
+		path += segment
+	}
+	return path
+}
+
+// jsonFieldType returns the type of the field decoded from the json key,
+// looking into embedded structs like encoding/json, nil if there is none.
+func jsonFieldType(t reflect.Type, key string) reflect.Type {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if found := jsonFieldType(embedded, key); found != nil {
+					return found
+				}
+			}
+			continue
+		}
+		if name == key || (name == "" && strings.EqualFold(field.Name, key)) {
+			return field.Type
+		}
+	}
+	return nil
+}
+
+func requestFieldName(field reflect.StructField) (string, bool) {
+	for _, key := range []string{"json", "form"} {
+		name := strings.Split(field.Tag.Get(key), ",")[0]
+		if name != "" && name != "-" {
+			return name, true
+		}
+	}
+	return field.Name, false
+}
+
+// fieldKind returns the kind of the field at a Go namespace, Invalid if it
+// cannot be found. Indexes like Items[1] step into the element type.
+func fieldKind(validateType reflect.Type, namespace string) reflect.Kind {
+	current := validateType
+	for _, segment := range strings.Split(namespace, ".")[1:] {
+		for current.Kind() == reflect.Pointer {
+			current = current.Elem()
+		}
+		if current.Kind() != reflect.Struct {
+			return reflect.Invalid
+		}
+		name, _, _ := strings.Cut(segment, "[")
+		field, ok := current.FieldByName(name)
+		if !ok {
+			return reflect.Invalid
+		}
+		current = field.Type
+		for i := strings.Count(segment, "["); i > 0; i-- {
+			for current.Kind() == reflect.Pointer {
+				current = current.Elem()
+			}
+			switch current.Kind() {
+			case reflect.Slice, reflect.Array, reflect.Map:
+				current = current.Elem()
+			default:
+				return reflect.Invalid
+			}
+		}
+	}
+	for current.Kind() == reflect.Pointer {
+		current = current.Elem()
+	}
+	return current.Kind()
+}
+
+// validationMessage describes a validator tag with the message keyed
+// validation.<tag>, or validation.<tag>.string and validation.<tag>.items
+// for sizes of strings and collections, else validation.default.
+func validationMessage(c *gin.Context, tag string, param string, kind reflect.Kind) string {
+	key := tag
+	switch tag {
+	case "gte":
+		key = "min"
+	case "lte":
+		key = "max"
+	case "eq":
+		key = "len"
+	case "oneof":
+		param = strings.Join(strings.Fields(param), ", ")
+	}
+	keys := []string{"validation." + key}
+	switch kind {
+	case reflect.String:
+		keys = append([]string{"validation." + key + ".string"}, keys...)
+	case reflect.Slice, reflect.Array, reflect.Map:
+		keys = append([]string{"validation." + key + ".items"}, keys...)
+	}
+	if text, ok := message(c, keys, param); ok {
+		return text
+	}
+	return translate(c, "validation.default", tag)
+}
@@ -0,0 +1,161 @@
+package viewset
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testOrderBase struct {
+	Reference string `json:"reference" binding:"required"`
+}
+
+type testOrderItem struct {
+	Name     string `json:"name" binding:"required"`
+	Quantity int    `form:"qty" binding:"gte=1"`
+}
+
+type testOrderRequest struct {
+	testOrderBase
+	Note     string           `json:"note" binding:"max=5"`
+	Status   string           `json:"status" binding:"oneof=open closed"`
+	Items    []testOrderItem  `json:"items" binding:"required,min=1,dive"`
+	Shipping *testOrderItem   `json:"shipping"`
+	Tags     map[string]int   `json:"tags" binding:"omitempty,dive,lte=3"`
+	Extra    []*testOrderItem `json:"-" binding:"dive"`
+}
+
+func testValidationError(t *testing.T, err error) ValidationErrors {
+	t.Helper()
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	converted := validationError[testOrderRequest](err, c)
+	viewSetErr := new(ViewSetError)
+	require.True(t, errors.As(converted, &viewSetErr), "%v", converted)
+	assert.Equal(t, http.StatusBadRequest, viewSetErr.StatusCode)
+	errs := ValidationErrors{}
+	require.True(t, errors.As(converted, &errs))
+	assert.Equal(t, errs.Error(), converted.Error())
+	return errs
+}
+
+func TestValidationErrorFromValidator(t *testing.T) {
+	request := testOrderRequest{
+		Note:     "too long",
+		Status:   "lost",
+		Items:    []testOrderItem{{Name: "pen", Quantity: 1}, {Quantity: 0}},
+		Shipping: &testOrderItem{Name: "post"},
+		Tags:     map[string]int{"a": 4},
+		Extra:    []*testOrderItem{{Name: "x", Quantity: 1}},
+	}
+	errs := testValidationError(t, binding.Validator.ValidateStruct(&request))
+
+	assert.Equal(t, ValidationErrors{
+		"reference":     {{Code: "required", Message: "This field is required."}},
+		"note":          {{Code: "max", Message: "Must be at most 5 characters."}},
+		"status":        {{Code: "oneof", Message: "Must be one of: open, closed."}},
+		"items[1].name": {{Code: "required", Message: "This field is required."}},
+		"items[1].qty":  {{Code: "gte", Message: "Must be at least 1."}},
+		"shipping.qty":  {{Code: "gte", Message: "Must be at least 1."}},
+		"tags[a]":       {{Code: "lte", Message: "Must be at most 3."}},
+	}, errs)
+
+	request = testOrderRequest{testOrderBase: testOrderBase{Reference: "A1"}, Status: "open", Extra: []*testOrderItem{{}}}
+	errs = testValidationError(t, binding.Validator.ValidateStruct(&request))
+	assert.Equal(t, ValidationErrors{
+		"items":         {{Code: "required", Message: "This field is required."}},
+		"Extra[0].name": {{Code: "required", Message: "This field is required."}},
+		"Extra[0].qty":  {{Code: "gte", Message: "Must be at least 1."}},
+	}, errs)
+}
+
+func TestValidationErrorFromFieldErrors(t *testing.T) {
+	errs := testValidationError(t, FieldErrors{
+		{Namespace: "testOrderRequest.Note", Field: "Note", Tag: "max", Param: "5"},
+		{Namespace: "testOrderRequest.Items", Field: "Items", Tag: "min", Param: "1"},
+		{Namespace: "testOrderRequest.Unknown", Field: "Unknown", Tag: "custom"},
+		{Namespace: "testOrderRequest.Items[1].Name", Field: "Name", Tag: "max", Param: "10"},
+		{Namespace: "testOrderRequest.Extra[0].Quantity", Field: "Quantity", Tag: "gte", Param: "1"},
+		{Namespace: "testOrderRequest.Tags[a]", Field: "Tags[a]", Tag: "lte", Param: "3"},
+	})
+	assert.Equal(t, ValidationErrors{
+		"note":          {{Code: "max", Message: "Must be at most 5 characters."}},
+		"items":         {{Code: "min", Message: "Must be at least 1 items."}},
+		"Unknown":       {{Code: "custom", Message: "Failed on the 'custom' rule."}},
+		"items[1].name": {{Code: "max", Message: "Must be at most 10 characters."}},
+		"Extra[0].qty":  {{Code: "gte", Message: "Must be at least 1."}},
+		"tags[a]":       {{Code: "lte", Message: "Must be at most 3."}},
+	}, errs)
+}
+
+func TestValidationErrorFromJSON(t *testing.T) {
+	request := testOrderRequest{}
+
+	err := json.NewDecoder(bytes.NewBufferString(`{"note": `)).Decode(&request)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.Equal(t, ValidationErrors{
+		NON_FIELD_ERRORS: {{Code: "invalid_json", Message: "Malformed or empty JSON body."}},
+	}, testValidationError(t, err))
+
+	err = json.NewDecoder(bytes.NewBufferString("")).Decode(&request)
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, "invalid_json", testValidationError(t, err)[NON_FIELD_ERRORS][0].Code)
+
+	err = json.Unmarshal([]byte(`{"note": }`), &request)
+	assert.Equal(t, ValidationErrors{
+		NON_FIELD_ERRORS: {{Code: "invalid_json", Message: "Malformed JSON at offset 10."}},
+	}, testValidationError(t, err))
+
+	err = json.Unmarshal([]byte(`{"shipping": {"name": 1}}`), &request)
+	assert.Equal(t, ValidationErrors{
+		"shipping.name": {{Code: "invalid_type", Message: "Expected string, got number."}},
+	}, testValidationError(t, err))
+
+	err = json.Unmarshal([]byte(`{"items": [{"name": "pen"}, {"name": 1}]}`), &request)
+	assert.Equal(t, ValidationErrors{
+		"items[1].name": {{Code: "invalid_type", Message: "Expected string, got number."}},
+	}, testValidationError(t, err))
+
+	err = json.Unmarshal([]byte(`{"tags": {"a": "many"}}`), &request)
+	assert.Equal(t, "invalid_type", testValidationError(t, err)["tags[a]"][0].Code)
+
+	assert.Equal(t, "reference", typeErrorPath(reflect.TypeOf(testOrderRequest{}), "reference"))
+	assert.Equal(t, "extra[0].name", typeErrorPath(reflect.TypeOf(struct {
+		Extra any `json:"extra"`
+	}{}), "extra.0.name"))
+}
+
+func TestValidationErrorKeepsOtherErrors(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	err := errors.New("testing")
+	assert.Equal(t, err, validationError[testOrderRequest](err, c))
+
+	custom := ValidationErrors{"note": {{Code: "spam", Message: "Looks like spam."}}}
+	assert.Equal(t, custom, validationError[testOrderRequest](custom, c))
+}
+
+func TestDefaultExceptionHandlerHandleWithValidationErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	errs := ValidationErrors{"title": {{Code: "required", Message: "This field is required."}}}
+	err := NewViewSetError(errs.Error(), http.StatusBadRequest, errs)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	(&DefaultExceptionHandler{}).Handle(err, c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"errors":{"title":[{"code":"required","message":"This field is required."}]}}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	(&DefaultExceptionHandler{ValidationStatusCode: http.StatusUnprocessableEntity}).Handle(err, c)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+}
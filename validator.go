@@ -1,6 +1,9 @@
 package viewset
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -16,3 +19,27 @@ func (_ *DefaultValidator[EntityType, ValidateType]) Validate(
 	}
 	return nil
 }
+
+// FieldError is a failed binding tag of a validator which does not go
+// through the validator gin uses, its message reads the same.
+type FieldError struct {
+	Namespace string
+	// e.g. BookRequest.Title
+	Field string
+	Tag   string
+	Param string
+}
+
+func (err FieldError) Error() string {
+	return fmt.Sprintf("Key: '%s' Error:Field validation for '%s' failed on the '%s' tag", err.Namespace, err.Field, err.Tag)
+}
+
+type FieldErrors []FieldError
+
+func (errs FieldErrors) Error() string {
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
@@ -46,3 +46,23 @@ func TestDefaultValidatorValidateWithError(t *testing.T) {
 
 	assert.NotEqual(t, nil, err)
 }
+
+func TestFieldErrorsReadLikeValidationErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	c.Request = &http.Request{
+		Header: make(http.Header),
+	}
+
+	MockJsonPost(c, map[string]any{})
+
+	validator := DefaultValidator[testObject, testObjectRequest]{}
+	err := validator.Validate(&testObjectRequest{}, nil, c)
+
+	fieldErrors := FieldErrors{
+		{Namespace: "testObjectRequest.Name", Field: "Name", Tag: "required"},
+		{Namespace: "testObjectRequest.Age", Field: "Age", Tag: "required"},
+	}
+	assert.Equal(t, err.Error(), fieldErrors.Error())
+}
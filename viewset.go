@@ -1,6 +1,7 @@
 package viewset
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/TcMits/viewset/manager"
@@ -37,6 +38,13 @@ type ViewSet[EntityType, ValidateType any] struct {
 	Manager       manager.Manager[EntityType, ValidateType]
 	Serializer    Serializer[EntityType]
 	FormValidator FormValidator[EntityType, ValidateType]
+
+	Instrumentation Instrumentation
+	Renderer        Renderer
+	PanicReporter   PanicReporter
+	// optional, notified of the panics recovered from actions
+
+	mountPath string
 }
 
 func NewViewSet[EntityType, ValidateType any](
@@ -54,6 +62,11 @@ func NewViewSet[EntityType, ValidateType any](
 	if manager == nil {
 		panic("manager is required")
 	}
+	for _, action := range excludeDefaultActions {
+		if shouldAddAction(action, defaultActions) {
+			panic(fmt.Sprintf("unknown default action %q in excludeDefaultActions", action))
+		}
+	}
 	if exceptionHandler == nil {
 		exceptionHandler = &DefaultExceptionHandler{}
 	}
@@ -74,6 +87,8 @@ func NewViewSet[EntityType, ValidateType any](
 		Manager:           manager,
 		Serializer:        serializer,
 		FormValidator:     formValidator,
+		Instrumentation:   &NopInstrumentation{},
+		Renderer:          &DefaultRenderer{},
 	}
 
 	if shouldAddAction(DEFAULT_LIST_ACTION, excludeDefaultActions) {
@@ -128,8 +143,11 @@ func NewViewSet[EntityType, ValidateType any](
 	return viewSet
 }
 
-func (viewSet *ViewSet[_, _]) Register(handler gin.IRouter, handleFuncs ...gin.HandlerFunc) {
+// Register adds the routes of the actions, and an OPTIONS route answered by
+// NewMetadata for each sub path which has none.
+func (viewSet *ViewSet[EntityType, ValidateType]) Register(handler gin.IRouter, handleFuncs ...gin.HandlerFunc) {
 	gr := handler.Group(viewSet.BasePath, handleFuncs...)
+	viewSet.mountPath = gr.BasePath()
 	{
 		for _, route := range viewSet.Actions {
 			gr.Handle(
@@ -138,6 +156,13 @@ func (viewSet *ViewSet[_, _]) Register(handler gin.IRouter, handleFuncs ...gin.H
 				getHandler(route.Action, *viewSet, route.Handler),
 			)
 		}
+		for _, subPath := range viewSet.metadataPaths() {
+			gr.Handle(
+				http.MethodOptions,
+				subPath,
+				getHandler(DEFAULT_METADATA_ACTION, *viewSet, NewMetadata[EntityType, ValidateType](subPath)),
+			)
+		}
 	}
 }
 
@@ -156,10 +181,25 @@ func getHandler[EntityType, ValidateType any](
 	function HandlerWithViewSetFunc[EntityType, ValidateType],
 ) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if err := viewSet.PermissionChecker.Check(action, c); err != nil {
-			viewSet.ExceptionHandler.Handle(NewViewSetError(
-				err.Error(), http.StatusForbidden, err,
-			), c)
+		defer viewSet.instrumentation().StartAction(action, c)()
+		defer viewSet.recoverPanic(c)
+
+		if err := viewSet.observe(action, PERMISSION_PHASE, c, func() error {
+			return viewSet.PermissionChecker.Check(action, c)
+		}); err != nil {
+			viewSet.ExceptionHandler.Handle(permissionError(err, c), c)
+			return
+		}
+		if err := viewSet.prepareExpansions(c); err != nil {
+			viewSet.ExceptionHandler.Handle(asViewSetError(err, http.StatusBadRequest), c)
+			return
+		}
+		if err := viewSet.validateQuery(c); err != nil {
+			viewSet.ExceptionHandler.Handle(asViewSetError(err, http.StatusBadRequest), c)
+			return
+		}
+		if txManager, ok := viewSet.Manager.(manager.TransactionalManager); ok && txManager.IsAtomic(c) {
+			handleAtomic(action, &viewSet, c, txManager, function)
 			return
 		}
 		function(action, &viewSet, c)
@@ -175,22 +215,23 @@ func List[EntityType, ValidateType any](
 	entities := make([]*EntityType, 0, 20)
 	manyResponse := make([]map[string]any, 0, 20)
 
-	if err := viewSet.Manager.GetObjects(&entities, paginatedMeta, c); err != nil {
-		viewSet.ExceptionHandler.Handle(NewViewSetError(
-			err.Error(), http.StatusInternalServerError, err,
-		), c)
+	if err := viewSet.observe(action, GET_OBJECTS_PHASE, c, func() error {
+		return viewSet.Manager.GetObjects(&entities, paginatedMeta, c)
+	}); err != nil {
+		viewSet.ExceptionHandler.Handle(classifiedError(err, c), c)
 		return
 	}
-	if err := viewSet.Serializer.ManySerialize(&manyResponse, &entities, c); err != nil {
-		viewSet.ExceptionHandler.Handle(NewViewSetError(
-			err.Error(), http.StatusInternalServerError, err,
-		), c)
+	if err := viewSet.observe(action, SERIALIZE_PHASE, c, func() error {
+		return viewSet.Serializer.ManySerialize(&manyResponse, &entities, c)
+	}); err != nil {
+		viewSet.ExceptionHandler.Handle(classifiedError(err, c), c)
 		return
 	}
-	c.JSON(http.StatusOK, map[string]any{
-		"meta":    paginatedMeta,
-		"results": manyResponse,
-	})
+	if err := viewSet.observe(action, RENDER_PHASE, c, func() error {
+		return viewSet.renderer().RenderMany(http.StatusOK, &manyResponse, paginatedMeta, c)
+	}); err != nil {
+		viewSet.ExceptionHandler.Handle(classifiedError(err, c), c)
+	}
 }
 
 func Retrieve[EntityType, ValidateType any](
@@ -201,19 +242,23 @@ func Retrieve[EntityType, ValidateType any](
 	entity := new(EntityType)
 	response := new(map[string]any)
 
-	if err := viewSet.Manager.GetObject(&entity, c); err != nil {
-		viewSet.ExceptionHandler.Handle(NewViewSetError(
-			err.Error(), http.StatusNotFound, err,
-		), c)
+	if err := viewSet.observe(action, GET_OBJECT_PHASE, c, func() error {
+		return viewSet.Manager.GetObject(&entity, c)
+	}); err != nil {
+		viewSet.ExceptionHandler.Handle(classifiedError(err, c), c)
 		return
 	}
-	if err := viewSet.Serializer.Serialize(response, entity, c); err != nil {
-		viewSet.ExceptionHandler.Handle(NewViewSetError(
-			err.Error(), http.StatusInternalServerError, err,
-		), c)
+	if err := viewSet.observe(action, SERIALIZE_PHASE, c, func() error {
+		return viewSet.Serializer.Serialize(response, entity, c)
+	}); err != nil {
+		viewSet.ExceptionHandler.Handle(classifiedError(err, c), c)
 		return
 	}
-	c.JSON(http.StatusOK, response)
+	if err := viewSet.observe(action, RENDER_PHASE, c, func() error {
+		return viewSet.renderer().Render(http.StatusOK, response, c)
+	}); err != nil {
+		viewSet.ExceptionHandler.Handle(classifiedError(err, c), c)
+	}
 }
 
 func Create[EntityType, ValidateType any](
@@ -221,65 +266,62 @@ func Create[EntityType, ValidateType any](
 	viewSet *ViewSet[EntityType, ValidateType],
 	c *gin.Context,
 ) {
-	var entity *EntityType // make nil
-	validatedData := new(ValidateType)
-	response := new(map[string]any)
+	save(action, viewSet, c, nil, http.StatusCreated)
+}
 
-	if err := viewSet.FormValidator.Validate(validatedData, entity, c); err != nil {
-		viewSet.ExceptionHandler.Handle(NewViewSetError(
-			err.Error(), http.StatusBadRequest, err,
-		), c)
-		return
-	}
-	if err := viewSet.Manager.Save(&entity, validatedData, c); err != nil {
-		viewSet.ExceptionHandler.Handle(NewViewSetError(
-			err.Error(), http.StatusBadRequest, err,
-		), c)
-		return
-	}
-	if err := viewSet.Serializer.Serialize(response, entity, c); err != nil {
-		viewSet.ExceptionHandler.Handle(NewViewSetError(
-			err.Error(), http.StatusInternalServerError, err,
-		), c)
+func Update[EntityType, ValidateType any](
+	action string,
+	viewSet *ViewSet[EntityType, ValidateType],
+	c *gin.Context,
+) {
+	entity := new(EntityType)
+
+	if err := viewSet.observe(action, GET_OBJECT_PHASE, c, func() error {
+		return viewSet.Manager.GetObject(&entity, c)
+	}); err != nil {
+		viewSet.ExceptionHandler.Handle(classifiedError(err, c), c)
 		return
 	}
-	c.JSON(http.StatusCreated, response)
+	save(action, viewSet, c, entity, http.StatusOK)
 }
 
-func Update[EntityType, ValidateType any](
+func save[EntityType, ValidateType any](
 	action string,
 	viewSet *ViewSet[EntityType, ValidateType],
 	c *gin.Context,
+	entity *EntityType, // nil to create
+	statusCode int,
 ) {
-	entity := new(EntityType)
 	validatedData := new(ValidateType)
 	response := new(map[string]any)
 
-	if err := viewSet.Manager.GetObject(&entity, c); err != nil {
-		viewSet.ExceptionHandler.Handle(NewViewSetError(
-			err.Error(), http.StatusNotFound, err,
-		), c)
+	if err := viewSet.observe(action, VALIDATE_PHASE, c, func() error {
+		if err := viewSet.FormValidator.Validate(validatedData, entity, c); err != nil {
+			return err
+		}
+		viewSet.declareFieldRules(c)
+		return viewSet.hideInput(validatedData, entity, c)
+	}); err != nil {
+		viewSet.ExceptionHandler.Handle(asViewSetError(validationError[ValidateType](err, c), http.StatusBadRequest), c)
 		return
 	}
-	if err := viewSet.FormValidator.Validate(validatedData, entity, c); err != nil {
-		viewSet.ExceptionHandler.Handle(NewViewSetError(
-			err.Error(), http.StatusBadRequest, err,
-		), c)
+	if err := viewSet.observe(action, SAVE_PHASE, c, func() error {
+		return viewSet.Manager.Save(&entity, validatedData, c)
+	}); err != nil {
+		viewSet.ExceptionHandler.Handle(classifiedError(err, c), c)
 		return
 	}
-	if err := viewSet.Manager.Save(&entity, validatedData, c); err != nil {
-		viewSet.ExceptionHandler.Handle(NewViewSetError(
-			err.Error(), http.StatusBadRequest, err,
-		), c)
+	if err := viewSet.observe(action, SERIALIZE_PHASE, c, func() error {
+		return viewSet.Serializer.Serialize(response, entity, c)
+	}); err != nil {
+		viewSet.ExceptionHandler.Handle(classifiedError(err, c), c)
 		return
 	}
-	if err := viewSet.Serializer.Serialize(response, entity, c); err != nil {
-		viewSet.ExceptionHandler.Handle(NewViewSetError(
-			err.Error(), http.StatusInternalServerError, err,
-		), c)
-		return
+	if err := viewSet.observe(action, RENDER_PHASE, c, func() error {
+		return viewSet.renderer().Render(statusCode, response, c)
+	}); err != nil {
+		viewSet.ExceptionHandler.Handle(classifiedError(err, c), c)
 	}
-	c.JSON(http.StatusOK, response)
 }
 
 func Delete[EntityType, ValidateType any](
@@ -289,17 +331,20 @@ func Delete[EntityType, ValidateType any](
 ) {
 	entity := new(EntityType)
 
-	if err := viewSet.Manager.GetObject(&entity, c); err != nil {
-		viewSet.ExceptionHandler.Handle(NewViewSetError(
-			err.Error(), http.StatusNotFound, err,
-		), c)
+	if err := viewSet.observe(action, GET_OBJECT_PHASE, c, func() error {
+		return viewSet.Manager.GetObject(&entity, c)
+	}); err != nil {
+		viewSet.ExceptionHandler.Handle(classifiedError(err, c), c)
 		return
 	}
-	if err := viewSet.Manager.Delete(&entity, c); err != nil {
-		viewSet.ExceptionHandler.Handle(NewViewSetError(
-			err.Error(), http.StatusBadRequest, err,
-		), c)
+	if err := viewSet.observe(action, DELETE_PHASE, c, func() error {
+		return viewSet.Manager.Delete(&entity, c)
+	}); err != nil {
+		viewSet.ExceptionHandler.Handle(classifiedError(err, c), c)
 		return
 	}
-	c.JSON(http.StatusNoContent, map[string]any{})
+	viewSet.observe(action, RENDER_PHASE, c, func() error {
+		c.JSON(http.StatusNoContent, map[string]any{})
+		return nil
+	})
 }
@@ -51,6 +51,16 @@ func TestNewViewSetWithExcludeActions(t *testing.T) {
 	assert.Equal(t, 4, len(viewSet.Actions))
 }
 
+func TestNewViewSetWithUnknownExcludeActions(t *testing.T) {
+	objectManager := &testObjectManager{}
+
+	assert.PanicsWithValue(t, `unknown default action "updates" in excludeDefaultActions`, func() {
+		NewViewSet[testObject, testObjectRequest](
+			"/objects", "/:pk", []string{"updates"}, nil, objectManager, nil, nil, nil, nil,
+		)
+	})
+}
+
 func TestViewSetRegister(t *testing.T) {
 	basePath := "/objects"
 
@@ -61,7 +71,7 @@ func TestViewSetRegister(t *testing.T) {
 	router := SetUpRouter()
 	viewSet.Register(router)
 
-	assert.Equal(t, 6, len(router.Routes()))
+	assert.Equal(t, 8, len(router.Routes()))
 }
 
 func TestGetHandler(t *testing.T) {
@@ -136,7 +146,7 @@ func TestList(t *testing.T) {
 }
 
 func TestListWithGetObjectsError(t *testing.T) {
-	mockResponse := `{"message":"Get objects error"}`
+	mockResponse := `{"message":"internal server error"}`
 	gin.SetMode(gin.TestMode)
 	c, _ := gin.CreateTestContext(httptest.NewRecorder())
 	blw := &MockGinBodyResponseWriter{MockBody: bytes.NewBufferString(""), ResponseWriter: c.Writer}
@@ -160,7 +170,7 @@ func TestListWithGetObjectsError(t *testing.T) {
 }
 
 func TestListWithSerializeError(t *testing.T) {
-	mockResponse := `{"message":"Many serialize error"}`
+	mockResponse := `{"message":"internal server error"}`
 	gin.SetMode(gin.TestMode)
 	c, _ := gin.CreateTestContext(httptest.NewRecorder())
 	blw := &MockGinBodyResponseWriter{MockBody: bytes.NewBufferString(""), ResponseWriter: c.Writer}
@@ -215,7 +225,7 @@ func TestRetrieve(t *testing.T) {
 }
 
 func TestRetrieveWithGetObjectError(t *testing.T) {
-	mockResponse := `{"message":"Object not found"}`
+	mockResponse := `{"message":"object not found"}`
 	gin.SetMode(gin.TestMode)
 	c, _ := gin.CreateTestContext(httptest.NewRecorder())
 	blw := &MockGinBodyResponseWriter{MockBody: bytes.NewBufferString(""), ResponseWriter: c.Writer}
@@ -245,7 +255,7 @@ func TestRetrieveWithGetObjectError(t *testing.T) {
 }
 
 func TestRetrieveWithSerializeError(t *testing.T) {
-	mockResponse := `{"message":"Serialize error"}`
+	mockResponse := `{"message":"internal server error"}`
 	gin.SetMode(gin.TestMode)
 	c, _ := gin.CreateTestContext(httptest.NewRecorder())
 	blw := &MockGinBodyResponseWriter{MockBody: bytes.NewBufferString(""), ResponseWriter: c.Writer}
@@ -305,7 +315,7 @@ func TestCreate(t *testing.T) {
 }
 
 func TestCreateWithValidateError(t *testing.T) {
-	mockResponse := `{"message":"Key: 'testObjectRequest.Age' Error:Field validation for 'Age' failed on the 'required' tag"}`
+	mockResponse := `{"errors":{"age":[{"code":"required","message":"This field is required."}]}}`
 	gin.SetMode(gin.TestMode)
 	c, _ := gin.CreateTestContext(httptest.NewRecorder())
 	blw := &MockGinBodyResponseWriter{MockBody: bytes.NewBufferString(""), ResponseWriter: c.Writer}
@@ -334,7 +344,7 @@ func TestCreateWithValidateError(t *testing.T) {
 }
 
 func TestCreateWithSaveError(t *testing.T) {
-	mockResponse := `{"message":"Saving error"}`
+	mockResponse := `{"message":"internal server error"}`
 	gin.SetMode(gin.TestMode)
 	c, _ := gin.CreateTestContext(httptest.NewRecorder())
 	blw := &MockGinBodyResponseWriter{MockBody: bytes.NewBufferString(""), ResponseWriter: c.Writer}
@@ -359,11 +369,11 @@ func TestCreateWithSaveError(t *testing.T) {
 	Create(DEFAULT_CREATE_ACTION, viewSet, c)
 
 	assert.Equal(t, mockResponse, blw.MockBody.String())
-	assert.Equal(t, http.StatusBadRequest, blw.MockStatusCode)
+	assert.Equal(t, http.StatusInternalServerError, blw.MockStatusCode)
 }
 
 func TestCreateWithSerializeError(t *testing.T) {
-	mockResponse := `{"message":"Serialize error"}`
+	mockResponse := `{"message":"internal server error"}`
 	gin.SetMode(gin.TestMode)
 	c, _ := gin.CreateTestContext(httptest.NewRecorder())
 	blw := &MockGinBodyResponseWriter{MockBody: bytes.NewBufferString(""), ResponseWriter: c.Writer}
@@ -428,7 +438,7 @@ func TestUpdate(t *testing.T) {
 }
 
 func TestUpdateWithGetObjectError(t *testing.T) {
-	mockResponse := `{"message":"Object not found"}`
+	mockResponse := `{"message":"object not found"}`
 	gin.SetMode(gin.TestMode)
 	c, _ := gin.CreateTestContext(httptest.NewRecorder())
 	blw := &MockGinBodyResponseWriter{MockBody: bytes.NewBufferString(""), ResponseWriter: c.Writer}
@@ -463,7 +473,7 @@ func TestUpdateWithGetObjectError(t *testing.T) {
 }
 
 func TestUpdateWithValidateError(t *testing.T) {
-	mockResponse := `{"message":"Key: 'testObjectRequest.Age' Error:Field validation for 'Age' failed on the 'required' tag"}`
+	mockResponse := `{"errors":{"age":[{"code":"required","message":"This field is required."}]}}`
 	gin.SetMode(gin.TestMode)
 	c, _ := gin.CreateTestContext(httptest.NewRecorder())
 	blw := &MockGinBodyResponseWriter{MockBody: bytes.NewBufferString(""), ResponseWriter: c.Writer}
@@ -498,7 +508,7 @@ func TestUpdateWithValidateError(t *testing.T) {
 }
 
 func TestUpdateWithSaveError(t *testing.T) {
-	mockResponse := `{"message":"Saving error"}`
+	mockResponse := `{"message":"internal server error"}`
 	gin.SetMode(gin.TestMode)
 	c, _ := gin.CreateTestContext(httptest.NewRecorder())
 	blw := &MockGinBodyResponseWriter{MockBody: bytes.NewBufferString(""), ResponseWriter: c.Writer}
@@ -529,11 +539,11 @@ func TestUpdateWithSaveError(t *testing.T) {
 	Update(DEFAULT_UPDATE_ACTION, viewSet, c)
 
 	assert.Equal(t, mockResponse, blw.MockBody.String())
-	assert.Equal(t, http.StatusBadRequest, blw.MockStatusCode)
+	assert.Equal(t, http.StatusInternalServerError, blw.MockStatusCode)
 }
 
 func TestUpdateWithSerializeError(t *testing.T) {
-	mockResponse := `{"message":"Serialize error"}`
+	mockResponse := `{"message":"internal server error"}`
 	gin.SetMode(gin.TestMode)
 	c, _ := gin.CreateTestContext(httptest.NewRecorder())
 	blw := &MockGinBodyResponseWriter{MockBody: bytes.NewBufferString(""), ResponseWriter: c.Writer}
@@ -603,7 +613,7 @@ func TestDelete(t *testing.T) {
 }
 
 func TestDeleteWithGetObjectError(t *testing.T) {
-	mockResponse := `{"message":"Object not found"}`
+	mockResponse := `{"message":"object not found"}`
 	gin.SetMode(gin.TestMode)
 	c, _ := gin.CreateTestContext(httptest.NewRecorder())
 	blw := &MockGinBodyResponseWriter{MockBody: bytes.NewBufferString(""), ResponseWriter: c.Writer}
@@ -637,7 +647,7 @@ func TestDeleteWithGetObjectError(t *testing.T) {
 }
 
 func TestDeleteWithDeleteError(t *testing.T) {
-	mockResponse := `{"message":"Deleting error"}`
+	mockResponse := `{"message":"internal server error"}`
 	gin.SetMode(gin.TestMode)
 	c, _ := gin.CreateTestContext(httptest.NewRecorder())
 	blw := &MockGinBodyResponseWriter{MockBody: bytes.NewBufferString(""), ResponseWriter: c.Writer}
@@ -667,5 +677,5 @@ func TestDeleteWithDeleteError(t *testing.T) {
 	Delete(DEFAULT_DELETE_ACTION, viewSet, c)
 
 	assert.Equal(t, mockResponse, blw.MockBody.String())
-	assert.Equal(t, http.StatusBadRequest, blw.MockStatusCode)
+	assert.Equal(t, http.StatusInternalServerError, blw.MockStatusCode)
 }
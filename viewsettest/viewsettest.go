@@ -0,0 +1,277 @@
+// Package viewsettest provides a fluent HTTP client to exercise ViewSets end
+// to end in tests.
+package viewsettest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+const USER_CONTEXT_KEY = "user"
+
+type Registerer interface {
+	Register(gin.IRouter, ...gin.HandlerFunc)
+}
+
+type impersonationKey struct{}
+
+type impersonation struct {
+	key  string
+	user any
+}
+
+type PaginationMeta struct {
+	Count    *int64  `json:"count"`
+	Next     *string `json:"next"`
+	Previous *string `json:"previous"`
+}
+
+type ListResponse[T any] struct {
+	Meta    PaginationMeta `json:"meta"`
+	Results []T            `json:"results"`
+}
+
+type FieldError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type ErrorResponse struct {
+	Message string                  `json:"message"`
+	Errors  map[string][]FieldError `json:"errors"`
+}
+
+// Client sends requests to a handler, every With* or As call returns a
+// copy so a base client can be shared between subtests.
+type Client struct {
+	t        testing.TB
+	handler  http.Handler
+	basePath string
+	header   http.Header
+	query    url.Values
+	userKey  string
+	user     any
+}
+
+type Response struct {
+	*httptest.ResponseRecorder
+	t testing.TB
+}
+
+// New registers the viewSet on a fresh gin engine and binds a client to it.
+func New(t testing.TB, basePath string, viewSet Registerer, handleFuncs ...gin.HandlerFunc) *Client {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	viewSet.Register(router, append([]gin.HandlerFunc{Middleware()}, handleFuncs...)...)
+	return NewWithRouter(t, basePath, router)
+}
+
+// NewWithRouter binds a client to an already configured router, add
+// Middleware to it for As to have an effect.
+func NewWithRouter(t testing.TB, basePath string, router http.Handler) *Client {
+	return &Client{
+		t:        t,
+		handler:  router,
+		basePath: strings.TrimRight(basePath, "/"),
+		header:   http.Header{},
+		query:    url.Values{},
+		userKey:  USER_CONTEXT_KEY,
+	}
+}
+
+// Middleware copies the user set by Client.As into the gin context.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if value, ok := c.Request.Context().Value(impersonationKey{}).(impersonation); ok {
+			c.Set(value.key, value.user)
+		}
+		c.Next()
+	}
+}
+
+func (client *Client) clone() *Client {
+	newClient := *client
+	newClient.header = client.header.Clone()
+	newClient.query = url.Values{}
+	for key, values := range client.query {
+		newClient.query[key] = append([]string{}, values...)
+	}
+	return &newClient
+}
+
+// As makes the following requests on behalf of user, permission checkers
+// find it under the user key of the gin context.
+func (client *Client) As(user any) *Client {
+	newClient := client.clone()
+	newClient.user = user
+	return newClient
+}
+
+func (client *Client) WithUserKey(key string) *Client {
+	newClient := client.clone()
+	newClient.userKey = key
+	return newClient
+}
+
+func (client *Client) WithHeader(key string, value string) *Client {
+	newClient := client.clone()
+	newClient.header.Set(key, value)
+	return newClient
+}
+
+func (client *Client) WithQuery(key string, values ...string) *Client {
+	newClient := client.clone()
+	newClient.query[key] = values
+	return newClient
+}
+
+func (client *Client) List() *Response {
+	return client.Do(http.MethodGet, "/", nil)
+}
+
+func (client *Client) Retrieve(pk any) *Response {
+	return client.Do(http.MethodGet, detailPath(pk), nil)
+}
+
+func (client *Client) Create(body any) *Response {
+	return client.Do(http.MethodPost, "/", body)
+}
+
+func (client *Client) Update(pk any, body any) *Response {
+	return client.Do(http.MethodPut, detailPath(pk), body)
+}
+
+func (client *Client) PartialUpdate(pk any, body any) *Response {
+	return client.Do(http.MethodPatch, detailPath(pk), body)
+}
+
+func (client *Client) Delete(pk any) *Response {
+	return client.Do(http.MethodDelete, detailPath(pk), nil)
+}
+
+// Do sends a request to basePath+path, body is encoded as JSON unless it is
+// already a string, a []byte or an io.Reader.
+func (client *Client) Do(method string, path string, body any) *Response {
+	client.t.Helper()
+
+	reader, err := encodeBody(body)
+	if err != nil {
+		client.t.Fatalf("viewsettest: encode body: %v", err)
+	}
+	target := client.basePath + path
+	if len(client.query) > 0 {
+		target += "?" + client.query.Encode()
+	}
+	req := httptest.NewRequest(method, target, reader)
+	for key, values := range client.header {
+		req.Header[key] = values
+	}
+	if body != nil && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if client.user != nil {
+		req = req.WithContext(context.WithValue(
+			req.Context(), impersonationKey{}, impersonation{key: client.userKey, user: client.user},
+		))
+	}
+
+	recorder := httptest.NewRecorder()
+	client.handler.ServeHTTP(recorder, req)
+	return &Response{ResponseRecorder: recorder, t: client.t}
+}
+
+func (r *Response) AssertStatus(statusCode int) *Response {
+	r.t.Helper()
+	if r.Code != statusCode {
+		r.t.Errorf("viewsettest: expected status %d, got %d: %s", statusCode, r.Code, r.Body.String())
+	}
+	return r
+}
+
+// AssertError checks the status and the body written by DefaultExceptionHandler.
+func (r *Response) AssertError(statusCode int, message string) *Response {
+	r.t.Helper()
+	r.AssertStatus(statusCode)
+	errorResponse := ErrorResponse{}
+	if err := json.Unmarshal(r.Body.Bytes(), &errorResponse); err != nil {
+		r.t.Errorf("viewsettest: expected an error body, got %q: %v", r.Body.String(), err)
+		return r
+	}
+	if errorResponse.Message != message {
+		r.t.Errorf("viewsettest: expected error message %q, got %q", message, errorResponse.Message)
+	}
+	return r
+}
+
+// AssertFieldError checks the status and that the validation errors written
+// by DefaultExceptionHandler hold code for the field, e.g. items[1].name.
+func (r *Response) AssertFieldError(statusCode int, field string, code string) *Response {
+	r.t.Helper()
+	r.AssertStatus(statusCode)
+	errorResponse := ErrorResponse{}
+	if err := json.Unmarshal(r.Body.Bytes(), &errorResponse); err != nil {
+		r.t.Errorf("viewsettest: expected an error body, got %q: %v", r.Body.String(), err)
+		return r
+	}
+	for _, fieldError := range errorResponse.Errors[field] {
+		if fieldError.Code == code {
+			return r
+		}
+	}
+	r.t.Errorf("viewsettest: expected error %q on field %q, got %s", code, field, r.Body.String())
+	return r
+}
+
+func (r *Response) Decode(dest any) *Response {
+	r.t.Helper()
+	if err := json.Unmarshal(r.Body.Bytes(), dest); err != nil {
+		r.t.Fatalf("viewsettest: decode %q: %v", r.Body.String(), err)
+	}
+	return r
+}
+
+func DecodeObject[T any](r *Response) T {
+	r.t.Helper()
+	object := new(T)
+	r.Decode(object)
+	return *object
+}
+
+func DecodeList[T any](r *Response) ListResponse[T] {
+	r.t.Helper()
+	list := ListResponse[T]{}
+	r.Decode(&list)
+	return list
+}
+
+func detailPath(pk any) string {
+	return "/" + url.PathEscape(fmt.Sprint(pk))
+}
+
+func encodeBody(body any) (io.Reader, error) {
+	switch v := body.(type) {
+	case nil:
+		return nil, nil
+	case io.Reader:
+		return v, nil
+	case string:
+		return strings.NewReader(v), nil
+	case []byte:
+		return bytes.NewReader(v), nil
+	}
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(encoded), nil
+}
@@ -0,0 +1,164 @@
+package viewsettest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/TcMits/viewset"
+	"github.com/TcMits/viewset/manager"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type book struct {
+	ID     uint   `mapstructure:"id" gorm:"primary_key"`
+	Title  string `mapstructure:"title"`
+	Author string `mapstructure:"author"`
+}
+
+type bookRequest struct {
+	Title  string `mapstructure:"title" json:"title" binding:"required"`
+	Author string `mapstructure:"author" json:"author" binding:"required"`
+}
+
+type bookURI struct {
+	ID uint `mapstructure:"id" uri:"pk" binding:"required"`
+}
+
+type bookResponse struct {
+	ID     uint   `json:"id"`
+	Title  string `json:"title"`
+	Author string `json:"author"`
+}
+
+type staffOnly struct{}
+
+func (_ *staffOnly) Check(action string, c *gin.Context) error {
+	if action == viewset.DEFAULT_LIST_ACTION {
+		return nil
+	}
+	if user, ok := c.Get(USER_CONTEXT_KEY); ok && user == "staff" {
+		return nil
+	}
+	return errors.New("Permission denied")
+}
+
+func newGormViewSet(t *testing.T) *viewset.ViewSet[book, bookRequest] {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.AutoMigrate(&book{})
+	bookManager := manager.NewGormManager[book, bookRequest, bookURI](
+		db.Model(&book{}), nil, nil, nil, nil, "db",
+	)
+	return viewset.NewViewSet[book, bookRequest](
+		"/books", "/:pk", nil, nil, bookManager, nil, &staffOnly{}, nil, nil,
+	)
+}
+
+func TestClientWithGormManager(t *testing.T) {
+	client := New(t, "/books", newGormViewSet(t)).As("staff")
+
+	created := DecodeObject[bookResponse](
+		client.Create(bookRequest{Title: "first", Author: "phuc"}).AssertStatus(http.StatusCreated),
+	)
+	assert.Equal(t, "first", created.Title)
+	assert.NotZero(t, created.ID)
+	client.Create(bookRequest{Title: "second", Author: "huy"}).AssertStatus(http.StatusCreated)
+
+	list := DecodeList[bookResponse](client.WithQuery("limit", "1").List().AssertStatus(http.StatusOK))
+	assert.Equal(t, 1, len(list.Results))
+	assert.Equal(t, "first", list.Results[0].Title)
+	assert.NotNil(t, list.Meta.Next)
+	assert.Nil(t, list.Meta.Previous)
+	assert.Nil(t, list.Meta.Count)
+
+	list = DecodeList[bookResponse](client.WithQuery("with_count", "true").List())
+	assert.Equal(t, int64(2), *list.Meta.Count)
+
+	updated := DecodeObject[bookResponse](
+		client.Update(created.ID, bookRequest{Title: "first 2", Author: "phuc"}).AssertStatus(http.StatusOK),
+	)
+	assert.Equal(t, "first 2", updated.Title)
+	assert.Equal(t, "first 2", DecodeObject[bookResponse](client.Retrieve(created.ID)).Title)
+
+	client.Delete(created.ID).AssertStatus(http.StatusNoContent)
+	client.Retrieve(created.ID).AssertError(http.StatusNotFound, "object not found")
+}
+
+func TestClientImpersonation(t *testing.T) {
+	client := New(t, "/books", newGormViewSet(t))
+
+	client.List().AssertStatus(http.StatusOK)
+	client.Create(bookRequest{Title: "first", Author: "phuc"}).
+		AssertError(http.StatusForbidden, "Permission denied")
+	client.As("guest").Create(bookRequest{Title: "first", Author: "phuc"}).
+		AssertError(http.StatusForbidden, "Permission denied")
+	client.As("staff").Create(bookRequest{Title: "first", Author: "phuc"}).
+		AssertStatus(http.StatusCreated)
+	client.WithUserKey("other").As("staff").Create(bookRequest{Title: "first", Author: "phuc"}).
+		AssertStatus(http.StatusForbidden)
+}
+
+func TestClientValidationError(t *testing.T) {
+	client := New(t, "/books", newGormViewSet(t)).As("staff")
+
+	response := client.Create(`{"title": "first"}`).AssertFieldError(http.StatusBadRequest, "author", "required")
+	assert.Equal(t, []FieldError{{Code: "required", Message: "This field is required."}},
+		DecodeObject[ErrorResponse](response).Errors["author"])
+}
+
+func TestClientWithHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Middleware())
+	router.GET("/echo/", func(c *gin.Context) {
+		user, _ := c.Get("account")
+		c.JSON(http.StatusOK, map[string]any{
+			"header": c.GetHeader("X-Test"),
+			"user":   user,
+			"query":  c.Query("q"),
+		})
+	})
+	base := NewWithRouter(t, "/echo/", router)
+	client := base.WithHeader("X-Test", "value").WithQuery("q", "search").WithUserKey("account").As("me")
+
+	assert.Equal(
+		t,
+		map[string]any{"header": "value", "user": "me", "query": "search"},
+		DecodeObject[map[string]any](client.List()),
+	)
+	assert.Equal(
+		t,
+		map[string]any{"header": "", "user": nil, "query": ""},
+		DecodeObject[map[string]any](base.List()),
+	)
+}
+
+func TestClientWithMemoryManager(t *testing.T) {
+	bookManager := manager.NewMemoryManager[book, bookRequest, bookURI]("ID")
+	bookManager.Add(book{Title: "first", Author: "phuc"})
+	viewSet := viewset.NewViewSet[book, bookRequest](
+		"/books", "/:pk", nil, nil, bookManager, nil, nil, nil, nil,
+	)
+	client := New(t, "/books", viewSet)
+
+	created := DecodeObject[bookResponse](
+		client.Create(bookRequest{Title: "second", Author: "huy"}).AssertStatus(http.StatusCreated),
+	)
+	assert.Equal(t, uint(2), created.ID)
+
+	list := DecodeList[bookResponse](client.WithQuery("with_count", "true").List().AssertStatus(http.StatusOK))
+	assert.Equal(t, []bookResponse{{1, "first", "phuc"}, {2, "second", "huy"}}, list.Results)
+	assert.Equal(t, int64(2), *list.Meta.Count)
+
+	client.PartialUpdate(1, bookRequest{Title: "first 2", Author: "phuc"}).AssertStatus(http.StatusOK)
+	assert.Equal(t, "first 2", DecodeObject[bookResponse](client.Retrieve(1)).Title)
+	client.Delete(1).AssertStatus(http.StatusNoContent)
+	client.Retrieve(1).AssertError(http.StatusNotFound, "object not found")
+}
@@ -0,0 +1,162 @@
+package viewset
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/TcMits/viewset/manager"
+	"github.com/gin-gonic/gin"
+	"github.com/mitchellh/mapstructure"
+)
+
+type HiddenInput int
+
+const (
+	IGNORE_HIDDEN_INPUT HiddenInput = iota
+	REJECT_HIDDEN_INPUT
+)
+
+var ErrHiddenField = errors.New("field is not writable")
+
+type VisibilityRule[EntityType any] interface {
+	Visible(*EntityType, *gin.Context) bool
+	// entity is nil when checking the input of a create
+}
+
+var _ VisibilityRule[any] = &RoleRule[any]{}
+var _ VisibilityRule[any] = &OwnerRule[any]{}
+var _ VisibilityRule[any] = PredicateRule[any](nil)
+
+// RoleRule shows the fields to users having one of Roles.
+type RoleRule[EntityType any] struct {
+	Roles     []string
+	UserRoles func(*gin.Context) []string
+	// roles of the current user
+}
+
+func (rule *RoleRule[EntityType]) Visible(_ *EntityType, c *gin.Context) bool {
+	for _, userRole := range rule.UserRoles(c) {
+		for _, role := range rule.Roles {
+			if userRole == role {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// OwnerRule shows the fields when the Source field of the entity holds the
+// id of the current user, never on create as there is no owner yet.
+type OwnerRule[EntityType any] struct {
+	Source string
+	UserID func(*gin.Context) (any, bool)
+	// id of the current user, false if there is none
+}
+
+func (rule *OwnerRule[EntityType]) Visible(entity *EntityType, c *gin.Context) bool {
+	if entity == nil {
+		return false
+	}
+	userID, ok := rule.UserID(c)
+	if !ok {
+		return false
+	}
+	source, err := sourceField(entity, rule.Source)
+	if err != nil {
+		return false
+	}
+	// compare the printed values, the ids may be different types
+	return fmt.Sprint(source.Interface()) == fmt.Sprint(userID)
+}
+
+// PredicateRule shows the fields when it returns true.
+type PredicateRule[EntityType any] func(*EntityType, *gin.Context) bool
+
+func (rule PredicateRule[EntityType]) Visible(entity *EntityType, c *gin.Context) bool {
+	return rule(entity, c)
+}
+
+// FieldVisibility hides fields per request, Rules maps the serialized keys,
+// as named by mapstructure, to the rule they must pass.
+type FieldVisibility[EntityType any] struct {
+	Rules map[string]VisibilityRule[EntityType]
+	Input HiddenInput
+	// hidden fields of the validated data are ignored by default, or rejected with 403, custom manager funcs must honor manager.HiddenFields to ignore them
+}
+
+type VisibilityDeclarer[EntityType any] interface {
+	FieldVisibility() FieldVisibility[EntityType]
+	// fields hidden from the request in the output and the input
+}
+
+// hidden returns the keys which the request must not see, nil if there is
+// none.
+func (visibility FieldVisibility[EntityType]) hidden(entity *EntityType, c *gin.Context) map[string]bool {
+	var hidden map[string]bool
+	for key, rule := range visibility.Rules {
+		if rule.Visible(entity, c) {
+			continue
+		}
+		if hidden == nil {
+			hidden = make(map[string]bool, len(visibility.Rules))
+		}
+		hidden[key] = true
+	}
+	return hidden
+}
+
+// isHidden matches keys case-insensitively, validated data and entities may
+// name a field differently, like email and Email.
+func isHidden(hidden map[string]bool, key string) bool {
+	if hidden[key] {
+		return true
+	}
+	for hiddenKey := range hidden {
+		if strings.EqualFold(hiddenKey, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// hideInput keeps the manager from writing the hidden fields of the
+// validated data, or rejects the data if it sets any of them.
+func (viewSet *ViewSet[EntityType, ValidateType]) hideInput(
+	validatedData *ValidateType, entity *EntityType, c *gin.Context,
+) error {
+	declarer, ok := viewSet.Serializer.(VisibilityDeclarer[EntityType])
+	if !ok {
+		return nil
+	}
+	visibility := declarer.FieldVisibility()
+	hidden := visibility.hidden(entity, c)
+	if hidden == nil {
+		return nil
+	}
+	values := map[string]any{}
+	if err := mapstructure.Decode(validatedData, &values); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(hidden))
+	rejected := []string{}
+	for key, value := range values {
+		if !isHidden(hidden, key) {
+			continue
+		}
+		keys = append(keys, key)
+		if visibility.Input == REJECT_HIDDEN_INPUT && value != nil && !reflect.ValueOf(value).IsZero() {
+			rejected = append(rejected, key)
+		}
+	}
+	if len(rejected) > 0 {
+		sort.Strings(rejected)
+		return NewLocalizedError(c, "hidden_field", http.StatusForbidden, ErrHiddenField, strings.Join(rejected, ", "))
+	}
+	sort.Strings(keys)
+	manager.SetHiddenFields(c, keys...)
+	return nil
+}
@@ -0,0 +1,159 @@
+package viewset
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/TcMits/viewset/manager"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testAccount struct {
+	ID            uint   `mapstructure:"id" viewset:"read_only"`
+	Username      string `mapstructure:"username"`
+	Email         string `mapstructure:"email"`
+	InternalNotes string `mapstructure:"internal_notes"`
+}
+
+type testAccountRequest struct {
+	Username      string `json:"username" mapstructure:"username"`
+	Email         string `json:"email" mapstructure:"email"`
+	InternalNotes string `json:"internal_notes" mapstructure:"internal_notes"`
+}
+
+type testAccountURI struct {
+	ID uint `uri:"pk"`
+}
+
+func testAccountRoles(c *gin.Context) []string {
+	return strings.Split(c.GetHeader("X-Roles"), ",")
+}
+
+func testAccountUserID(c *gin.Context) (any, bool) {
+	user := c.GetHeader("X-User")
+	return user, user != ""
+}
+
+func newTestAccountSerializer(input HiddenInput) *DefaultSerializer[testAccount] {
+	admin := &RoleRule[testAccount]{Roles: []string{"admin"}, UserRoles: testAccountRoles}
+	return &DefaultSerializer[testAccount]{
+		AdditionalField: map[string]Field[testAccount]{
+			"notes_length": &MethodField[testAccount]{Method: func(account *testAccount, _ *gin.Context) (any, error) {
+				return len(account.InternalNotes), nil
+			}},
+		},
+		Visibility: FieldVisibility[testAccount]{
+			Rules: map[string]VisibilityRule[testAccount]{
+				"email": PredicateRule[testAccount](func(account *testAccount, c *gin.Context) bool {
+					return admin.Visible(account, c) || (&OwnerRule[testAccount]{Source: "Username", UserID: testAccountUserID}).Visible(account, c)
+				}),
+				"internal_notes": admin,
+				"notes_length":   admin,
+			},
+			Input: input,
+		},
+	}
+}
+
+func TestVisibilityRules(t *testing.T) {
+	c := newTestFieldContext("/accounts/")
+	account := &testAccount{Username: "phuc"}
+
+	admin := &RoleRule[testAccount]{Roles: []string{"admin", "staff"}, UserRoles: testAccountRoles}
+	assert.False(t, admin.Visible(account, c))
+	c.Request.Header.Set("X-Roles", "user,staff")
+	assert.True(t, admin.Visible(account, c))
+
+	owner := &OwnerRule[testAccount]{Source: "Username", UserID: testAccountUserID}
+	assert.False(t, owner.Visible(account, c))
+	c.Request.Header.Set("X-User", "phuc")
+	assert.True(t, owner.Visible(account, c))
+	assert.False(t, owner.Visible(nil, c))
+	assert.False(t, (&OwnerRule[testAccount]{Source: "Unknown", UserID: testAccountUserID}).Visible(account, c))
+
+	predicate := PredicateRule[testAccount](func(account *testAccount, _ *gin.Context) bool {
+		return account != nil && account.ID > 0
+	})
+	assert.False(t, predicate.Visible(account, c))
+	assert.True(t, predicate.Visible(&testAccount{ID: 1}, c))
+}
+
+func TestDefaultSerializerHidesFields(t *testing.T) {
+	serializer := newTestAccountSerializer(IGNORE_HIDDEN_INPUT)
+	accounts := []*testAccount{
+		{ID: 1, Username: "phuc", Email: "phuc@example.com", InternalNotes: "vip"},
+		{ID: 2, Username: "huy", Email: "huy@example.com"},
+	}
+
+	c := newTestFieldContext("/accounts/")
+	c.Request.Header.Set("X-User", "phuc")
+	result := []map[string]any{}
+	require.NoError(t, serializer.ManySerialize(&result, &accounts, c))
+	assert.Equal(t, []map[string]any{
+		{"id": uint(1), "username": "phuc", "email": "phuc@example.com"},
+		{"id": uint(2), "username": "huy"},
+	}, result)
+
+	c = newTestFieldContext("/accounts/")
+	c.Request.Header.Set("X-Roles", "admin")
+	object := map[string]any{}
+	require.NoError(t, serializer.Serialize(&object, accounts[0], c))
+	assert.Equal(t, map[string]any{
+		"id": uint(1), "username": "phuc", "email": "phuc@example.com", "internal_notes": "vip", "notes_length": 3,
+	}, object)
+}
+
+func newTestAccountRouter(input HiddenInput) *gin.Engine {
+	accountManager := manager.NewMemoryManager[testAccount, testAccountRequest, testAccountURI]("ID")
+	accountManager.Add(testAccount{Username: "phuc", Email: "phuc@example.com", InternalNotes: "vip"})
+	viewSet := NewViewSet[testAccount, testAccountRequest](
+		"/accounts", "/:pk", nil, nil, accountManager, nil, nil, newTestAccountSerializer(input), nil,
+	)
+	router := SetUpRouter()
+	viewSet.Register(router)
+	return router
+}
+
+func serveTestAccount(router *gin.Engine, method string, path string, roles string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Roles", roles)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestViewSetIgnoresHiddenInput(t *testing.T) {
+	router := newTestAccountRouter(IGNORE_HIDDEN_INPUT)
+
+	w := serveTestAccount(router, http.MethodPut, "/accounts/1", "", `{"username":"phuc","internal_notes":"banned"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"id":1,"username":"phuc"}`, w.Body.String())
+
+	w = serveTestAccount(router, http.MethodGet, "/accounts/1", "admin", "")
+	assert.Equal(t, `{"email":"phuc@example.com","id":1,"internal_notes":"vip","notes_length":3,"username":"phuc"}`, w.Body.String())
+
+	w = serveTestAccount(router, http.MethodPut, "/accounts/1", "admin", `{"username":"phuc","email":"p@example.com","internal_notes":"banned"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"email":"p@example.com","id":1,"internal_notes":"banned","notes_length":6,"username":"phuc"}`, w.Body.String())
+}
+
+func TestViewSetRejectsHiddenInput(t *testing.T) {
+	router := newTestAccountRouter(REJECT_HIDDEN_INPUT)
+
+	w := serveTestAccount(router, http.MethodPost, "/accounts/", "", `{"username":"huy","email":"huy@example.com"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, `{"message":"field is not writable: email"}`, w.Body.String())
+
+	w = serveTestAccount(router, http.MethodPost, "/accounts/", "", `{"username":"huy"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `{"id":2,"username":"huy"}`, w.Body.String())
+
+	w = serveTestAccount(router, http.MethodPost, "/accounts/", "admin", `{"username":"an","email":"an@example.com"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `{"email":"an@example.com","id":3,"internal_notes":"","notes_length":0,"username":"an"}`, w.Body.String())
+}